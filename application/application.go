@@ -1,14 +1,16 @@
 package application
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/dotWicho/marathon"
 	"github.com/dotWicho/marathon/data"
 	"github.com/dotWicho/utilities"
+	"net/http"
+	"net/url"
 	"path/filepath"
-	"regexp"
-	"sort"
 	"strings"
 	"time"
 )
@@ -25,50 +27,112 @@ type App struct {
 // Marathon Application interface
 type application interface {
 	Get(id string) *Application
+	GetContext(ctx context.Context, id string) *Application
 	Set(app AppDefinition) *Application
 	Create(app AppDefinition) *Application
+	CreateContext(ctx context.Context, app AppDefinition) *Application
 	Destroy() error
+	DestroyContext(ctx context.Context) error
 	Update(app AppDefinition) error
+	UpdateContext(ctx context.Context, app AppDefinition) error
 
 	Instances() int
 
 	Scale(instances int, force bool) error
+	ScaleContext(ctx context.Context, instances int, force bool) error
 	Stop(force bool) error
+	StopContext(ctx context.Context, force bool) error
 	Start(instances int, force bool) error
+	StartContext(ctx context.Context, instances int, force bool) error
 	Restart(force bool) error
+	RestartContext(ctx context.Context, force bool) error
 	Suspend(force bool) error
+	SuspendContext(ctx context.Context, force bool) error
 
 	GetTag() (string, error)
 	SetTag(tag string, force bool) error
+	SetTagContext(ctx context.Context, tag string, force bool) error
+	Image() (*marathon.ImageReference, error)
+	SetImage(image marathon.ImageReference, force bool) error
+	SetImageContext(ctx context.Context, image marathon.ImageReference, force bool) error
+	GetRegistry() (string, error)
+	SetRegistry(registry string, force bool) error
+	SetRegistryContext(ctx context.Context, registry string, force bool) error
+	GetDigest() (string, error)
+	SetDigest(digest string, force bool) error
+	SetDigestContext(ctx context.Context, digest string, force bool) error
+	Pin(resolve DigestResolver, force bool) error
+	PinContext(ctx context.Context, resolve DigestResolver, force bool) error
 
 	Env() map[string]string
 	SetEnv(name, value string, force bool) error
+	SetEnvContext(ctx context.Context, name, value string, force bool) error
 	DelEnv(name string, force bool) error
+	DelEnvContext(ctx context.Context, name string, force bool) error
+
+	Labels() map[string]string
+	SetLabel(name, value string, force bool) error
+	SetLabelContext(ctx context.Context, name, value string, force bool) error
+	DelLabel(name string, force bool) error
+	DelLabelContext(ctx context.Context, name string, force bool) error
 
 	Cpus() float64
 	SetCpus(to float64, force bool) error
+	SetCpusContext(ctx context.Context, to float64, force bool) error
 
 	Memory() float64
 	SetMemory(to float64, force bool) error
+	SetMemoryContext(ctx context.Context, to float64, force bool) error
 
 	Role() string
 	SetRole(to string, force bool) error
+	SetRoleContext(ctx context.Context, to string, force bool) error
 
 	Container() *marathon.Container
 	SetContainer(to *marathon.Container, force bool) error
+	SetContainerContext(ctx context.Context, to *marathon.Container, force bool) error
 
 	Parameters() (map[string]string, error)
 	AddParameter(key, value string, force bool) error
+	AddParameterContext(ctx context.Context, key, value string, force bool) error
 	DelParameter(key string, force bool) error
+	DelParameterContext(ctx context.Context, key string, force bool) error
 
 	Versions() []string
+	VersionsContext(ctx context.Context) []string
 	LastVersion() string
+	LastVersionContext(ctx context.Context) string
 	Config(version string) *Application
+	ConfigContext(ctx context.Context, version string) *Application
 
 	Load(fileName string) *Application
 	Dump(fileName string) error
 
+	LastError() error
+
 	Apply(force bool) error
+	ApplyContext(ctx context.Context, force bool) error
+	Partial(enabled bool) *Application
+	Batch() *Application
+	Pending() []string
+	Commit(force bool) error
+	CommitContext(ctx context.Context, force bool) error
+	Patch(force bool, fields ...string) error
+	PatchContext(ctx context.Context, force bool, fields ...string) error
+	OnConflict(policy marathon.ConflictPolicy) *Application
+	Version() time.Time
+	RollbackTo(version string, force bool, timeout time.Duration) (*Rollback, error)
+	RollbackToContext(ctx context.Context, version string, force bool, timeout time.Duration) (*Rollback, error)
+	RollbackSteps(steps int, force bool, timeout time.Duration) (*Rollback, error)
+	RollbackStepsContext(ctx context.Context, steps int, force bool, timeout time.Duration) (*Rollback, error)
+	ApplyAndWait(force bool, timeout time.Duration) (*DeployResult, error)
+	ApplyAndWaitContext(ctx context.Context, force bool, timeout time.Duration) (*DeployResult, error)
+
+	Status() (*AppStatus, error)
+	StatusContext(ctx context.Context) (*AppStatus, error)
+	DeploymentID() string
+	Await(id string, timeout time.Duration) (*DeployResult, error)
+	AwaitContext(ctx context.Context, id string, timeout time.Duration) (*DeployResult, error)
 }
 
 // Application is a Marathon Application implementation
@@ -79,38 +143,64 @@ type Application struct {
 	//
 	app *App
 
+	//
+	partial bool
+	batch   bool
+	pending map[string]bool
+
+	//
+	policy  marathon.ConflictPolicy
+	version time.Time
+	base    map[string]json.RawMessage
+
 	//
 	deploy *data.Response
 	fail   *data.FailureMessage
+	err    error
 }
 
 //=== Marathon FilteredApps JSON Entities definition
 
 // AppDefinition encapsulates the data definitions of a Marathon App
 type AppDefinition struct {
-	ID                    string                 `json:"id"`
-	AcceptedResourceRoles []string               `json:"acceptedResourceRoles,omitempty"`
-	BackoffFactor         float64                `json:"backoffFactor,omitempty"`
-	BackoffSeconds        int                    `json:"backoffSeconds,omitempty"`
-	Container             marathon.Container     `json:"container"`
-	Constraints           []TaskConstraints      `json:"constraints,omitempty"`
-	Cpus                  float64                `json:"cpus"`
-	Disk                  float64                `json:"disk,omitempty"`
-	Env                   map[string]string      `json:"env,omitempty"`
-	Executor              string                 `json:"executor,omitempty"`
-	Fetch                 []Fetch                `json:"fetch,omitempty"`
-	HealthChecks          []marathon.Healthcheck `json:"healthChecks,omitempty"`
-	Instances             int                    `json:"instances"`
-	Labels                map[string]string      `json:"labels,omitempty"`
-	MaxLaunchDelaySeconds int                    `json:"maxLaunchDelaySeconds,omitempty"`
-	Mem                   float64                `json:"mem"`
-	Gpus                  int                    `json:"gpus,omitempty"`
-	Networks              []Network              `json:"networks,omitempty"`
-	RequirePorts          bool                   `json:"requirePorts,omitempty"`
-	UpgradeStrategy       UpgradeStrategy        `json:"upgradeStrategy,omitempty"`
-	KillSelection         string                 `json:"killSelection,omitempty"`
-	UnreachableStrategy   UnreachableStrategy    `json:"unreachableStrategy,omitempty"`
-	Role                  string                 `json:"role,omitempty"`
+	ID                         string                 `json:"id"`
+	Cmd                        string                 `json:"cmd,omitempty"`
+	Args                       []string               `json:"args,omitempty"`
+	User                       string                 `json:"user,omitempty"`
+	AcceptedResourceRoles      []string               `json:"acceptedResourceRoles,omitempty"`
+	BackoffFactor              float64                `json:"backoffFactor,omitempty"`
+	BackoffSeconds             int                    `json:"backoffSeconds,omitempty"`
+	Container                  marathon.Container     `json:"container"`
+	Constraints                []TaskConstraints      `json:"constraints,omitempty"`
+	Cpus                       float64                `json:"cpus,omitempty"`
+	Disk                       float64                `json:"disk,omitempty"`
+	Env                        map[string]string      `json:"env,omitempty"`
+	EnvSecrets                 map[string]string      `json:"-"`
+	Executor                   string                 `json:"executor,omitempty"`
+	Fetch                      []Fetch                `json:"fetch,omitempty"`
+	HealthChecks               []marathon.Healthcheck `json:"healthChecks,omitempty"`
+	ReadinessChecks            []ReadinessCheck       `json:"readinessChecks,omitempty"`
+	Instances                  int                    `json:"instances"`
+	Labels                     map[string]string      `json:"labels,omitempty"`
+	MaxLaunchDelaySeconds      int                    `json:"maxLaunchDelaySeconds,omitempty"`
+	Mem                        float64                `json:"mem"`
+	Gpus                       int                    `json:"gpus,omitempty"`
+	Networks                   []Network              `json:"networks,omitempty"`
+	IPAddress                  *IPAddressPerTask      `json:"ipAddress,omitempty"`
+	PortDefinitions            []PortDefinition       `json:"portDefinitions,omitempty"`
+	Ports                      []int                  `json:"ports,omitempty"`
+	RequirePorts               bool                   `json:"requirePorts,omitempty"`
+	Dependencies               []string               `json:"dependencies,omitempty"`
+	Secrets                    map[string]Secret      `json:"secrets,omitempty"`
+	Residency                  *Residency             `json:"residency,omitempty"`
+	TaskKillGracePeriodSeconds int                    `json:"taskKillGracePeriodSeconds,omitempty"`
+	UpgradeStrategy            UpgradeStrategy        `json:"upgradeStrategy,omitempty"`
+	KillSelection              string                 `json:"killSelection,omitempty"`
+	UnreachableStrategy        UnreachableStrategy    `json:"unreachableStrategy,omitempty"`
+	Role                       string                 `json:"role,omitempty"`
+
+	// Extra holds fields not modeled (or zero valued) in the original payload, re-emitted on marshal
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // TaskConstraints is a simple array of strings
@@ -126,7 +216,61 @@ type Fetch struct {
 
 // Network reflects the data used by the sub-element network on a Marathon App
 type Network struct {
-	Mode string `json:"mode"`
+	Name   string            `json:"name,omitempty"`
+	Mode   string            `json:"mode"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// PortDefinition reflects a port allocated to App tasks on the host network
+type PortDefinition struct {
+	Port     int               `json:"port"`
+	Protocol string            `json:"protocol,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// ReadinessCheck reflects the data used by the sub-element readinessChecks on a Marathon App
+type ReadinessCheck struct {
+	Name                    string `json:"name,omitempty"`
+	Protocol                string `json:"protocol,omitempty"`
+	Path                    string `json:"path,omitempty"`
+	PortName                string `json:"portName,omitempty"`
+	IntervalSeconds         int    `json:"intervalSeconds,omitempty"`
+	TimeoutSeconds          int    `json:"timeoutSeconds,omitempty"`
+	HTTPStatusCodesForReady []int  `json:"httpStatusCodesForReady,omitempty"`
+	PreserveLastResponse    bool   `json:"preserveLastResponse,omitempty"`
+}
+
+// Secret references a secret from the secret store, used by Env (EnvSecrets) and volumes
+type Secret struct {
+	Source string `json:"source"`
+}
+
+// Residency reflects the data used by the sub-element residency on a Marathon App with persistent volumes
+type Residency struct {
+	RelaunchEscalationTimeoutSeconds int    `json:"relaunchEscalationTimeoutSeconds,omitempty"`
+	TaskLostBehavior                 string `json:"taskLostBehavior,omitempty"`
+}
+
+// IPAddressPerTask reflects the data used by the sub-element ipAddress on a Marathon App (IP per task)
+type IPAddressPerTask struct {
+	Groups      []string          `json:"groups,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	NetworkName string            `json:"networkName,omitempty"`
+	Discovery   *Discovery        `json:"discovery,omitempty"`
+}
+
+// Discovery holds the ports discovered on an IP per task App
+type Discovery struct {
+	Ports []DiscoveryPort `json:"ports,omitempty"`
+}
+
+// DiscoveryPort is a port discovered on an IP per task App
+type DiscoveryPort struct {
+	Number   int               `json:"number"`
+	Name     string            `json:"name"`
+	Protocol string            `json:"protocol"`
+	Labels   map[string]string `json:"labels,omitempty"`
 }
 
 // UpgradeStrategy reflects the data used by the sub-element ppgradeStrategy on a Marathon App
@@ -171,17 +315,25 @@ func New(client *marathon.Client) *Application {
 // Get allows to establish the internal structures to referenced id
 func (ma *Application) Get(id string) *Application {
 
+	return ma.GetContext(context.Background(), id)
+}
+
+// GetContext allows to establish the internal structures to referenced id, honoring ctx
+func (ma *Application) GetContext(ctx context.Context, id string) *Application {
+
 	if len(id) > 0 {
 		ma.clear()
 
 		marathon.Logger.Debug("Application: Get %s %+v", id, ma.app.App)
 
-		path := fmt.Sprintf("%s%s", marathon.APIApps, utilities.DelInitialSlash(id))
-
-		if _, err := ma.client.Session.BodyAsJSON(nil).Get(path, ma.app, ma.fail); err != nil {
-			marathon.Logger.Debug("Application: Get failed [%+v]", err)
+		app, version, err := ma.read(ctx, id)
+		if ma.err = err; ma.err != nil {
+			marathon.Logger.Debug("Application: Get failed [%+v]", ma.err)
 			ma.clear()
+			return ma
 		}
+		ma.app.App = app
+		ma.track(version)
 	}
 	return ma
 }
@@ -200,11 +352,17 @@ func (ma *Application) Set(app AppDefinition) *Application {
 // Create allows create a Marathon application into server
 func (ma *Application) Create(app AppDefinition) *Application {
 
+	return ma.CreateContext(context.Background(), app)
+}
+
+// CreateContext allows create a Marathon application into server, honoring ctx
+func (ma *Application) CreateContext(ctx context.Context, app AppDefinition) *Application {
+
 	if len(app.ID) > 0 {
 		marathon.Logger.Debug("Application: Create id = [%s] body = %+v", app.ID, app)
 
 		ma.app.App = app
-		_ = ma.Apply(true)
+		ma.err = ma.ApplyContext(ctx, true)
 	}
 	return ma
 }
@@ -212,13 +370,19 @@ func (ma *Application) Create(app AppDefinition) *Application {
 // Destroy erase a Marathon application from server
 func (ma *Application) Destroy() error {
 
+	return ma.DestroyContext(context.Background())
+}
+
+// DestroyContext erase a Marathon application from server, honoring ctx
+func (ma *Application) DestroyContext(ctx context.Context) error {
+
 	if len(ma.app.App.ID) > 0 {
 		marathon.Logger.Debug("Application: Destroy id = [%s] body = %+v", ma.app.App.ID, ma.app.App)
 
 		path := fmt.Sprintf("%s%s", marathon.APIApps, utilities.DelInitialSlash(ma.app.App.ID))
 
 		ma.clear()
-		if _, err := ma.client.Session.BodyAsJSON(nil).Delete(path, ma.deploy, ma.fail); err != nil {
+		if err := ma.client.Do(ctx, http.MethodDelete, path, nil, nil, ma.deploy, ma.fail); err != nil {
 			marathon.Logger.Debug("Application: Destroy failed [%+v]", err)
 			return err
 		}
@@ -230,11 +394,17 @@ func (ma *Application) Destroy() error {
 // Update allows change values into Marathon application
 func (ma *Application) Update(app AppDefinition) error {
 
+	return ma.UpdateContext(context.Background(), app)
+}
+
+// UpdateContext allows change values into Marathon application, honoring ctx
+func (ma *Application) UpdateContext(ctx context.Context, app AppDefinition) error {
+
 	if len(app.ID) > 0 {
 		marathon.Logger.Debug("Application: Update id = [%s] body = %+v", app.ID, app)
 
 		ma.app.App = app
-		return ma.Apply(true)
+		return ma.ApplyContext(ctx, true)
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -251,11 +421,17 @@ func (ma *Application) Instances() int {
 // Scale allows change instances numbers of a Marathon application
 func (ma *Application) Scale(instances int, force bool) error {
 
+	return ma.ScaleContext(context.Background(), instances, force)
+}
+
+// ScaleContext allows change instances numbers of a Marathon application, honoring ctx
+func (ma *Application) ScaleContext(ctx context.Context, instances int, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 		marathon.Logger.Debug("Application: Scale %s to %d force=%v", ma.app.App.ID, instances, force)
 		ma.app.App.Instances = instances
 
-		return ma.Apply(force)
+		return ma.change(ctx, force, "instances")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -266,25 +442,39 @@ func (ma *Application) Start(instances int, force bool) error {
 	return ma.Scale(instances, force)
 }
 
+// StartContext sets instances of a Marathon application to a number provided, honoring ctx
+func (ma *Application) StartContext(ctx context.Context, instances int, force bool) error {
+
+	return ma.ScaleContext(ctx, instances, force)
+}
+
 // Stop sets instances of a Marathon application to 0
 func (ma *Application) Stop(force bool) error {
 
 	return ma.Scale(0, force)
 }
 
+// StopContext sets instances of a Marathon application to 0, honoring ctx
+func (ma *Application) StopContext(ctx context.Context, force bool) error {
+
+	return ma.ScaleContext(ctx, 0, force)
+}
+
 // Restart use an endpoint to trigger a Marathon application restart
 func (ma *Application) Restart(force bool) error {
 
+	return ma.RestartContext(context.Background(), force)
+}
+
+// RestartContext use an endpoint to trigger a Marathon application restart, honoring ctx
+func (ma *Application) RestartContext(ctx context.Context, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 		marathon.Logger.Debug("Application: Restart id = [%s] force = %v", ma.app.App.ID, force)
 
 		path := fmt.Sprintf("%s%s/restart", marathon.APIApps, utilities.DelInitialSlash(ma.app.App.ID))
 
-		if force {
-			ma.client.Session.AddQueryParam("force", "true")
-		}
-
-		if _, err := ma.client.Session.BodyAsJSON(nil).Post(path, ma.deploy, ma.fail); err != nil {
+		if err := ma.client.Do(ctx, http.MethodPost, path, forceParams(force), nil, ma.deploy, ma.fail); err != nil {
 			marathon.Logger.Debug("Application: Restart failed [%+v]", err)
 			return err
 		}
@@ -299,30 +489,34 @@ func (ma *Application) Suspend(force bool) error {
 	return ma.Stop(force)
 }
 
-// GetTag allows you to change the version of Docker image
-func (ma *Application) GetTag() (string, error) {
+// SuspendContext is an alias to StopContext
+func (ma *Application) SuspendContext(ctx context.Context, force bool) error {
 
-	if len(ma.app.App.ID) > 0 {
-		re := regexp.MustCompile(marathon.DockerImageRegEx)
-		elements := re.FindStringSubmatch(ma.app.App.Container.Docker.Image)
+	return ma.StopContext(ctx, force)
+}
+
+// GetTag returns the tag of the Docker image of a Marathon application, empty if it has none
+func (ma *Application) GetTag() (string, error) {
 
-		return elements[len(elements)-1], nil
+	image, err := ma.Image()
+	if err != nil {
+		return "", err
 	}
-	return "", errors.New("app cannot be null nor empty")
+	return image.Tag, nil
 }
 
 // SetTag allows you to change the version of Docker image
 func (ma *Application) SetTag(tag string, force bool) error {
 
-	if len(ma.app.App.ID) > 0 {
-		re := regexp.MustCompile(marathon.DockerImageRegEx)
-		elements := re.FindStringSubmatch(ma.app.App.Container.Docker.Image)
+	return ma.SetTagContext(context.Background(), tag, force)
+}
 
-		ma.app.App.Container.Docker.Image = fmt.Sprintf("%s%s/%s:%s", elements[1], elements[4], elements[6], tag)
+// SetTagContext allows you to change the version of Docker image, honoring ctx
+func (ma *Application) SetTagContext(ctx context.Context, tag string, force bool) error {
 
-		return ma.Apply(force)
-	}
-	return errors.New("app cannot be null nor empty")
+	return ma.changeImage(ctx, force, func(image marathon.ImageReference) (marathon.ImageReference, error) {
+		return image.WithTag(tag)
+	})
 }
 
 // Env returns the Environment Variables of a Marathon application
@@ -338,10 +532,19 @@ func (ma *Application) Env() map[string]string {
 // SetEnv allows set an environment variable into a Marathon application
 func (ma *Application) SetEnv(name, value string, force bool) error {
 
+	return ma.SetEnvContext(context.Background(), name, value, force)
+}
+
+// SetEnvContext allows set an environment variable into a Marathon application, honoring ctx
+func (ma *Application) SetEnvContext(ctx context.Context, name, value string, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 
+		if ma.app.App.Env == nil {
+			ma.app.App.Env = make(map[string]string)
+		}
 		ma.app.App.Env[name] = value
-		return ma.Apply(force)
+		return ma.change(ctx, force, "env")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -349,10 +552,63 @@ func (ma *Application) SetEnv(name, value string, force bool) error {
 // DelEnv deletes an environment variable from a Marathon application
 func (ma *Application) DelEnv(name string, force bool) error {
 
+	return ma.DelEnvContext(context.Background(), name, force)
+}
+
+// DelEnvContext deletes an environment variable from a Marathon application, honoring ctx
+func (ma *Application) DelEnvContext(ctx context.Context, name string, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 
 		delete(ma.app.App.Env, name)
-		return ma.Apply(force)
+		return ma.change(ctx, force, "env")
+	}
+	return errors.New("app cannot be null nor empty")
+}
+
+// Labels returns the labels of a Marathon application
+func (ma *Application) Labels() map[string]string {
+
+	if len(ma.app.App.ID) > 0 {
+
+		return ma.app.App.Labels
+	}
+	return nil
+}
+
+// SetLabel allows set a label into a Marathon application
+func (ma *Application) SetLabel(name, value string, force bool) error {
+
+	return ma.SetLabelContext(context.Background(), name, value, force)
+}
+
+// SetLabelContext allows set a label into a Marathon application, honoring ctx
+func (ma *Application) SetLabelContext(ctx context.Context, name, value string, force bool) error {
+
+	if len(ma.app.App.ID) > 0 {
+
+		if ma.app.App.Labels == nil {
+			ma.app.App.Labels = make(map[string]string)
+		}
+		ma.app.App.Labels[name] = value
+		return ma.change(ctx, force, "labels")
+	}
+	return errors.New("app cannot be null nor empty")
+}
+
+// DelLabel deletes a label from a Marathon application
+func (ma *Application) DelLabel(name string, force bool) error {
+
+	return ma.DelLabelContext(context.Background(), name, force)
+}
+
+// DelLabelContext deletes a label from a Marathon application, honoring ctx
+func (ma *Application) DelLabelContext(ctx context.Context, name string, force bool) error {
+
+	if len(ma.app.App.ID) > 0 {
+
+		delete(ma.app.App.Labels, name)
+		return ma.change(ctx, force, "labels")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -370,10 +626,16 @@ func (ma *Application) Cpus() float64 {
 // SetCpus sets the amount of cpus of a Marathon application
 func (ma *Application) SetCpus(to float64, force bool) error {
 
+	return ma.SetCpusContext(context.Background(), to, force)
+}
+
+// SetCpusContext sets the amount of cpus of a Marathon application, honoring ctx
+func (ma *Application) SetCpusContext(ctx context.Context, to float64, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 
 		ma.app.App.Cpus = to
-		return ma.Apply(force)
+		return ma.change(ctx, force, "cpus")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -391,10 +653,16 @@ func (ma *Application) Memory() float64 {
 // SetMemory sets the amount of memory of a Marathon application
 func (ma *Application) SetMemory(to float64, force bool) error {
 
+	return ma.SetMemoryContext(context.Background(), to, force)
+}
+
+// SetMemoryContext sets the amount of memory of a Marathon application, honoring ctx
+func (ma *Application) SetMemoryContext(ctx context.Context, to float64, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 
 		ma.app.App.Mem = to
-		return ma.Apply(force)
+		return ma.change(ctx, force, "mem")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -412,10 +680,16 @@ func (ma *Application) Role() string {
 // SetRole sets role of a Marathon application
 func (ma *Application) SetRole(to string, force bool) error {
 
+	return ma.SetRoleContext(context.Background(), to, force)
+}
+
+// SetRoleContext sets role of a Marathon application, honoring ctx
+func (ma *Application) SetRoleContext(ctx context.Context, to string, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 
 		ma.app.App.Role = to
-		return ma.Apply(force)
+		return ma.change(ctx, force, "role")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -433,20 +707,20 @@ func (ma *Application) Container() *marathon.Container {
 // SetContainer sets the Container information of a Marathon application
 func (ma *Application) SetContainer(to *marathon.Container, force bool) error {
 
+	return ma.SetContainerContext(context.Background(), to, force)
+}
+
+// SetContainerContext sets the Container information of a Marathon application, honoring ctx.
+// to replaces the whole container, networking and pull config included
+func (ma *Application) SetContainerContext(ctx context.Context, to *marathon.Container, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 
-		ma.app.App.Container = marathon.Container{
-			Type: to.Type,
-			Docker: marathon.Docker{
-				ForcePullImage: to.Docker.ForcePullImage,
-				Image:          to.Docker.Image,
-				Parameters:     to.Docker.Parameters,
-				Privileged:     to.Docker.Privileged,
-			},
-			Volumes:      to.Volumes,
-			PortMappings: to.PortMappings,
+		if to == nil {
+			return errors.New("container cannot be null")
 		}
-		return ma.Apply(force)
+		ma.app.App.Container = *to
+		return ma.change(ctx, force, "container")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -472,34 +746,26 @@ func (ma *Application) Parameters() (map[string]string, error) {
 // AddParameter sets the key, value into parameters of a Marathon application
 func (ma *Application) AddParameter(key, value string, force bool) error {
 
-	if len(ma.app.App.ID) > 0 {
+	return ma.AddParameterContext(context.Background(), key, value, force)
+}
 
-		exist := false
-		index := -1
+// AddParameterContext sets the key, value into parameters of a Marathon application, honoring ctx
+func (ma *Application) AddParameterContext(ctx context.Context, key, value string, force bool) error {
 
-		// Are there any parameters defined?
-		if len(ma.app.App.Container.Docker.Parameters) > 0 {
+	if len(ma.app.App.ID) > 0 {
 
-			// Iterate searching for our passed key
-			for idx, val := range ma.app.App.Container.Docker.Parameters {
-				if val.Key == key {
-					exist = true
-					index = idx
-				}
+		// Change it if it exists, append it otherwise
+		for index, parameter := range ma.app.App.Container.Docker.Parameters {
+			if parameter.Key == key {
+				ma.app.App.Container.Docker.Parameters[index].Value = value
+				return ma.change(ctx, force, "container")
 			}
 		}
-		// Exists?
-		if exist {
-			// Chenge it
-			ma.app.App.Container.Docker.Parameters[index].Value = value
-		} else {
-			// Create, append it
-			ma.app.App.Container.Docker.Parameters = append(ma.app.App.Container.Docker.Parameters, marathon.DockerParameters{
-				Key:   key,
-				Value: value,
-			})
-			return ma.Apply(force)
-		}
+		ma.app.App.Container.Docker.Parameters = append(ma.app.App.Container.Docker.Parameters, marathon.DockerParameters{
+			Key:   key,
+			Value: value,
+		})
+		return ma.change(ctx, force, "container")
 	}
 	return errors.New("app cannot be null nor empty")
 }
@@ -507,6 +773,12 @@ func (ma *Application) AddParameter(key, value string, force bool) error {
 // DelParameter erase the parameter referenced by key
 func (ma *Application) DelParameter(key string, force bool) error {
 
+	return ma.DelParameterContext(context.Background(), key, force)
+}
+
+// DelParameterContext erase the parameter referenced by key, honoring ctx
+func (ma *Application) DelParameterContext(ctx context.Context, key string, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 		toRemove := -1
 
@@ -527,7 +799,7 @@ func (ma *Application) DelParameter(key string, force bool) error {
 			}
 			ma.app.App.Container.Docker.Parameters = ma.app.App.Container.Docker.Parameters[:length-1]
 
-			return ma.Apply(force)
+			return ma.change(ctx, force, "container")
 		}
 		return fmt.Errorf("parameters %s dont exist in Marathon app %s", key, ma.app.App.ID)
 	}
@@ -537,6 +809,13 @@ func (ma *Application) DelParameter(key string, force bool) error {
 // Versions returns all configurations versions of provided task
 func (ma *Application) Versions() []string {
 
+	return ma.VersionsContext(context.Background())
+}
+
+// VersionsContext returns all configurations versions of provided task, honoring ctx. The app is
+// kept if they cannot be read
+func (ma *Application) VersionsContext(ctx context.Context) []string {
+
 	if len(ma.app.App.ID) > 0 {
 		marathon.Logger.Debug("Application: Versions %+v", ma.app.App)
 
@@ -544,9 +823,8 @@ func (ma *Application) Versions() []string {
 
 		versions := &AppVersions{Versions: make([]string, 0)}
 
-		if _, err := ma.client.Session.BodyAsJSON(nil).Get(path, versions, ma.fail); err != nil {
-			marathon.Logger.Debug("Application: Versions failed [%+v]", err)
-			ma.clear()
+		if ma.err = ma.client.Do(ctx, http.MethodGet, path, nil, nil, versions, ma.fail); ma.err != nil {
+			marathon.Logger.Debug("Application: Versions failed [%+v]", ma.err)
 		}
 
 		if len(versions.Versions) > 0 {
@@ -559,9 +837,16 @@ func (ma *Application) Versions() []string {
 // LastVersion returns last version of a provided task
 func (ma *Application) LastVersion() string {
 
+	return ma.LastVersionContext(context.Background())
+}
+
+// LastVersionContext returns last version of a provided task, honoring ctx. Versions are compared
+// as times, as RollbackSteps does, and the app is kept if they cannot be read
+func (ma *Application) LastVersionContext(ctx context.Context) string {
+
 	if len(ma.app.App.ID) > 0 {
-		if versions := ma.Versions(); len(versions) > 0 {
-			sort.Strings(versions)
+		var versions []string
+		if versions, ma.err = ma.versions(ctx); len(versions) > 0 {
 			return versions[len(versions)-1]
 		}
 	}
@@ -571,13 +856,19 @@ func (ma *Application) LastVersion() string {
 // Config returns a AppDefinition based on it version
 func (ma *Application) Config(version string) *Application {
 
+	return ma.ConfigContext(context.Background(), version)
+}
+
+// ConfigContext returns a AppDefinition based on it version, honoring ctx
+func (ma *Application) ConfigContext(ctx context.Context, version string) *Application {
+
 	if len(ma.app.App.ID) > 0 {
 		marathon.Logger.Debug("Application: Config %+v", ma.app.App)
 
 		path := fmt.Sprintf(marathon.APIConfigByVersion, utilities.DelInitialSlash(ma.app.App.ID), version)
 
-		if _, err := ma.client.Session.BodyAsJSON(nil).Get(path, ma.app, ma.fail); err != nil {
-			marathon.Logger.Debug("Application: Config failed [%+v]", err)
+		if ma.err = ma.client.Do(ctx, http.MethodGet, path, nil, nil, ma.app, ma.fail); ma.err != nil {
+			marathon.Logger.Debug("Application: Config failed [%+v]", ma.err)
 			ma.clear()
 		}
 	}
@@ -595,12 +886,12 @@ func (ma *Application) Load(fileName string) *Application {
 	case ".json":
 		err = utilities.LoadDataFromJSON(&ma.app.App, fileName)
 	case ".yaml":
-		err = utilities.LoadDataFromYAML(&ma.app.App, fileName)
+		err = marathon.LoadYAML(fileName, &ma.app.App)
 	default:
 		err = fmt.Errorf("invalid filename extension")
 	}
 
-	if err != nil {
+	if ma.err = err; err != nil {
 		ma.clear()
 	}
 	return ma
@@ -628,27 +919,41 @@ func (ma *Application) Dump(fileName string) (err error) {
 // Apply internal func, allows send all changes of a Marathon application to Marathon server
 func (ma *Application) Apply(force bool) error {
 
+	return ma.ApplyContext(context.Background(), force)
+}
+
+// ApplyContext allows send all changes of a Marathon application to Marathon server, honoring ctx
+func (ma *Application) ApplyContext(ctx context.Context, force bool) error {
+
 	if len(ma.app.App.ID) > 0 {
 
+		if err := ma.checkVersion(ctx); err != nil {
+			return err
+		}
+
 		path := fmt.Sprintf("%s%s", marathon.APIApps, utilities.DelInitialSlash(ma.app.App.ID))
 
 		marathon.Logger.Debug("Application: Apply(%v)[%+v] %s", force, ma.app, path)
-		if force {
-			ma.client.Session.AddQueryParam("force", "true")
-		}
 
-		if _, err := ma.client.Session.BodyAsJSON(ma.app.App).Put(path, ma.deploy, ma.fail); err != nil {
+		if err := ma.client.Do(ctx, http.MethodPut, path, forceParams(force), ma.app.App, ma.deploy, ma.fail); err != nil {
 			marathon.Logger.Debug("Application: Apply StatusCode: %d [Deploy Id: %s => date: %v {%+v}{%+v}]", ma.client.StatusCode(), ma.deploy.ID, ma.deploy.Version, ma.fail, err)
 			return err
 		}
-		// TODO: Deployment wait for ma.timeout
 		marathon.Logger.Debug("Application: Apply StatusCode: %d [Deploy Id: %s => date: %v]", ma.client.StatusCode(), ma.deploy.ID, ma.deploy.Version)
 
+		ma.pending = nil
+		ma.track(ma.deploy.Version)
 		return nil
 	}
 	return errors.New("app cannot be null nor empty")
 }
 
+// LastError returns the error of last Get, Load, Create, Versions or Config, nil if it was successful
+func (ma *Application) LastError() error {
+
+	return ma.err
+}
+
 // AsRaw returns AppDefinition content of current Application
 func (ma *Application) AsRaw() AppDefinition {
 
@@ -658,9 +963,21 @@ func (ma *Application) AsRaw() AppDefinition {
 	return AppDefinition{}
 }
 
+// forceParams returns the query params needed to force a change
+func forceParams(force bool) url.Values {
+
+	if force {
+		return url.Values{"force": []string{"true"}}
+	}
+	return nil
+}
+
 // clear set internal data to his defaults
 func (ma *Application) clear() {
 
 	ma.app = nil
 	ma.app = &App{}
+	ma.pending = nil
+	ma.version = time.Time{}
+	ma.base = nil
 }
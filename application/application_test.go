@@ -1,13 +1,16 @@
 package application
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/dotWicho/marathon"
 	"github.com/dotWicho/marathon/mockserver"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 func Test_NewApplication(t *testing.T) {
@@ -74,6 +77,40 @@ func TestApplication_Get(t *testing.T) {
 	})
 }
 
+func TestApplication_LastError(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get NotFound error if app does not exist", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to Get an app that don't exist
+		_app.Get("/infra/missing")
+
+		// Application ref must be empty
+		assert.Empty(t, _app.app.App)
+
+		// We get a not found error
+		assert.True(t, marathon.IsNotFound(_app.LastError()))
+	})
+
+	t.Run("get nil error if app exists", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to Get a valid app
+		_app.Get("/infra/redis-1")
+
+		// We get not error
+		assert.Nil(t, _app.LastError())
+	})
+}
+
 func TestApplication_Set(t *testing.T) {
 
 	// We create a Mock Server
@@ -346,6 +383,141 @@ func TestApplication_Scale(t *testing.T) {
 	})
 }
 
+func TestApplication_ScaleContext(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get ctx error when ScaleContext is called with a cancelled ctx", func(t *testing.T) {
+
+		// we define some vars
+		redisApp := &App{}
+		_ = json.Unmarshal([]byte(mockserver.AppRedis), redisApp)
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// We cancel our context before fire up
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// try to Scale with a valid app
+		err := _app.Set(redisApp.App).ScaleContext(ctx, 2, true)
+
+		// We get context error
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("get not error when ScaleContext is called with a valid app", func(t *testing.T) {
+
+		// we define some vars
+		redisApp := &App{}
+		_ = json.Unmarshal([]byte(mockserver.AppRedis), redisApp)
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to Get and Scale with a valid app
+		ctx := context.Background()
+		err := _app.GetContext(ctx, redisApp.App.ID).ScaleContext(ctx, 2, true)
+
+		// We get not error
+		assert.Nil(t, err)
+
+		// Check some values on response
+		assert.Equal(t, 2, _app.Instances())
+	})
+}
+
+func TestApplication_ApplyErrors(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get Conflict error when app is locked by a deployment", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to Apply a locked app
+		err := _app.Set(AppDefinition{ID: "/infra/locked"}).Apply(false)
+
+		// We get a conflict error
+		assert.True(t, marathon.IsConflict(err))
+	})
+
+	t.Run("get Validation error when app is invalid", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to Apply an invalid app
+		err := _app.Set(AppDefinition{ID: "/infra/invalid"}).Apply(false)
+
+		// We get a validation error
+		assert.True(t, marathon.IsValidation(err))
+	})
+}
+
+func TestApplication_ApplyAndWait(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get error when ApplyAndWait is called with app empty", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to ApplyAndWait with empty app
+		result, err := _app.ApplyAndWait(true, time.Second)
+
+		// Error must be "app cannot be null nor empty"
+		assert.Nil(t, result)
+		assert.NotNil(t, err)
+		assert.Equal(t, "app cannot be null nor empty", err.Error())
+	})
+
+	t.Run("get result when tasks are running and healthy", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to Get and ApplyAndWait a valid app
+		result, err := _app.Get("/infra/redis-1").ApplyAndWait(true, 5*time.Second)
+
+		// We get not error
+		assert.Nil(t, err)
+
+		// Check some values on result
+		assert.Equal(t, "/infra/redis-1", result.AppID)
+		assert.Equal(t, "d4b75430-8ee6-47e9-95f2-6cf297aaac00", result.DeploymentID)
+		assert.Equal(t, 1, result.TasksRunning)
+		assert.Equal(t, 1, result.TasksHealthy)
+		assert.Empty(t, result.Failures)
+	})
+
+	t.Run("get timeout error when tasks are not running", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// try to Get and ApplyAndWait an app without tasks running
+		result, err := _app.Get("/infra/kong-v2").ApplyAndWait(true, time.Second)
+
+		// We get timeout error
+		assert.Equal(t, ErrTimeout, err)
+
+		// Check some values on result
+		assert.Equal(t, 1, result.Instances)
+		assert.Equal(t, 0, result.TasksRunning)
+		assert.True(t, result.Duration >= time.Second)
+	})
+}
+
 func TestApplication_Start(t *testing.T) {
 
 	TestApplication_Scale(t)
@@ -639,6 +811,17 @@ func TestApplication_SetEnv(t *testing.T) {
 		// Check some values on response
 		assert.Equal(t, "YES", _env["TESTED"])
 	})
+
+	t.Run("set Env of an app without env", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Set(AppDefinition{ID: "/infra/redis-1"})
+
+		// No panic, the env is created
+		assert.Nil(t, _app.Batch().SetEnv("TESTED", "YES", true))
+		assert.Equal(t, map[string]string{"TESTED": "YES"}, _app.Env())
+		assert.Equal(t, []string{"env"}, _app.Pending())
+	})
 }
 
 func TestApplication_DelEnv(t *testing.T) {
@@ -683,6 +866,42 @@ func TestApplication_DelEnv(t *testing.T) {
 	})
 }
 
+func TestApplication_Labels(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get error when SetLabel or DelLabel are called with app empty", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		// Error must be "app cannot be null nor empty"
+		assert.Equal(t, "app cannot be null nor empty", _app.SetLabel("HAPROXY_GROUP", "external", true).Error())
+		assert.Equal(t, "app cannot be null nor empty", _app.DelLabel("HAPROXY_GROUP", true).Error())
+		assert.Nil(t, _app.Labels())
+	})
+
+	t.Run("set and del labels of a valid app", func(t *testing.T) {
+
+		// we define some vars
+		redisApp := &App{}
+		_ = json.Unmarshal([]byte(mockserver.AppRedis), redisApp)
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get(redisApp.App.ID)
+		_app.app.App.Labels = nil
+
+		// We get not error, labels are created if the app has none
+		assert.Nil(t, _app.SetLabel("HAPROXY_GROUP", "external", true))
+		assert.Equal(t, map[string]string{"HAPROXY_GROUP": "external"}, _app.Labels())
+
+		assert.Nil(t, _app.DelLabel("HAPROXY_GROUP", true))
+		assert.Empty(t, _app.Labels())
+	})
+}
+
 func TestApplication_Cpus(t *testing.T) {
 
 	// We create a Mock Server
@@ -1007,6 +1226,41 @@ func TestApplication_SetContainer(t *testing.T) {
 		// Check some values on response
 		assert.Equal(t, container, _app.Container())
 	})
+
+	t.Run("ports and pull config survive a new image", func(t *testing.T) {
+
+		redisApp := &App{}
+		_ = json.Unmarshal([]byte(mockserver.AppRedis), redisApp)
+
+		_app := New(marathon.New(server.URL)).Get(redisApp.App.ID)
+
+		container := *_app.Container()
+		container.Docker.Network = "BRIDGE"
+		container.Docker.PortMappings = []marathon.PortMapping{{ContainerPort: 6379, Protocol: "tcp"}}
+		container.Docker.PullConfig = &marathon.PullConfig{Secret: "registry"}
+		container.LinuxInfo = &marathon.LinuxInfo{Seccomp: &marathon.Seccomp{Unconfined: true}}
+		assert.Nil(t, _app.SetContainer(&container, true))
+
+		// Only the image changes
+		changed := container
+		changed.Docker.Image = "fake.registry.org/redis:6.0.9"
+		assert.Nil(t, _app.SetContainer(&changed, true))
+
+		assert.Equal(t, "BRIDGE", _app.Container().Docker.Network)
+		assert.Equal(t, 6379, _app.Container().Docker.PortMappings[0].ContainerPort)
+		assert.Equal(t, "registry", _app.Container().Docker.PullConfig.Secret)
+		assert.True(t, _app.Container().LinuxInfo.Seccomp.Unconfined)
+		assert.Equal(t, "fake.registry.org/redis:6.0.9", _app.Container().Docker.Image)
+	})
+
+	t.Run("error if container is nil", func(t *testing.T) {
+
+		redisApp := &App{}
+		_ = json.Unmarshal([]byte(mockserver.AppRedis), redisApp)
+
+		err := New(marathon.New(server.URL)).Get(redisApp.App.ID).SetContainer(nil, true)
+		assert.Equal(t, "container cannot be null", err.Error())
+	})
 }
 
 func TestApplication_Parameter(t *testing.T) {
@@ -1108,6 +1362,26 @@ func TestApplication_AddParameter(t *testing.T) {
 		assert.Equal(t, "10.128.64.32", _map["add-host"])
 		assert.Equal(t, "10.64.128.1", _map["new-host"])
 	})
+
+	t.Run("change a Parameter that already exists", func(t *testing.T) {
+
+		// we define some vars
+		redisApp := &App{}
+		_ = json.Unmarshal([]byte(mockserver.AppRedis), redisApp)
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get(redisApp.App.ID)
+
+		// The change is sent
+		assert.Nil(t, _app.AddParameter("add-host", "10.64.128.2", true))
+
+		_map, _ := _app.Parameters()
+		assert.Equal(t, map[string]string{"add-host": "10.64.128.2"}, _map)
+
+		// And recorded as a change of the container
+		assert.Nil(t, _app.Batch().AddParameter("add-host", "10.64.128.3", true))
+		assert.Equal(t, []string{"container"}, _app.Pending())
+	})
 }
 
 func TestApplication_DelParameter(t *testing.T) {
@@ -1215,6 +1489,28 @@ func TestApplication_Versions(t *testing.T) {
 		assert.NotNil(t, _backup)
 		assert.NotEmpty(t, _backup.app.App.ID)
 	})
+
+	t.Run("last version is the newest as a time", func(t *testing.T) {
+
+		_app := New(marathon.New(server.URL)).Set(AppDefinition{ID: "/infra/versioned"})
+
+		// Compared as strings 42.7Z would be the newest
+		assert.Equal(t, "2021-01-21T20:27:42.725Z", _app.LastVersion())
+		assert.Nil(t, _app.LastError())
+	})
+
+	t.Run("the cached app is kept if versions fail", func(t *testing.T) {
+
+		// A server that is gone
+		gone := mockserver.MockServer()
+		gone.Close()
+		_app := New(marathon.New(gone.URL)).Set(AppDefinition{ID: appID})
+
+		assert.Empty(t, _app.LastVersion())
+		assert.NotNil(t, _app.LastError())
+		assert.Nil(t, _app.Versions())
+		assert.Equal(t, appID, _app.AsRaw().ID)
+	})
 }
 
 func TestApplication_Load(t *testing.T) {
@@ -1317,3 +1613,560 @@ func TestApplication_Dump(t *testing.T) {
 		assert.Equal(t, redisRef, file)
 	})
 }
+
+func TestAppDefinition_JSON(t *testing.T) {
+
+	t.Run("decode every App field", func(t *testing.T) {
+
+		app := AppDefinition{}
+		err := json.Unmarshal([]byte(mockserver.FullApp), &app)
+
+		// We get not error
+		assert.Nil(t, err)
+
+		// Check some values
+		assert.Equal(t, "docker-entrypoint.sh postgres", app.Cmd)
+		assert.Equal(t, "postgres", app.User)
+		assert.Equal(t, marathon.ContainerMesos, app.Container.Type)
+		assert.Equal(t, "registry", app.Container.Docker.PullConfig.Secret)
+		assert.Equal(t, 1024, app.Container.Volumes[0].Persistent.Size)
+		assert.Equal(t, "dvdi", app.Container.Volumes[1].External.Provider)
+		assert.Equal(t, "certificate", app.Container.Volumes[2].Secret)
+		assert.Equal(t, []string{"dcos"}, app.Container.PortMappings[0].NetworkNames)
+		assert.Equal(t, map[string]string{"PGDATA": "pgdata"}, app.Env)
+		assert.Equal(t, map[string]string{"POSTGRES_PASSWORD": "password"}, app.EnvSecrets)
+		assert.Equal(t, "/infra/postgres/password", app.Secrets["password"].Source)
+		assert.Equal(t, "pg_isready", app.HealthChecks[0].Command.Value)
+		assert.Equal(t, "pg", app.ReadinessChecks[0].PortName)
+		assert.Equal(t, "dcos", app.Networks[0].Name)
+		assert.Equal(t, []string{"/infra/redis-1"}, app.Dependencies)
+		assert.Equal(t, "WAIT_FOREVER", app.Residency.TaskLostBehavior)
+		assert.Equal(t, 30, app.TaskKillGracePeriodSeconds)
+	})
+
+	t.Run("encode keeps every App field", func(t *testing.T) {
+
+		app := AppDefinition{}
+		_ = json.Unmarshal([]byte(mockserver.FullApp), &app)
+
+		// Encode and decode it again
+		content, err := json.Marshal(app)
+		assert.Nil(t, err)
+
+		var expected, actual map[string]interface{}
+		_ = json.Unmarshal([]byte(mockserver.FullApp), &expected)
+		_ = json.Unmarshal(content, &actual)
+
+		// Nothing was stripped
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("error if env value is not valid", func(t *testing.T) {
+
+		app := AppDefinition{}
+		err := json.Unmarshal([]byte(`{"id": "/infra/bad", "env": {"A": 1}}`), &app)
+
+		// We get an error
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAppDefinition_Clone(t *testing.T) {
+
+	t.Run("clone of a host networking app is valid", func(t *testing.T) {
+
+		// Marathon returns both ports and portDefinitions
+		app := AppDefinition{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.HostApp), &app))
+		assert.Equal(t, []int{10105}, app.Ports)
+
+		clone, err := app.Clone("/stored/host/web-copy")
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Nil(t, clone.Validate())
+		assert.Equal(t, "/stored/host/web-copy", clone.ID)
+		assert.Empty(t, clone.Ports)
+		assert.Equal(t, 0, clone.PortDefinitions[0].Port)
+		assert.Equal(t, "http", clone.PortDefinitions[0].Name)
+
+		// The original is untouched
+		assert.Equal(t, []int{10105}, app.Ports)
+		assert.Equal(t, 10105, app.PortDefinitions[0].Port)
+	})
+
+	t.Run("clone clears service ports of port mappings", func(t *testing.T) {
+
+		app := AppDefinition{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.FullApp), &app))
+
+		clone, err := app.Clone("/infra/postgres-copy")
+
+		assert.Nil(t, err)
+		for _, mapping := range clone.Container.PortMappings {
+			assert.Equal(t, 0, mapping.ServicePort)
+		}
+	})
+}
+
+func TestApplication_ApplyLossless(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("Get then Apply sends back the recorded payload", func(t *testing.T) {
+
+		// Try to Get and Apply the recorded app
+		_app := New(marathon.New(server.URL)).Get("/infra/recorded")
+		assert.Nil(t, _app.LastError())
+		assert.Nil(t, _app.Apply(false))
+
+		// What Marathon sent us, without read-only fields
+		recorded := &struct {
+			App map[string]interface{} `json:"app"`
+		}{}
+		_ = json.Unmarshal([]byte(mockserver.RecordedApp), recorded)
+		for _, field := range readOnlyFields {
+			delete(recorded.App, field)
+		}
+
+		// What we sent back
+		var sent map[string]interface{}
+		assert.Nil(t, json.Unmarshal(mockserver.LastBody(), &sent))
+
+		// Semantics are the same, unknown and zero valued fields included
+		assert.Equal(t, recorded.App, sent)
+	})
+
+	t.Run("changes win over kept fields", func(t *testing.T) {
+
+		// Try to Get and Scale the recorded app
+		_app := New(marathon.New(server.URL)).Get("/infra/recorded")
+		assert.Nil(t, _app.Scale(5, false))
+
+		var sent map[string]interface{}
+		assert.Nil(t, json.Unmarshal(mockserver.LastBody(), &sent))
+
+		// Scaled and still carrying unknown fields
+		assert.Equal(t, float64(5), sent["instances"])
+		assert.Equal(t, map[string]interface{}{"cpus": "unlimited", "mem": float64(512)}, sent["resourceLimits"])
+		assert.Equal(t, float64(0), sent["backoffSeconds"])
+	})
+
+	t.Run("app without container does not send one", func(t *testing.T) {
+
+		content, err := json.Marshal(AppDefinition{ID: "/infra/cmd", Cmd: "sleep 100"})
+
+		assert.Nil(t, err)
+		assert.NotContains(t, string(content), "container")
+	})
+
+	t.Run("app without cpus leaves them to the Marathon default", func(t *testing.T) {
+
+		content, err := json.Marshal(AppDefinition{ID: "/infra/cmd", Cmd: "sleep 100"})
+
+		assert.Nil(t, err)
+		assert.NotContains(t, string(content), "cpus")
+	})
+}
+
+func TestApplication_Patch(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("error if app is empty", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Partial(true)
+
+		assert.NotNil(t, _app.Patch(false, "cpus"))
+		assert.NotNil(t, _app.SetCpus(1, false))
+	})
+
+	t.Run("error if field does not exist", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1")
+
+		assert.NotNil(t, _app.Patch(false, "missing"))
+	})
+
+	t.Run("partial setters send only changed fields", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1").Partial(true)
+		assert.Nil(t, _app.LastError())
+
+		// Change cpus
+		assert.Nil(t, _app.SetCpus(0.5, false))
+		assert.JSONEq(t, `{"id":"/infra/redis-1","cpus":0.5}`, string(mockserver.LastBody()))
+		assert.Empty(t, _app.Pending())
+
+		// Remove every env variable
+		for name := range _app.Env() {
+			assert.Nil(t, _app.DelEnv(name, false))
+		}
+		assert.JSONEq(t, `{"id":"/infra/redis-1","env":{}}`, string(mockserver.LastBody()))
+	})
+
+	t.Run("batch changes into a single request", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1").Batch()
+		assert.Nil(t, _app.LastError())
+
+		// Nothing is sent until Commit
+		before := mockserver.LastBody()
+		assert.Nil(t, _app.SetCpus(2, false))
+		assert.Nil(t, _app.SetMemory(512, false))
+		assert.Nil(t, _app.Scale(0, false))
+		assert.Equal(t, before, mockserver.LastBody())
+		assert.Equal(t, []string{"cpus", "instances", "mem"}, _app.Pending())
+
+		// Commit them
+		assert.Nil(t, _app.CommitContext(context.Background(), true))
+		assert.JSONEq(t, `{"id":"/infra/redis-1","cpus":2,"mem":512,"instances":0}`, string(mockserver.LastBody()))
+		assert.Empty(t, _app.Pending())
+
+		// Batch is over, setters PUT the whole definition again
+		assert.Nil(t, _app.SetCpus(1, false))
+		assert.Contains(t, string(mockserver.LastBody()), `"container"`)
+	})
+}
+
+func TestApplication_OnConflict(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("overwrite by default", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/moving")
+		assert.Nil(t, _app.LastError())
+		assert.False(t, _app.Version().IsZero())
+
+		// Last write wins
+		assert.Nil(t, _app.SetCpus(0.5, false))
+	})
+
+	t.Run("fail if app changed since Get", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/moving").OnConflict(marathon.FailOnChange)
+		read := _app.Version()
+
+		// Someone else changed it
+		err := _app.SetCpus(0.5, false)
+		assert.True(t, marathon.IsModified(err))
+
+		modified := &marathon.ModifiedError{}
+		assert.True(t, errors.As(err, &modified))
+		assert.Equal(t, "/infra/moving", modified.ID)
+		assert.Equal(t, read, modified.Read)
+		assert.True(t, modified.Current.After(read))
+	})
+
+	t.Run("rebase changes not overlapping", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/moving").OnConflict(marathon.RebaseOnChange)
+		read := _app.Version()
+
+		// Someone else changed labels, we change cpus
+		assert.Nil(t, _app.SetCpus(0.5, false))
+
+		sent := AppDefinition{}
+		assert.Nil(t, json.Unmarshal(mockserver.LastBody(), &sent))
+		assert.Equal(t, 0.5, sent.Cpus)
+		assert.NotEqual(t, _app.AsRaw().Labels["REVISION"], "")
+		assert.Equal(t, _app.AsRaw().Labels, sent.Labels)
+		assert.True(t, _app.Version().After(read))
+	})
+
+	t.Run("fail if changes overlap", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/moving").OnConflict(marathon.RebaseOnChange)
+
+		// Someone else changed labels, we change them too
+		_app.AsRaw().Labels["OWNER"] = "us"
+		err := _app.Apply(false)
+
+		modified := &marathon.ModifiedError{}
+		assert.True(t, errors.As(err, &modified))
+		assert.Equal(t, []string{"labels"}, modified.Fields)
+	})
+
+	t.Run("set definitions are never checked", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Set(AppDefinition{ID: "/infra/moving"}).OnConflict(marathon.FailOnChange)
+
+		assert.True(t, _app.Version().IsZero())
+		assert.Nil(t, _app.Patch(false, "cpus"))
+	})
+}
+
+func TestApplication_Rollback(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("error if app is empty", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL))
+
+		_, err := _app.RollbackTo("2021-01-16T18:27:41.662Z", false, 0)
+		assert.NotNil(t, err)
+		_, err = _app.RollbackSteps(1, false, 0)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error if steps are out of range", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1")
+
+		_, err := _app.RollbackSteps(0, false, 0)
+		assert.NotNil(t, err)
+		_, err = _app.RollbackSteps(9, false, 0)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("versions are sorted as times", func(t *testing.T) {
+
+		_app := New(marathon.New(server.URL)).Set(AppDefinition{ID: "/infra/versioned"})
+
+		versions, err := _app.versions(context.Background())
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"2021-01-21T20:27:42.65Z", "2021-01-21T20:27:42.7Z", "2021-01-21T20:27:42.725Z"}, versions)
+	})
+
+	t.Run("the cached app is kept if versions fail", func(t *testing.T) {
+
+		// A server that is gone
+		gone := mockserver.MockServer()
+		gone.Close()
+		_app := New(marathon.New(gone.URL)).Set(AppDefinition{ID: "/infra/redis-1", Cpus: 1})
+
+		_, err := _app.RollbackSteps(1, false, 0)
+
+		// We get an error and the app can be retried
+		assert.NotNil(t, err)
+		assert.Equal(t, "/infra/redis-1", _app.AsRaw().ID)
+		assert.Equal(t, 1.0, _app.Cpus())
+	})
+
+	t.Run("roll back to a previous version", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1")
+
+		rollback, err := _app.RollbackSteps(1, true, 0)
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, "2021-01-16T18:27:41.662Z", rollback.Version)
+		assert.Equal(t, []string{"container", "cpus", "env", "healthChecks", "labels"}, rollback.Changed)
+		assert.Equal(t, "d4b75430-8ee6-47e9-95f2-6cf297aaac00", rollback.DeploymentID)
+		assert.Nil(t, rollback.Result)
+
+		// The definition sent has no read-only fields
+		sent := map[string]json.RawMessage{}
+		assert.Nil(t, json.Unmarshal(mockserver.LastBody(), &sent))
+		assert.Equal(t, json.RawMessage(`0.5`), sent["cpus"])
+		assert.NotContains(t, sent, "tasksRunning")
+		assert.NotContains(t, sent, "deployments")
+
+		// The cached app is the one rolled back
+		assert.Equal(t, 0.5, _app.Cpus())
+		assert.Equal(t, 1.0, rollback.Previous.Cpus)
+	})
+
+	t.Run("roll back and wait", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1")
+
+		rollback, err := _app.RollbackToContext(context.Background(), "2021-01-16T18:27:41.662Z", true, 5*time.Second)
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.NotNil(t, rollback.Result)
+		assert.Equal(t, "/infra/redis-1", rollback.Result.AppID)
+	})
+}
+
+func TestAppDefinition_Validate(t *testing.T) {
+
+	// We define some vars
+	redis := App{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.AppRedis), &redis))
+
+	t.Run("valid definitions", func(t *testing.T) {
+
+		assert.Nil(t, redis.App.Validate())
+
+		full := AppDefinition{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.FullApp), &full))
+		assert.Nil(t, full.Validate())
+
+		assert.Nil(t, (&AppDefinition{ID: "sleeper", Cmd: "sleep 100", Cpus: 0.1}).Validate())
+
+		// Without cpus none are sent and Marathon uses its default
+		assert.Nil(t, (&AppDefinition{ID: "sleeper", Cmd: "sleep 100"}).Validate())
+	})
+
+	t.Run("every problem with its path", func(t *testing.T) {
+
+		app := AppDefinition{
+			ID:        "/infra/Redis_1/",
+			Cmd:       "redis-server",
+			Args:      []string{"--port", "6379"},
+			Cpus:      0.0001,
+			Mem:       -1,
+			Instances: -1,
+			Container: marathon.Container{
+				Type: marathon.ContainerDocker,
+				PortMappings: []marathon.PortMapping{
+					{ContainerPort: 6379, HostPort: 70000, Protocol: "sctp", NetworkNames: []string{"dcos"}},
+				},
+			},
+			Networks:     []Network{{Mode: NetworkBridge}},
+			HealthChecks: []marathon.Healthcheck{{Protocol: "TCP", PortIndex: 1}, {Protocol: "COMMAND"}},
+			Constraints: []TaskConstraints{
+				{"hostname", "UNIQUE", "x"},
+				{"rack", "MAX_PER"},
+				{"zone", "LIKE", "[a-"},
+				{"hostname", "NEAR"},
+			},
+			UpgradeStrategy:     UpgradeStrategy{MinimumHealthCapacity: 1.5, MaximumOverCapacity: 1},
+			UnreachableStrategy: UnreachableStrategy{InactiveAfterSeconds: 300, ExpungeAfterSeconds: 60},
+		}
+
+		err := app.Validate()
+
+		// Error is a validation error with every problem found
+		assert.True(t, marathon.IsValidation(err))
+		invalid := marathon.ValidationErrors{}
+		assert.True(t, errors.As(err, &invalid))
+		assert.Equal(t, []string{
+			"/id",
+			"/cmd",
+			"/container/docker/image",
+			"/cpus",
+			"/mem",
+			"/instances",
+			"/container/portMappings(0)/hostPort",
+			"/container/portMappings(0)/protocol",
+			"/container/portMappings(0)/networkNames",
+			"/container/portMappings(0)/networkNames",
+			"/healthChecks(0)/portIndex",
+			"/healthChecks(1)/command",
+			"/constraints(0)",
+			"/constraints(1)",
+			"/constraints(2)",
+			"/constraints(3)",
+			"/upgradeStrategy/minimumHealthCapacity",
+			"/unreachableStrategy/expungeAfterSeconds",
+		}, invalid.Paths())
+	})
+
+	t.Run("port definitions need host networking", func(t *testing.T) {
+
+		app := redis.App
+		app.PortDefinitions = []PortDefinition{{Port: 0}}
+
+		err := app.Validate()
+		assert.Contains(t, err.Error(), "/portDefinitions: portDefinitions are only allowed with host networking")
+	})
+}
+
+func TestApplication_SetTagInvalidImage(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Try to create Application
+	_app := New(marathon.New(server.URL)).Set(AppDefinition{ID: "/infra/sleeper", Cmd: "sleep 100"})
+
+	// We get an error instead of a panic
+	_, err := _app.GetTag()
+	assert.NotNil(t, err)
+	assert.NotNil(t, _app.SetTag("1.0", false))
+}
+
+func TestApplication_Image(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	digest := "sha256:4bb6e9e1d1a2e5f4e5d6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8"
+
+	t.Run("images without registry", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Set(AppDefinition{ID: "/infra/moving", Container: marathon.Container{Docker: marathon.Docker{Image: "redis:6"}}})
+
+		tag, err := _app.GetTag()
+		assert.Nil(t, err)
+		assert.Equal(t, "6", tag)
+
+		registry, err := _app.GetRegistry()
+		assert.Nil(t, err)
+		assert.Empty(t, registry)
+
+		assert.Nil(t, _app.SetTag("6.2", false))
+		assert.Equal(t, "redis:6.2", _app.Container().Docker.Image)
+	})
+
+	t.Run("change registry and digest independently", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1")
+
+		assert.Nil(t, _app.SetRegistry("registry.local:5000", false))
+		assert.Nil(t, _app.SetDigest(digest, false))
+		assert.Equal(t, "registry.local:5000/redis-ha:5.0.5@"+digest, _app.Container().Docker.Image)
+
+		value, err := _app.GetDigest()
+		assert.Nil(t, err)
+		assert.Equal(t, digest, value)
+
+		// Invalid values are not applied
+		assert.NotNil(t, _app.SetDigest("latest", false))
+		assert.NotNil(t, _app.SetImage(marathon.ImageReference{Repository: "Redis"}, false))
+		assert.Equal(t, "registry.local:5000/redis-ha:5.0.5@"+digest, _app.Container().Docker.Image)
+	})
+
+	t.Run("pin tag to a digest", func(t *testing.T) {
+
+		// Try to create Application
+		_app := New(marathon.New(server.URL)).Get("/infra/redis-1")
+
+		resolve := func(image marathon.ImageReference) (string, error) {
+			if image.Tag == "5.0.5" {
+				return digest, nil
+			}
+			return "", errors.New("unknown tag")
+		}
+
+		assert.Nil(t, _app.Pin(resolve, false))
+		assert.Equal(t, "docker.io/redis-ha:5.0.5@"+digest, _app.Container().Docker.Image)
+
+		assert.Nil(t, _app.SetTag("6.0", false))
+		assert.NotNil(t, _app.PinContext(context.Background(), resolve, false))
+	})
+}
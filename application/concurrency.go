@@ -0,0 +1,177 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// OnConflict sets what Apply, Patch and every setter do when the app changed on Marathon server
+// since it was read with Get: overwrite it (the default), fail or rebase local changes on it
+func (ma *Application) OnConflict(policy marathon.ConflictPolicy) *Application {
+
+	ma.policy = policy
+	return ma
+}
+
+// Version returns the version of the app read with Get (or written later), zero if unknown
+func (ma *Application) Version() time.Time {
+
+	return ma.version
+}
+
+// read returns the definition and the version of the app id as it is on Marathon server
+func (ma *Application) read(ctx context.Context, id string) (AppDefinition, time.Time, error) {
+
+	path := fmt.Sprintf("%s%s", marathon.APIApps, utilities.DelInitialSlash(id))
+
+	var content json.RawMessage
+	if err := ma.client.Do(ctx, http.MethodGet, path, nil, nil, &content, ma.fail); err != nil {
+		return AppDefinition{}, time.Time{}, err
+	}
+
+	app := App{}
+	if err := json.Unmarshal(content, &app); err != nil {
+		return AppDefinition{}, time.Time{}, err
+	}
+
+	var status struct {
+		App struct {
+			Version time.Time `json:"version"`
+		} `json:"app"`
+	}
+	if err := json.Unmarshal(content, &status); err != nil {
+		return AppDefinition{}, time.Time{}, err
+	}
+	return app.App, status.App.Version, nil
+}
+
+// track remembers version and the content of the cached definition as it is on Marathon server
+func (ma *Application) track(version time.Time) {
+
+	ma.version = version
+	ma.base, _ = encodeFields(ma.app.App)
+}
+
+// checkVersion compares the version read with the current one on Marathon server before
+// sending changes and, if it changed, applies the conflict policy
+func (ma *Application) checkVersion(ctx context.Context) error {
+
+	if ma.policy == marathon.Overwrite || ma.version.IsZero() {
+		return nil
+	}
+
+	current, version, err := ma.read(ctx, ma.app.App.ID)
+	if err != nil {
+		return err
+	}
+	if version.Equal(ma.version) {
+		return nil
+	}
+
+	modified := &marathon.ModifiedError{ID: ma.app.App.ID, Read: ma.version, Current: version}
+	if ma.policy != marathon.RebaseOnChange {
+		return modified
+	}
+
+	local, err := changedFields(ma.base, ma.app.App)
+	if err != nil {
+		return err
+	}
+	remote, err := changedFields(ma.base, current)
+	if err != nil {
+		return err
+	}
+	for _, name := range local {
+		for _, other := range remote {
+			if name == other {
+				modified.Fields = append(modified.Fields, name)
+			}
+		}
+	}
+	if len(modified.Fields) > 0 {
+		return modified
+	}
+
+	rebased, err := rebase(current, ma.app.App, local)
+	if err != nil {
+		return err
+	}
+	marathon.Logger.Debug("Application: %s rebased %v on version %v", ma.app.App.ID, local, version)
+
+	ma.app.App = rebased
+	ma.track(version)
+	return nil
+}
+
+// encodeFields returns the top level fields of app as sent to Marathon server
+func encodeFields(app AppDefinition) (map[string]json.RawMessage, error) {
+
+	encoded, err := json.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(encoded, &fields)
+	return fields, err
+}
+
+// changedFields returns the sorted names of top level fields of app that differ from base
+func changedFields(base map[string]json.RawMessage, app AppDefinition) ([]string, error) {
+
+	fields, err := encodeFields(app)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for name, raw := range fields {
+		if !bytes.Equal(raw, base[name]) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range base {
+		if _, ok := fields[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// rebase returns current with the named fields taken from local
+func rebase(current, local AppDefinition, names []string) (AppDefinition, error) {
+
+	fields, err := encodeFields(current)
+	if err != nil {
+		return AppDefinition{}, err
+	}
+	changes, err := encodeFields(local)
+	if err != nil {
+		return AppDefinition{}, err
+	}
+
+	for _, name := range names {
+		if raw, ok := changes[name]; ok {
+			fields[name] = raw
+		} else {
+			delete(fields, name)
+		}
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return AppDefinition{}, err
+	}
+
+	rebased := AppDefinition{}
+	err = json.Unmarshal(encoded, &rebased)
+	return rebased, err
+}
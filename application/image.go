@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+)
+
+// DigestResolver returns the digest an image reference must be pinned to
+type DigestResolver func(image marathon.ImageReference) (string, error)
+
+// Image returns the reference of the Docker image of a Marathon application
+func (ma *Application) Image() (*marathon.ImageReference, error) {
+
+	if len(ma.app.App.ID) > 0 {
+
+		image, err := marathon.ParseImage(ma.app.App.Container.Docker.Image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid docker image of Marathon app %s: %w", ma.app.App.ID, err)
+		}
+		return image, nil
+	}
+	return nil, errors.New("app cannot be null nor empty")
+}
+
+// SetImage sets the Docker image of a Marathon application
+func (ma *Application) SetImage(image marathon.ImageReference, force bool) error {
+
+	return ma.SetImageContext(context.Background(), image, force)
+}
+
+// SetImageContext sets the Docker image of a Marathon application, honoring ctx
+func (ma *Application) SetImageContext(ctx context.Context, image marathon.ImageReference, force bool) error {
+
+	if len(ma.app.App.ID) > 0 {
+
+		if _, err := marathon.ParseImage(image.String()); err != nil {
+			return err
+		}
+		ma.app.App.Container.Docker.Image = image.String()
+		return ma.change(ctx, force, "container")
+	}
+	return errors.New("app cannot be null nor empty")
+}
+
+// GetRegistry returns the registry (with its port) of the Docker image of a Marathon
+// application, empty for Docker Hub
+func (ma *Application) GetRegistry() (string, error) {
+
+	image, err := ma.Image()
+	if err != nil {
+		return "", err
+	}
+	return image.Host(), nil
+}
+
+// SetRegistry moves the Docker image of a Marathon application to registry, keeping
+// repository, tag and digest; an empty registry means Docker Hub
+func (ma *Application) SetRegistry(registry string, force bool) error {
+
+	return ma.SetRegistryContext(context.Background(), registry, force)
+}
+
+// SetRegistryContext moves the Docker image of a Marathon application to registry, honoring ctx
+func (ma *Application) SetRegistryContext(ctx context.Context, registry string, force bool) error {
+
+	return ma.changeImage(ctx, force, func(image marathon.ImageReference) (marathon.ImageReference, error) {
+		return image.WithRegistry(registry)
+	})
+}
+
+// GetDigest returns the digest of the Docker image of a Marathon application, empty if it has none
+func (ma *Application) GetDigest() (string, error) {
+
+	image, err := ma.Image()
+	if err != nil {
+		return "", err
+	}
+	return image.Digest, nil
+}
+
+// SetDigest pins the Docker image of a Marathon application to digest, keeping its tag
+func (ma *Application) SetDigest(digest string, force bool) error {
+
+	return ma.SetDigestContext(context.Background(), digest, force)
+}
+
+// SetDigestContext pins the Docker image of a Marathon application to digest, honoring ctx
+func (ma *Application) SetDigestContext(ctx context.Context, digest string, force bool) error {
+
+	return ma.changeImage(ctx, force, func(image marathon.ImageReference) (marathon.ImageReference, error) {
+		return image.WithDigest(digest)
+	})
+}
+
+// Pin pins the tag of the Docker image of a Marathon application to the digest returned by resolve
+func (ma *Application) Pin(resolve DigestResolver, force bool) error {
+
+	return ma.PinContext(context.Background(), resolve, force)
+}
+
+// PinContext pins the tag of the Docker image of a Marathon application to the digest returned
+// by resolve, honoring ctx
+func (ma *Application) PinContext(ctx context.Context, resolve DigestResolver, force bool) error {
+
+	return ma.changeImage(ctx, force, func(image marathon.ImageReference) (marathon.ImageReference, error) {
+		digest, err := resolve(image)
+		if err != nil {
+			return image, fmt.Errorf("unable to resolve digest of %s: %w", image, err)
+		}
+		return image.WithDigest(digest)
+	})
+}
+
+// changeImage applies change to the Docker image of a Marathon application and sends it
+func (ma *Application) changeImage(ctx context.Context, force bool, change func(marathon.ImageReference) (marathon.ImageReference, error)) error {
+
+	image, err := ma.Image()
+	if err != nil {
+		return err
+	}
+
+	changed, err := change(*image)
+	if err != nil {
+		return err
+	}
+	ma.app.App.Container.Docker.Image = changed.String()
+
+	return ma.change(ctx, force, "container")
+}
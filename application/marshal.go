@@ -0,0 +1,134 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"reflect"
+)
+
+// readOnlyFields are App status fields returned by Marathon, they must not be sent back
+var readOnlyFields = []string{
+	"version",
+	"versionInfo",
+	"tasksStaged",
+	"tasksRunning",
+	"tasksHealthy",
+	"tasksUnhealthy",
+	"deployments",
+	"tasks",
+	"lastTaskFailure",
+	"taskStats",
+	"readinessCheckResults",
+}
+
+// appDefinition has the same fields of AppDefinition but none of its methods,
+// so it is encoded and decoded without recursion into AppDefinition JSON methods
+type appDefinition AppDefinition
+
+// envSecret is the value of an env variable referencing a secret
+type envSecret struct {
+	Secret string `json:"secret"`
+}
+
+// UnmarshalJSON decodes an AppDefinition, env variables referencing a secret
+// ({"secret": "name"}) are decoded into EnvSecrets instead of Env, fields not
+// modeled are kept into Extra and read-only status fields are dropped
+func (ad *AppDefinition) UnmarshalJSON(content []byte) error {
+
+	aux := struct {
+		*appDefinition
+		Env map[string]json.RawMessage `json:"env,omitempty"`
+	}{
+		appDefinition: (*appDefinition)(ad),
+	}
+	if err := json.Unmarshal(content, &aux); err != nil {
+		return err
+	}
+
+	if aux.Env != nil {
+		ad.Env = make(map[string]string)
+		ad.EnvSecrets = nil
+		for name, raw := range aux.Env {
+			var value string
+			if err := json.Unmarshal(raw, &value); err == nil {
+				ad.Env[name] = value
+				continue
+			}
+
+			secret := envSecret{}
+			if err := json.Unmarshal(raw, &secret); err != nil {
+				return fmt.Errorf("invalid value of env %s: %w", name, err)
+			}
+			if ad.EnvSecrets == nil {
+				ad.EnvSecrets = make(map[string]string)
+			}
+			ad.EnvSecrets[name] = secret.Secret
+		}
+	}
+
+	extra, err := marathon.UnknownFields(content, ad, readOnlyFields...)
+	ad.Extra = extra
+	return err
+}
+
+// MarshalJSON encodes an AppDefinition, merging Env and EnvSecrets into env and
+// including fields kept into Extra, an empty Container is not sent
+func (ad AppDefinition) MarshalJSON() ([]byte, error) {
+
+	aux := struct {
+		appDefinition
+		Env       map[string]interface{} `json:"env,omitempty"`
+		Container *marathon.Container    `json:"container,omitempty"`
+	}{
+		appDefinition: appDefinition(ad),
+	}
+
+	if !reflect.DeepEqual(ad.Container, marathon.Container{}) {
+		aux.Container = &ad.Container
+	}
+
+	if len(ad.Env)+len(ad.EnvSecrets) > 0 {
+		aux.Env = make(map[string]interface{})
+		for name, value := range ad.Env {
+			aux.Env[name] = value
+		}
+		for name, secret := range ad.EnvSecrets {
+			aux.Env[name] = envSecret{Secret: secret}
+		}
+	}
+
+	encoded, err := json.Marshal(aux)
+	if err != nil {
+		return nil, err
+	}
+	return marathon.MergeFields(encoded, ad.Extra)
+}
+
+// Clone returns a copy of the AppDefinition sharing nothing with it as id, service ports are
+// cleared because they are unique on the cluster and Marathon assigns new ones; ports, which
+// Marathon returns along with portDefinitions, are dropped as they hold the same service ports
+func (ad AppDefinition) Clone(id string) (AppDefinition, error) {
+
+	clone := AppDefinition{}
+	content, err := json.Marshal(ad)
+	if err != nil {
+		return clone, err
+	}
+	if err := json.Unmarshal(content, &clone); err != nil {
+		return clone, err
+	}
+
+	clone.ID = id
+	for index := range clone.Container.PortMappings {
+		clone.Container.PortMappings[index].ServicePort = 0
+	}
+	for index := range clone.Container.Docker.PortMappings {
+		clone.Container.Docker.PortMappings[index].ServicePort = 0
+	}
+	for index := range clone.PortDefinitions {
+		clone.PortDefinitions[index].Port = 0
+	}
+	clone.Ports = nil
+	return clone, nil
+}
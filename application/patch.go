@@ -0,0 +1,171 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Partial enables (or disables) partial updates, when enabled every setter sends only
+// the fields it changed with PATCH instead of PUT the whole cached definition
+func (ma *Application) Partial(enabled bool) *Application {
+
+	ma.partial = enabled
+	return ma
+}
+
+// Batch starts a batch of changes, setters only change the cached definition and record
+// changed fields until Commit sends them all with a single PATCH
+func (ma *Application) Batch() *Application {
+
+	ma.batch = true
+	return ma
+}
+
+// Pending returns the names of changed fields not sent yet to Marathon server
+func (ma *Application) Pending() []string {
+
+	fields := make([]string, 0, len(ma.pending))
+	for name := range ma.pending {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Commit sends every change recorded since Batch with a single PATCH and ends the batch
+func (ma *Application) Commit(force bool) error {
+
+	return ma.CommitContext(context.Background(), force)
+}
+
+// CommitContext sends every change recorded since Batch with a single PATCH and ends the batch, honoring ctx
+func (ma *Application) CommitContext(ctx context.Context, force bool) error {
+
+	ma.batch = false
+	if len(ma.pending) == 0 {
+		return nil
+	}
+	return ma.PatchContext(ctx, force, ma.Pending()...)
+}
+
+// Patch sends the named fields (JSON names, e.g. "cpus" or "env") of the cached definition
+// to Marathon server with PATCH, leaving any other field as it is on the server
+func (ma *Application) Patch(force bool, fields ...string) error {
+
+	return ma.PatchContext(context.Background(), force, fields...)
+}
+
+// PatchContext sends the named fields of the cached definition to Marathon server with PATCH, honoring ctx
+func (ma *Application) PatchContext(ctx context.Context, force bool, fields ...string) error {
+
+	if len(ma.app.App.ID) > 0 {
+
+		if err := ma.checkVersion(ctx); err != nil {
+			return err
+		}
+
+		body, err := ma.patchBody(fields)
+		if err != nil {
+			return err
+		}
+
+		path := fmt.Sprintf("%s%s", marathon.APIApps, utilities.DelInitialSlash(ma.app.App.ID))
+
+		marathon.Logger.Debug("Application: Patch(%v)[%v] %s", force, fields, path)
+
+		if err := ma.client.Do(ctx, http.MethodPatch, path, forceParams(force), body, ma.deploy, ma.fail); err != nil {
+			marathon.Logger.Debug("Application: Patch StatusCode: %d [{%+v}{%+v}]", ma.client.StatusCode(), ma.fail, err)
+			return err
+		}
+		marathon.Logger.Debug("Application: Patch StatusCode: %d [Deploy Id: %s => date: %v]", ma.client.StatusCode(), ma.deploy.ID, ma.deploy.Version)
+
+		for _, name := range fields {
+			delete(ma.pending, name)
+		}
+		ma.track(ma.deploy.Version)
+		return nil
+	}
+	return errors.New("app cannot be null nor empty")
+}
+
+// change sends the changed fields to Marathon server according to the update mode: the whole
+// definition with PUT, only the changed fields with PATCH, or nothing until Commit if batching
+func (ma *Application) change(ctx context.Context, force bool, fields ...string) error {
+
+	if !ma.partial && !ma.batch {
+		return ma.ApplyContext(ctx, force)
+	}
+
+	if ma.pending == nil {
+		ma.pending = make(map[string]bool)
+	}
+	for _, name := range fields {
+		ma.pending[name] = true
+	}
+
+	if ma.batch {
+		return nil
+	}
+	return ma.PatchContext(ctx, force, fields...)
+}
+
+// patchBody returns the id and the named fields of the cached definition as encoded by
+// MarshalJSON, a field dropped by omitempty is sent with its empty value to clear it
+func (ma *Application) patchBody(fields []string) (map[string]json.RawMessage, error) {
+
+	encoded, err := json.Marshal(ma.app.App)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &definition); err != nil {
+		return nil, err
+	}
+
+	body := map[string]json.RawMessage{"id": definition["id"]}
+	for _, name := range fields {
+		if raw, ok := definition[name]; ok {
+			body[name] = raw
+			continue
+		}
+		empty, err := emptyValue(name)
+		if err != nil {
+			return nil, err
+		}
+		body[name] = empty
+	}
+	return body, nil
+}
+
+// emptyValue returns the empty JSON value of the AppDefinition field named name
+func emptyValue(name string) (json.RawMessage, error) {
+
+	model := reflect.TypeOf(AppDefinition{})
+	for index := 0; index < model.NumField(); index++ {
+		field := model.Field(index)
+		if strings.Split(field.Tag.Get("json"), ",")[0] != name {
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Map, reflect.Struct:
+			return json.RawMessage(`{}`), nil
+		case reflect.Slice:
+			return json.RawMessage(`[]`), nil
+		case reflect.Ptr:
+			return json.RawMessage(`null`), nil
+		default:
+			return json.Marshal(reflect.Zero(field.Type).Interface())
+		}
+	}
+	return nil, fmt.Errorf("app has no field %s", name)
+}
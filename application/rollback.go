@@ -0,0 +1,165 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Rollback holds the outcome of rolling back a Marathon application to a previous version
+type Rollback struct {
+	// Version applied again
+	Version string
+	// Previous is the definition replaced, Applied the one of Version
+	Previous AppDefinition
+	Applied  AppDefinition
+	// Changed holds the names of top level fields changed by the rollback
+	Changed      []string
+	DeploymentID string
+	// Result is only set when the rollback waited for its deployment
+	Result *DeployResult
+}
+
+// RollbackTo applies again the definition of a previous version of a Marathon application, if
+// timeout is not zero it waits until the deployment finish and its tasks are running and healthy
+func (ma *Application) RollbackTo(version string, force bool, timeout time.Duration) (*Rollback, error) {
+
+	return ma.RollbackToContext(context.Background(), version, force, timeout)
+}
+
+// RollbackToContext applies again the definition of a previous version of a Marathon application,
+// if timeout is not zero it waits until the deployment finish, honoring ctx
+func (ma *Application) RollbackToContext(ctx context.Context, version string, force bool, timeout time.Duration) (*Rollback, error) {
+
+	if len(ma.app.App.ID) > 0 {
+		marathon.Logger.Debug("Application: RollbackTo %s version %s", ma.app.App.ID, version)
+
+		start := time.Now()
+
+		applied, err := ma.config(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+
+		previous := ma.app.App
+		base, err := encodeFields(previous)
+		if err != nil {
+			return nil, err
+		}
+		changed, err := changedFields(base, applied)
+		if err != nil {
+			return nil, err
+		}
+
+		ma.app.App = applied
+		if err := ma.ApplyContext(ctx, force); err != nil {
+			ma.app.App = previous
+			return nil, err
+		}
+
+		rollback := &Rollback{
+			Version:      version,
+			Previous:     previous,
+			Applied:      applied,
+			Changed:      changed,
+			DeploymentID: ma.deploy.ID,
+		}
+		if timeout > 0 {
+			rollback.Result, err = ma.await(ctx, ma.deploy.ID, start, timeout)
+		}
+		return rollback, err
+	}
+	return nil, errors.New("app cannot be null nor empty")
+}
+
+// RollbackSteps applies again the definition the Marathon application had steps versions ago
+func (ma *Application) RollbackSteps(steps int, force bool, timeout time.Duration) (*Rollback, error) {
+
+	return ma.RollbackStepsContext(context.Background(), steps, force, timeout)
+}
+
+// RollbackStepsContext applies again the definition the Marathon application had steps versions
+// ago, honoring ctx
+func (ma *Application) RollbackStepsContext(ctx context.Context, steps int, force bool, timeout time.Duration) (*Rollback, error) {
+
+	if len(ma.app.App.ID) > 0 {
+
+		if steps < 1 {
+			return nil, fmt.Errorf("invalid rollback steps %d, it must be 1 or more", steps)
+		}
+
+		id := ma.app.App.ID
+		versions, err := ma.versions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if steps >= len(versions) {
+			return nil, fmt.Errorf("the Marathon app %s has only %d versions, cannot roll back %d steps", id, len(versions), steps)
+		}
+		return ma.RollbackToContext(ctx, versions[len(versions)-1-steps], force, timeout)
+	}
+	return nil, errors.New("app cannot be null nor empty")
+}
+
+// versions returns the versions of the cached app from the oldest to the newest, the cached app
+// is not changed
+func (ma *Application) versions(ctx context.Context) ([]string, error) {
+
+	path := fmt.Sprintf(marathon.APIVersions, utilities.DelInitialSlash(ma.app.App.ID))
+
+	versions := &AppVersions{}
+	if err := ma.client.Do(ctx, http.MethodGet, path, nil, nil, versions, ma.fail); err != nil {
+		marathon.Logger.Debug("Application: Versions failed [%+v]", err)
+		return nil, err
+	}
+
+	// Timestamps are compared as times, their fractional seconds may have different lengths
+	times := make(map[string]time.Time, len(versions.Versions))
+	for _, version := range versions.Versions {
+		parsed, err := time.Parse(time.RFC3339Nano, version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %s of the Marathon app %s: %w", version, ma.app.App.ID, err)
+		}
+		times[version] = parsed
+	}
+	sort.SliceStable(versions.Versions, func(i, j int) bool {
+		return times[versions.Versions[i]].Before(times[versions.Versions[j]])
+	})
+	return versions.Versions, nil
+}
+
+// config returns the definition of version of the cached app without read-only fields, the
+// cached app is not changed
+func (ma *Application) config(ctx context.Context, version string) (AppDefinition, error) {
+
+	path := fmt.Sprintf(marathon.APIConfigByVersion, utilities.DelInitialSlash(ma.app.App.ID), version)
+
+	var content json.RawMessage
+	if err := ma.client.Do(ctx, http.MethodGet, path, nil, nil, &content, ma.fail); err != nil {
+		marathon.Logger.Debug("Application: Config failed [%+v]", err)
+		return AppDefinition{}, err
+	}
+
+	// The definition may come alone or wrapped as a single app
+	var wrapped struct {
+		App *AppDefinition `json:"app"`
+	}
+	if err := json.Unmarshal(content, &wrapped); err == nil && wrapped.App != nil && len(wrapped.App.ID) > 0 {
+		return *wrapped.App, nil
+	}
+
+	app := AppDefinition{}
+	if err := json.Unmarshal(content, &app); err != nil {
+		return AppDefinition{}, err
+	}
+	if len(app.ID) == 0 {
+		return AppDefinition{}, fmt.Errorf("version %s of the Marathon app %s not found", version, ma.app.App.ID)
+	}
+	return app, nil
+}
@@ -0,0 +1,383 @@
+package application
+
+import (
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Network modes of a Marathon App
+const (
+	NetworkHost      = "host"
+	NetworkContainer = "container"
+	NetworkBridge    = "container/bridge"
+)
+
+// Constraint operators accepted by Marathon
+const (
+	OperatorUnique  = "UNIQUE"
+	OperatorCluster = "CLUSTER"
+	OperatorGroupBy = "GROUP_BY"
+	OperatorLike    = "LIKE"
+	OperatorUnlike  = "UNLIKE"
+	OperatorMaxPer  = "MAX_PER"
+	OperatorIs      = "IS"
+)
+
+// MinCpus is the minimum amount of cpus accepted for an App
+const MinCpus = 0.001
+
+// pathSegment is the syntax of every segment of an App or Group id
+var pathSegment = regexp.MustCompile(`^(([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])\.)*([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])$`)
+
+// portProtocols are the protocols of health checks which need a port
+var portProtocols = map[string]bool{
+	"HTTP": true, "HTTPS": true, "TCP": true, "MESOS_HTTP": true, "MESOS_HTTPS": true, "MESOS_TCP": true,
+}
+
+// commandProtocols are the protocols of health checks which need a command
+var commandProtocols = map[string]bool{
+	"COMMAND": true, "MESOS_COMMAND": true,
+}
+
+// ValidatePath returns an error if id is not a valid App or Group id, relative or absolute
+func ValidatePath(id string) error {
+
+	if len(id) == 0 {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if id == "/" {
+		return nil
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(id, "/"), "/") {
+		if segment == "." || segment == ".." {
+			continue
+		}
+		if !pathSegment.MatchString(segment) {
+			return fmt.Errorf("invalid path segment %q of %s, it must be lowercase letters, digits, hyphens and dots, starting and ending with a letter or digit", segment, id)
+		}
+	}
+	return nil
+}
+
+// Validate checks the definition before sending it to Marathon server, it returns every
+// problem found as marathon.ValidationErrors, or nil if the definition is valid
+func (ad *AppDefinition) Validate() error {
+
+	var errs marathon.ValidationErrors
+
+	if err := ValidatePath(ad.ID); err != nil {
+		errs.Add("/id", "%v", err)
+	} else if ad.ID == "/" {
+		errs.Add("/id", "the root group cannot be used as app id")
+	}
+
+	ad.validateCommand(&errs)
+	ad.validateResources(&errs)
+	ad.validateNetworks(&errs)
+	ad.validateHealthChecks(&errs)
+	ad.validateConstraints(&errs)
+	ad.validateStrategies(&errs)
+
+	for name, secret := range ad.EnvSecrets {
+		if _, ok := ad.Secrets[secret]; !ok {
+			errs.Add(fmt.Sprintf("/env/%s", name), "references secret %s not defined in secrets", secret)
+		}
+	}
+	return errs.Err()
+}
+
+// validateCommand checks what the App runs
+func (ad *AppDefinition) validateCommand(errs *marathon.ValidationErrors) {
+
+	if len(ad.Cmd) > 0 && len(ad.Args) > 0 {
+		errs.Add("/cmd", "cmd and args cannot be both defined")
+	}
+
+	image := ad.Container.Docker.Image
+	if len(ad.Cmd) == 0 && len(ad.Args) == 0 && len(image) == 0 {
+		errs.Add("/cmd", "an app needs cmd, args or a container image")
+	}
+	if len(ad.Container.Type) > 0 && ad.Container.Type != marathon.ContainerDocker && ad.Container.Type != marathon.ContainerMesos {
+		errs.Add("/container/type", "invalid container type %s, it must be %s or %s", ad.Container.Type, marathon.ContainerDocker, marathon.ContainerMesos)
+	}
+	if ad.Container.Type == marathon.ContainerDocker && len(image) == 0 {
+		errs.Add("/container/docker/image", "a DOCKER container needs an image")
+	}
+	if len(image) > 0 {
+		if _, err := marathon.ParseImage(image); err != nil {
+			errs.Add("/container/docker/image", "%v", err)
+		}
+	}
+}
+
+// validateResources checks resources and instances
+func (ad *AppDefinition) validateResources(errs *marathon.ValidationErrors) {
+
+	// Left out cpus are not sent, so they get the Marathon default
+	if ad.Cpus != 0 && ad.Cpus < MinCpus {
+		errs.Add("/cpus", "cpus must be %v or more", MinCpus)
+	}
+	if ad.Mem < 0 {
+		errs.Add("/mem", "mem cannot be negative")
+	}
+	if ad.Disk < 0 {
+		errs.Add("/disk", "disk cannot be negative")
+	}
+	if ad.Gpus < 0 {
+		errs.Add("/gpus", "gpus cannot be negative")
+	}
+	if ad.Instances < 0 {
+		errs.Add("/instances", "instances cannot be negative")
+	}
+	if ad.BackoffFactor != 0 && ad.BackoffFactor < 1 {
+		errs.Add("/backoffFactor", "backoffFactor must be 1 or more")
+	}
+	if ad.BackoffSeconds < 0 {
+		errs.Add("/backoffSeconds", "backoffSeconds cannot be negative")
+	}
+	if ad.MaxLaunchDelaySeconds < 0 {
+		errs.Add("/maxLaunchDelaySeconds", "maxLaunchDelaySeconds cannot be negative")
+	}
+	if len(ad.KillSelection) > 0 && ad.KillSelection != "YOUNGEST_FIRST" && ad.KillSelection != "OLDEST_FIRST" {
+		errs.Add("/killSelection", "invalid killSelection %s, it must be YOUNGEST_FIRST or OLDEST_FIRST", ad.KillSelection)
+	}
+}
+
+// networkMode returns the network mode of the App, host if it has no networks
+func (ad *AppDefinition) networkMode() string {
+
+	if len(ad.Networks) == 0 || len(ad.Networks[0].Mode) == 0 {
+		return NetworkHost
+	}
+	return ad.Networks[0].Mode
+}
+
+// portMappings returns the port mappings of the App and the path they were found at
+func (ad *AppDefinition) portMappings() ([]marathon.PortMapping, string) {
+
+	if len(ad.Container.PortMappings) == 0 && len(ad.Container.Docker.PortMappings) > 0 {
+		return ad.Container.Docker.PortMappings, "/container/docker/portMappings"
+	}
+	return ad.Container.PortMappings, "/container/portMappings"
+}
+
+// ports returns the number of ports allocated to every task of the App
+func (ad *AppDefinition) ports() int {
+
+	if ad.networkMode() != NetworkHost {
+		mappings, _ := ad.portMappings()
+		return len(mappings)
+	}
+	switch {
+	case len(ad.PortDefinitions) > 0:
+		return len(ad.PortDefinitions)
+	case len(ad.Ports) > 0:
+		return len(ad.Ports)
+	default:
+		// Marathon allocates a port if none is defined
+		return 1
+	}
+}
+
+// validateNetworks checks networks, port mappings and port definitions are consistent
+func (ad *AppDefinition) validateNetworks(errs *marathon.ValidationErrors) {
+
+	mode := ad.networkMode()
+	names := make(map[string]bool)
+
+	for index, network := range ad.Networks {
+		path := fmt.Sprintf("/networks(%d)", index)
+		switch network.Mode {
+		case NetworkHost, NetworkBridge:
+			if len(ad.Networks) > 1 {
+				errs.Add(path+"/mode", "%s network cannot be combined with other networks", network.Mode)
+			}
+		case NetworkContainer:
+			if len(ad.Networks) > 1 && len(network.Name) == 0 {
+				errs.Add(path+"/name", "every container network needs a name when there are several")
+			}
+			names[network.Name] = true
+		default:
+			errs.Add(path+"/mode", "invalid network mode %q, it must be %s, %s or %s", network.Mode, NetworkHost, NetworkContainer, NetworkBridge)
+		}
+	}
+
+	mappings, mappingsPath := ad.portMappings()
+	if mode == NetworkHost && len(mappings) > 0 {
+		errs.Add(mappingsPath, "portMappings are not allowed with host networking, use portDefinitions")
+	}
+	if mode != NetworkHost && len(ad.PortDefinitions) > 0 {
+		errs.Add("/portDefinitions", "portDefinitions are only allowed with host networking, use portMappings")
+	}
+	if len(ad.Ports) > 0 && len(ad.PortDefinitions) > 0 {
+		errs.Add("/ports", "ports and portDefinitions cannot be both defined")
+	}
+
+	mappingNames := make(map[string]bool)
+	for index, mapping := range mappings {
+		path := fmt.Sprintf("%s(%d)", mappingsPath, index)
+
+		validatePort(path+"/containerPort", mapping.ContainerPort, errs)
+		validatePort(path+"/hostPort", mapping.HostPort, errs)
+		validatePort(path+"/servicePort", mapping.ServicePort, errs)
+		validateProtocol(path+"/protocol", mapping.Protocol, errs)
+
+		if mode == NetworkBridge && len(mapping.NetworkNames) > 0 {
+			errs.Add(path+"/networkNames", "networkNames are only allowed with container networking")
+		}
+		for _, name := range mapping.NetworkNames {
+			if !names[name] {
+				errs.Add(path+"/networkNames", "network %s is not defined in networks", name)
+			}
+		}
+		if len(mapping.Name) > 0 {
+			if mappingNames[mapping.Name] {
+				errs.Add(path+"/name", "duplicated port name %s", mapping.Name)
+			}
+			mappingNames[mapping.Name] = true
+		}
+	}
+
+	for index, definition := range ad.PortDefinitions {
+		path := fmt.Sprintf("/portDefinitions(%d)", index)
+
+		validatePort(path+"/port", definition.Port, errs)
+		validateProtocol(path+"/protocol", definition.Protocol, errs)
+	}
+}
+
+// validateHealthChecks checks protocols, ports and commands of every health check
+func (ad *AppDefinition) validateHealthChecks(errs *marathon.ValidationErrors) {
+
+	ports := ad.ports()
+	for index, check := range ad.HealthChecks {
+		path := fmt.Sprintf("/healthChecks(%d)", index)
+
+		protocol := check.Protocol
+		if len(protocol) == 0 {
+			protocol = "HTTP"
+		}
+
+		switch {
+		case portProtocols[protocol]:
+			if check.Port > 0 {
+				validatePort(path+"/port", check.Port, errs)
+				if check.PortIndex > 0 {
+					errs.Add(path+"/portIndex", "port and portIndex cannot be both defined")
+				}
+			} else if check.PortIndex < 0 || check.PortIndex >= ports {
+				errs.Add(path+"/portIndex", "portIndex %d is out of range, the app has %d ports", check.PortIndex, ports)
+			}
+		case commandProtocols[protocol]:
+			if check.Command == nil || len(check.Command.Value) == 0 {
+				errs.Add(path+"/command", "%s health checks need a command", protocol)
+			}
+		default:
+			errs.Add(path+"/protocol", "invalid health check protocol %s", check.Protocol)
+		}
+
+		if check.GracePeriodSeconds < 0 || check.IntervalSeconds < 0 || check.TimeoutSeconds < 0 || check.DelaySeconds < 0 {
+			errs.Add(path, "health check seconds cannot be negative")
+		}
+		if check.MaxConsecutiveFailures < 0 {
+			errs.Add(path+"/maxConsecutiveFailures", "maxConsecutiveFailures cannot be negative")
+		}
+	}
+}
+
+// validateConstraints checks fields, operators and values of every constraint
+func (ad *AppDefinition) validateConstraints(errs *marathon.ValidationErrors) {
+
+	for index, constraint := range ad.Constraints {
+		path := fmt.Sprintf("/constraints(%d)", index)
+
+		if len(constraint) < 2 || len(constraint) > 3 {
+			errs.Add(path, "a constraint must be [field, operator] or [field, operator, value]")
+			continue
+		}
+		if len(constraint[0]) == 0 {
+			errs.Add(path, "constraint field cannot be empty")
+		}
+
+		operator := constraint[1]
+		value, hasValue := "", len(constraint) == 3
+		if hasValue {
+			value = constraint[2]
+		}
+
+		switch operator {
+		case OperatorUnique:
+			if hasValue {
+				errs.Add(path, "%s constraints take no value", operator)
+			}
+		case OperatorCluster:
+		case OperatorGroupBy:
+			if hasValue {
+				if groups, err := strconv.Atoi(value); err != nil || groups < 1 {
+					errs.Add(path, "%s value must be a positive integer", operator)
+				}
+			}
+		case OperatorLike, OperatorUnlike:
+			if !hasValue {
+				errs.Add(path, "%s constraints need a regular expression", operator)
+			} else if _, err := regexp.Compile(value); err != nil {
+				errs.Add(path, "invalid regular expression %q: %v", value, err)
+			}
+		case OperatorMaxPer:
+			if tasks, err := strconv.Atoi(value); !hasValue || err != nil || tasks < 1 {
+				errs.Add(path, "%s constraints need a positive integer value", operator)
+			}
+		case OperatorIs:
+			if !hasValue || len(value) == 0 {
+				errs.Add(path, "%s constraints need a value", operator)
+			}
+		default:
+			errs.Add(path, "invalid constraint operator %s", operator)
+		}
+	}
+}
+
+// validateStrategies checks upgrade and unreachable strategies
+func (ad *AppDefinition) validateStrategies(errs *marathon.ValidationErrors) {
+
+	upgrade := ad.UpgradeStrategy
+	if upgrade.MinimumHealthCapacity < 0 || upgrade.MinimumHealthCapacity > 1 {
+		errs.Add("/upgradeStrategy/minimumHealthCapacity", "minimumHealthCapacity must be between 0 and 1")
+	}
+	if upgrade.MaximumOverCapacity < 0 || upgrade.MaximumOverCapacity > 1 {
+		errs.Add("/upgradeStrategy/maximumOverCapacity", "maximumOverCapacity must be between 0 and 1")
+	}
+	if ad.Residency != nil && upgrade.MaximumOverCapacity != 0 {
+		errs.Add("/upgradeStrategy/maximumOverCapacity", "apps with residency (persistent volumes) need maximumOverCapacity 0")
+	}
+
+	unreachable := ad.UnreachableStrategy
+	if unreachable.InactiveAfterSeconds < 0 {
+		errs.Add("/unreachableStrategy/inactiveAfterSeconds", "inactiveAfterSeconds cannot be negative")
+	}
+	if unreachable.ExpungeAfterSeconds < unreachable.InactiveAfterSeconds {
+		errs.Add("/unreachableStrategy/expungeAfterSeconds", "expungeAfterSeconds must be greater than or equal to inactiveAfterSeconds")
+	}
+}
+
+// validatePort checks port is a valid port number, 0 means any port
+func validatePort(path string, port int, errs *marathon.ValidationErrors) {
+
+	if port < 0 || port > 65535 {
+		errs.Add(path, "invalid port %d, it must be between 0 and 65535", port)
+	}
+}
+
+// validateProtocol checks protocol of a port
+func validateProtocol(path, protocol string, errs *marathon.ValidationErrors) {
+
+	switch protocol {
+	case "", "tcp", "udp", "udp,tcp", "tcp,udp":
+	default:
+		errs.Add(path, "invalid protocol %s, it must be tcp, udp or udp,tcp", protocol)
+	}
+}
@@ -0,0 +1,228 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/deployment"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"time"
+)
+
+// ErrTimeout is returned when app tasks are not running and healthy after the timeout
+var ErrTimeout = errors.New("exit by timeout... tasks still not running and healthy")
+
+// Status wraps an AppStatus element returned by the Marathon API
+type Status struct {
+	App AppStatus `json:"app"`
+}
+
+// AppStatus reflects the runtime state of a Marathon App
+type AppStatus struct {
+	ID              string                 `json:"id"`
+	Instances       int                    `json:"instances"`
+	Cpus            float64                `json:"cpus"`
+	Mem             float64                `json:"mem"`
+	HealthChecks    []marathon.Healthcheck `json:"healthChecks,omitempty"`
+	TasksStaged     int                    `json:"tasksStaged"`
+	TasksRunning    int                    `json:"tasksRunning"`
+	TasksHealthy    int                    `json:"tasksHealthy"`
+	TasksUnhealthy  int                    `json:"tasksUnhealthy"`
+	Deployments     []AppDeployment        `json:"deployments,omitempty"`
+	LastTaskFailure *TaskFailure           `json:"lastTaskFailure,omitempty"`
+}
+
+// AppDeployment reflects a deployment in course of a Marathon App
+type AppDeployment struct {
+	ID string `json:"id"`
+}
+
+// TaskFailure reflects the data used by the sub-element lastTaskFailure on a Marathon App
+type TaskFailure struct {
+	AppID     string    `json:"appId"`
+	Host      string    `json:"host"`
+	Message   string    `json:"message"`
+	State     string    `json:"state"`
+	TaskID    string    `json:"taskId"`
+	Timestamp time.Time `json:"timestamp"`
+	Version   time.Time `json:"version"`
+}
+
+// DeployResult holds the outcome of a change awaited until app tasks are running and healthy
+type DeployResult struct {
+	AppID          string
+	DeploymentID   string
+	Duration       time.Duration
+	Instances      int
+	TasksRunning   int
+	TasksHealthy   int
+	TasksUnhealthy int
+	Failures       []TaskFailure
+}
+
+// Ready returns true if all instances of the app are running and, if it has health checks, healthy
+func (as *AppStatus) Ready() bool {
+
+	if as.TasksRunning < as.Instances || as.TasksStaged > 0 {
+		return false
+	}
+	if len(as.HealthChecks) > 0 && as.TasksHealthy < as.Instances {
+		return false
+	}
+	return len(as.Deployments) == 0
+}
+
+// Status returns the runtime state of a Marathon application
+func (ma *Application) Status() (*AppStatus, error) {
+
+	return ma.StatusContext(context.Background())
+}
+
+// StatusContext returns the runtime state of a Marathon application, honoring ctx
+func (ma *Application) StatusContext(ctx context.Context) (*AppStatus, error) {
+
+	if len(ma.app.App.ID) > 0 {
+
+		path := fmt.Sprintf("%s%s", marathon.APIApps, utilities.DelInitialSlash(ma.app.App.ID))
+
+		status := &Status{}
+		if err := ma.client.Do(ctx, http.MethodGet, path, nil, nil, status, ma.fail); err != nil {
+			marathon.Logger.Debug("Application: Status failed [%+v]", err)
+			return nil, err
+		}
+		return &status.App, nil
+	}
+	return nil, errors.New("app cannot be null nor empty")
+}
+
+// DeploymentID returns the id of the deployment started by the last change sent to Marathon
+func (ma *Application) DeploymentID() string {
+
+	return ma.deploy.ID
+}
+
+// ApplyAndWait sends all changes of a Marathon application and waits until its deployment
+// finish and its tasks are running and healthy
+func (ma *Application) ApplyAndWait(force bool, timeout time.Duration) (*DeployResult, error) {
+
+	return ma.ApplyAndWaitContext(context.Background(), force, timeout)
+}
+
+// ApplyAndWaitContext sends all changes of a Marathon application and waits until its deployment
+// finish and its tasks are running and healthy, honoring ctx
+func (ma *Application) ApplyAndWaitContext(ctx context.Context, force bool, timeout time.Duration) (*DeployResult, error) {
+
+	start := time.Now()
+	if err := ma.ApplyContext(ctx, force); err != nil {
+		return nil, err
+	}
+	return ma.await(ctx, ma.deploy.ID, start, timeout)
+}
+
+// Await waits until deployment id finish and the Marathon application tasks are running and healthy
+func (ma *Application) Await(id string, timeout time.Duration) (*DeployResult, error) {
+
+	return ma.AwaitContext(context.Background(), id, timeout)
+}
+
+// AwaitContext waits until deployment id finish and the Marathon application tasks are running
+// and healthy, honoring ctx
+func (ma *Application) AwaitContext(ctx context.Context, id string, timeout time.Duration) (*DeployResult, error) {
+
+	if len(ma.app.App.ID) > 0 {
+		return ma.await(ctx, id, time.Now(), timeout)
+	}
+	return nil, errors.New("app cannot be null nor empty")
+}
+
+// await polls deployment id and then app status until it is ready or timeout is reached
+func (ma *Application) await(ctx context.Context, id string, start time.Time, timeout time.Duration) (*DeployResult, error) {
+
+	result := &DeployResult{AppID: ma.app.App.ID, DeploymentID: id}
+	finish := start.Add(timeout)
+
+	marathon.Logger.Debug("Application: Await %s deployment Id = %s", result.AppID, id)
+
+	if len(id) > 0 {
+		if err := deployment.New(ma.client).AwaitContext(ctx, id, time.Until(finish)); err != nil {
+			result.Duration = time.Since(start)
+			return result, err
+		}
+	}
+
+	// iterate while tasks are not ready or timeout don't reached
+	for {
+		status, err := ma.StatusContext(ctx)
+		if err != nil {
+			result.Duration = time.Since(start)
+			return result, err
+		}
+
+		result.Instances = status.Instances
+		result.TasksRunning = status.TasksRunning
+		result.TasksHealthy = status.TasksHealthy
+		result.TasksUnhealthy = status.TasksUnhealthy
+		result.Duration = time.Since(start)
+
+		if failure := status.LastTaskFailure; failure != nil && failure.Timestamp.After(start) && !hasFailure(result.Failures, failure.TaskID) {
+			result.Failures = append(result.Failures, *failure)
+		}
+
+		if status.Ready() {
+			return result, nil
+		}
+		if time.Now().After(finish) {
+			return result, ErrTimeout
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
+	}
+}
+
+// hasFailure returns true if failures contains a failure of taskID
+func hasFailure(failures []TaskFailure, taskID string) bool {
+
+	for _, failure := range failures {
+		if failure.TaskID == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyAllAndWait sends apps definitions to Marathon and then waits until all deployments
+// finish and their tasks are running and healthy, the first failure found is returned
+func ApplyAllAndWait(ctx context.Context, client *marathon.Client, apps []AppDefinition, force bool, timeout time.Duration) ([]*DeployResult, error) {
+
+	start := time.Now()
+	finish := start.Add(timeout)
+
+	// First of all, we send every change so deployments run concurrently on Marathon
+	deployments := make(map[string]string, len(apps))
+	for _, app := range apps {
+		appClient := New(client)
+		if err := appClient.Set(app).ApplyContext(ctx, force); err != nil {
+			return nil, err
+		}
+		deployments[app.ID] = appClient.DeploymentID()
+	}
+
+	// Then, we wait for each one within the same timeout
+	results := make([]*DeployResult, 0, len(apps))
+	for _, app := range apps {
+		result, err := New(client).Set(app).AwaitContext(ctx, deployments[app.ID], time.Until(finish))
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,223 @@
+package bluegreen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/deployment"
+	"strings"
+	"time"
+)
+
+// Colors of a blue-green deployment, each one is the suffix of its app id
+const (
+	Blue  = "blue"
+	Green = "green"
+)
+
+// LabelColor is the label holding the color of every app deployed by BlueGreen
+const LabelColor = "HAPROXY_DEPLOYMENT_COLOR"
+
+// DefaultTimeout is the time to wait for each step if Options has no Timeout
+const DefaultTimeout = 10 * time.Minute
+
+// DefaultRoutingLabels are the labels moved from the old color to the new one if Options has none
+var DefaultRoutingLabels = []string{"HAPROXY_0_VHOST", "HAPROXY_DEPLOYMENT_GROUP"}
+
+// Options of a blue-green deployment
+type Options struct {
+	// RoutingLabels are the labels used by the load balancer to route traffic to the app
+	RoutingLabels []string
+	// Timeout to wait for each deployment step
+	Timeout time.Duration
+	// Force changes even if the apps are locked by other deployments
+	Force bool
+	// KeepOld keeps the old color scaled to zero instead of destroying it
+	KeepOld bool
+}
+
+// Result holds the outcome of a blue-green deployment
+type Result struct {
+	// From is the id of the old color, To the id of the new one
+	From string
+	To   string
+	// Color and Image of the new color
+	Color string
+	Image string
+	// Result of waiting the new color tasks running and healthy
+	Result *application.DeployResult
+	// RolledBack is true if the deployment failed and the old color was restored
+	RolledBack bool
+}
+
+// BlueGreen deploys new versions of Marathon applications side by side with the running ones
+type BlueGreen struct {
+	client  *marathon.Client
+	options Options
+}
+
+// New returns a new instance of BlueGreen with options
+func New(client *marathon.Client, options Options) *BlueGreen {
+
+	if client != nil {
+		if len(options.RoutingLabels) == 0 {
+			options.RoutingLabels = DefaultRoutingLabels
+		}
+		if options.Timeout <= 0 {
+			options.Timeout = DefaultTimeout
+		}
+		return &BlueGreen{
+			client:  client,
+			options: options,
+		}
+	}
+	return nil
+}
+
+// Deploy clones app id as the other color with image, once its tasks are healthy moves the
+// routing labels to it and removes the old color; on failure the old color is restored, unless
+// traffic already moved and only removing the old color failed
+func (bg *BlueGreen) Deploy(id, image string) (*Result, error) {
+
+	return bg.DeployContext(context.Background(), id, image)
+}
+
+// DeployContext clones app id as the other color with image and moves the routing labels to it
+// once its tasks are healthy, honoring ctx
+func (bg *BlueGreen) DeployContext(ctx context.Context, id, image string) (*Result, error) {
+
+	if len(id) == 0 {
+		return nil, errors.New("app cannot be null nor empty")
+	}
+
+	old := application.New(bg.client).GetContext(ctx, id)
+	if err := old.LastError(); err != nil {
+		return nil, err
+	}
+	original := old.AsRaw()
+
+	clone, color, err := bg.clone(original, image)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{From: original.ID, To: clone.ID, Color: color, Image: clone.Container.Docker.Image}
+	marathon.Logger.Debug("BlueGreen: Deploy %s as %s with image %s", result.From, result.To, result.Image)
+
+	current := application.New(bg.client).Set(clone)
+	retired := false
+
+	fail := func(err error) (*Result, error) {
+		bg.rollback(original, current, retired)
+		result.RolledBack = true
+		return result, err
+	}
+
+	// Start the new color without routing labels, the load balancer ignores it until it is healthy
+	if result.Result, err = current.ApplyAndWaitContext(ctx, bg.options.Force, bg.options.Timeout); err != nil {
+		return fail(fmt.Errorf("new color %s is not healthy: %w", clone.ID, err))
+	}
+
+	// Route traffic to the new color
+	current.Batch()
+	for _, name := range bg.options.RoutingLabels {
+		if value, ok := original.Labels[name]; ok {
+			_ = current.SetLabelContext(ctx, name, value, bg.options.Force)
+		}
+	}
+	if err := bg.commit(ctx, current); err != nil {
+		return fail(fmt.Errorf("unable to route traffic to %s: %w", clone.ID, err))
+	}
+
+	// Stop routing traffic to the old color and scale it down
+	retired = true
+	old.Batch()
+	for _, name := range bg.options.RoutingLabels {
+		_ = old.DelLabelContext(ctx, name, bg.options.Force)
+	}
+	_ = old.ScaleContext(ctx, 0, bg.options.Force)
+	if err := bg.commit(ctx, old); err != nil {
+		return fail(fmt.Errorf("unable to scale down %s: %w", original.ID, err))
+	}
+
+	// Traffic already moved, a failed cleanup leaves the new color in place
+	if !bg.options.KeepOld {
+		if err := old.DestroyContext(ctx); err != nil && !marathon.IsNotFound(err) {
+			return result, fmt.Errorf("unable to destroy %s: %w", original.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// clone returns app as the other color with image, an empty image keeps the current one
+func (bg *BlueGreen) clone(app application.AppDefinition, image string) (application.AppDefinition, string, error) {
+
+	// A copy sharing nothing with app, which must be kept to roll back
+	id, color := Next(app.ID)
+	clone, err := app.Clone(id)
+	if err != nil {
+		return clone, "", err
+	}
+
+	if len(image) > 0 {
+		if _, err := marathon.ParseImage(image); err != nil {
+			return clone, "", err
+		}
+		clone.Container.Docker.Image = image
+	}
+
+	if clone.Labels == nil {
+		clone.Labels = make(map[string]string)
+	}
+	for _, name := range bg.options.RoutingLabels {
+		delete(clone.Labels, name)
+	}
+	clone.Labels[LabelColor] = color
+
+	return clone, color, clone.Validate()
+}
+
+// commit sends the pending changes of app and waits its deployment
+func (bg *BlueGreen) commit(ctx context.Context, app *application.Application) error {
+
+	if err := app.CommitContext(ctx, bg.options.Force); err != nil {
+		return err
+	}
+	_, err := app.AwaitContext(ctx, app.DeploymentID(), bg.options.Timeout)
+	return err
+}
+
+// rollback cancels the deployment of current, destroys it and restores original if it was
+// retired; it runs even if the deployment context is done
+func (bg *BlueGreen) rollback(original application.AppDefinition, current *application.Application, retired bool) {
+
+	ctx := context.Background()
+	marathon.Logger.Debug("BlueGreen: Rollback %s", original.ID)
+
+	if id := current.DeploymentID(); len(id) > 0 {
+		_ = deployment.New(bg.client).RollbackContext(ctx, id)
+	}
+	if err := current.DestroyContext(ctx); err != nil && !marathon.IsNotFound(err) {
+		marathon.Logger.Debug("BlueGreen: Rollback unable to destroy new color [%+v]", err)
+	}
+	if retired {
+		if err := application.New(bg.client).Set(original).ApplyContext(ctx, true); err != nil {
+			marathon.Logger.Debug("BlueGreen: Rollback unable to restore %s [%+v]", original.ID, err)
+		}
+	}
+}
+
+// Next returns the id and color following app id: blue becomes green, green becomes blue and
+// apps without color become green
+func Next(id string) (string, string) {
+
+	switch {
+	case strings.HasSuffix(id, "-"+Blue):
+		return strings.TrimSuffix(id, Blue) + Green, Green
+	case strings.HasSuffix(id, "-"+Green):
+		return strings.TrimSuffix(id, Green) + Blue, Blue
+	}
+	return id + "-" + Green, Green
+}
@@ -0,0 +1,206 @@
+package bluegreen
+
+import (
+	"encoding/json"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// storeRedis saves the redis app of the mock server as id with routing labels
+func storeRedis(t *testing.T, id string) application.AppDefinition {
+
+	redisApp := application.App{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.AppRedis), &redisApp))
+
+	app := redisApp.App
+	app.ID = id
+	app.Labels = map[string]string{"HAPROXY_0_VHOST": "redis.example.com", "HAPROXY_DEPLOYMENT_GROUP": "redis", "TEAM": "infra"}
+
+	content, err := json.Marshal(app)
+	assert.Nil(t, err)
+	mockserver.StoreApp(string(content))
+	return app
+}
+
+// storedApp returns the app id saved in the mock server, nil if it does not exist
+func storedApp(t *testing.T, id string) *application.AppDefinition {
+
+	content := mockserver.StoredApp(id)
+	if len(content) == 0 {
+		return nil
+	}
+	app := &application.AppDefinition{}
+	assert.Nil(t, json.Unmarshal([]byte(content), app))
+	return app
+}
+
+func TestNew(t *testing.T) {
+
+	t.Run("nil if client is nil", func(t *testing.T) {
+
+		assert.Nil(t, New(nil, Options{}))
+	})
+
+	t.Run("default options", func(t *testing.T) {
+
+		_bg := New(marathon.New("http://127.0.0.1:8080"), Options{})
+
+		assert.Equal(t, DefaultRoutingLabels, _bg.options.RoutingLabels)
+		assert.Equal(t, DefaultTimeout, _bg.options.Timeout)
+	})
+}
+
+func TestNext(t *testing.T) {
+
+	for id, expected := range map[string][2]string{
+		"/infra/redis":       {"/infra/redis-green", Green},
+		"/infra/redis-green": {"/infra/redis-blue", Blue},
+		"/infra/redis-blue":  {"/infra/redis-green", Green},
+		"/infra/blueberry":   {"/infra/blueberry-green", Green},
+	} {
+		next, color := Next(id)
+		assert.Equal(t, expected[0], next, id)
+		assert.Equal(t, expected[1], color, id)
+	}
+}
+
+func TestBlueGreen_Deploy(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	client := marathon.New(server.URL)
+
+	t.Run("error if app is empty or does not exist", func(t *testing.T) {
+
+		_, err := New(client, Options{}).Deploy("", "redis:6")
+		assert.Equal(t, "app cannot be null nor empty", err.Error())
+
+		_, err = New(client, Options{}).Deploy("/stored/missing-blue", "redis:6")
+		assert.True(t, marathon.IsNotFound(err))
+	})
+
+	t.Run("error if image is invalid, nothing is deployed", func(t *testing.T) {
+
+		storeRedis(t, "/stored/invalid-blue")
+
+		result, err := New(client, Options{}).Deploy("/stored/invalid-blue", "Redis:6")
+
+		assert.NotNil(t, err)
+		assert.Nil(t, result)
+		assert.Nil(t, storedApp(t, "/stored/invalid-green"))
+	})
+
+	t.Run("deploy blue as green", func(t *testing.T) {
+
+		original := storeRedis(t, "/stored/redis-blue")
+
+		result, err := New(client, Options{Timeout: 5 * time.Second, Force: true}).Deploy("/stored/redis-blue", "docker.io/redis-ha:6.0.9")
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, &Result{
+			From:   "/stored/redis-blue",
+			To:     "/stored/redis-green",
+			Color:  Green,
+			Image:  "docker.io/redis-ha:6.0.9",
+			Result: result.Result,
+		}, result)
+		assert.Equal(t, original.Instances, result.Result.TasksHealthy)
+
+		// The old color is gone and the new one has the routing labels
+		assert.Nil(t, storedApp(t, "/stored/redis-blue"))
+		green := storedApp(t, "/stored/redis-green")
+		assert.NotNil(t, green)
+		assert.Equal(t, "docker.io/redis-ha:6.0.9", green.Container.Docker.Image)
+		assert.Equal(t, map[string]string{
+			"HAPROXY_0_VHOST":          "redis.example.com",
+			"HAPROXY_DEPLOYMENT_GROUP": "redis",
+			"TEAM":                     "infra",
+			LabelColor:                 Green,
+		}, green.Labels)
+		assert.Equal(t, original.Instances, green.Instances)
+	})
+
+	t.Run("keep old color scaled down", func(t *testing.T) {
+
+		storeRedis(t, "/stored/cache")
+
+		result, err := New(client, Options{Timeout: 5 * time.Second, KeepOld: true}).Deploy("/stored/cache", "")
+
+		// We get not error, the image is kept
+		assert.Nil(t, err)
+		assert.Equal(t, "/stored/cache-green", result.To)
+		assert.Equal(t, "docker.io/redis-ha:5.0.5", result.Image)
+
+		old := storedApp(t, "/stored/cache")
+		assert.NotNil(t, old)
+		assert.Equal(t, 0, old.Instances)
+		assert.Equal(t, map[string]string{"TEAM": "infra"}, old.Labels)
+	})
+
+	t.Run("deploy a host networking app", func(t *testing.T) {
+
+		// Marathon returns both ports and portDefinitions
+		mockserver.StoreApp(mockserver.HostApp)
+
+		result, err := New(client, Options{Timeout: 5 * time.Second}).Deploy("/stored/host/web", "")
+
+		// We get not error and the new color gets its own service ports
+		assert.Nil(t, err)
+		assert.False(t, result.RolledBack)
+		green := storedApp(t, "/stored/host/web-green")
+		assert.NotNil(t, green)
+		assert.Empty(t, green.Ports)
+		assert.Equal(t, 0, green.PortDefinitions[0].Port)
+		assert.Equal(t, "web.example.com", green.Labels["HAPROXY_0_VHOST"])
+	})
+
+	t.Run("keep the new color if the old one cannot be destroyed", func(t *testing.T) {
+
+		storeRedis(t, "/stored/locked")
+
+		// The old color uses an image the mock server refuses to destroy
+		old := storedApp(t, "/stored/locked")
+		old.Container.Docker.Image = "docker.io/redis-ha:undeletable"
+		content, _ := json.Marshal(old)
+		mockserver.StoreApp(string(content))
+
+		result, err := New(client, Options{Timeout: 5 * time.Second}).Deploy("/stored/locked", "docker.io/redis-ha:6.0.9")
+
+		// We get an error but traffic stays on the new color
+		assert.NotNil(t, err)
+		assert.False(t, result.RolledBack)
+		green := storedApp(t, "/stored/locked-green")
+		assert.NotNil(t, green)
+		assert.Equal(t, "redis.example.com", green.Labels["HAPROXY_0_VHOST"])
+
+		retired := storedApp(t, "/stored/locked")
+		assert.NotNil(t, retired)
+		assert.Equal(t, 0, retired.Instances)
+	})
+
+	t.Run("roll back if the new color is not healthy", func(t *testing.T) {
+
+		original := storeRedis(t, "/stored/broken-green")
+
+		result, err := New(client, Options{Timeout: 2 * time.Second}).Deploy("/stored/broken-green", "docker.io/redis-ha:broken")
+
+		// We get an error and the old color is untouched
+		assert.NotNil(t, err)
+		assert.True(t, result.RolledBack)
+		assert.Equal(t, "/stored/broken-blue", result.To)
+		assert.Nil(t, storedApp(t, "/stored/broken-blue"))
+
+		old := storedApp(t, "/stored/broken-green")
+		assert.NotNil(t, old)
+		assert.Equal(t, original.Instances, old.Instances)
+		assert.Equal(t, original.Labels, old.Labels)
+	})
+}
@@ -0,0 +1,394 @@
+package canary
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/deployment"
+	"github.com/dotWicho/marathon/tasks"
+	"text/tabwriter"
+	"time"
+)
+
+// Suffix is appended to the id of an app to name its canary
+const Suffix = "-canary"
+
+// LabelCanary is the label set on every canary app, its value is the id of the stable app
+const LabelCanary = "CANARY_OF"
+
+// Default options of a rollout
+const (
+	DefaultBake     = 5 * time.Minute
+	DefaultInterval = 10 * time.Second
+	DefaultTimeout  = 10 * time.Minute
+)
+
+// DefaultSteps are the percents of instances running the new tag on each step if Options has none
+var DefaultSteps = []int{10, 50, 100}
+
+// Options of a canary rollout
+type Options struct {
+	// Steps are the percents of instances running the new tag, the last one must be 100
+	Steps []int
+	// Bake is how long the canary is watched on each step before going on
+	Bake time.Duration
+	// Interval between two checks of the canary tasks while baking
+	Interval time.Duration
+	// Timeout to wait for each deployment
+	Timeout time.Duration
+	// MaxRestarts is the number of canary task restarts tolerated on each step
+	MaxRestarts int
+	// Force changes even if the apps are locked by other deployments
+	Force bool
+	// Progress is called at the end of every step, if set
+	Progress func(Step)
+}
+
+// Step reports a single step of a rollout
+type Step struct {
+	// Percent of instances running the new tag
+	Percent int
+	// Canary and Stable instances
+	Canary int
+	Stable int
+	// Healthy and Unhealthy canary tasks at the end of the step, after Promote they are stable tasks
+	Healthy   int
+	Unhealthy int
+	// Restarts of canary tasks while baking
+	Restarts int
+	Duration time.Duration
+	// Err is the reason of a failed step, nil if it passed
+	Err error
+}
+
+// Report holds the outcome of a rollout step by step
+type Report struct {
+	App    string
+	Canary string
+	Tag    string
+	Steps  []Step
+	// Promoted is true if the stable app runs the new tag, Aborted if the rollout was undone
+	Promoted bool
+	Aborted  bool
+}
+
+// Canary rolls out new tags of Marathon applications progressively
+type Canary struct {
+	client  *marathon.Client
+	options Options
+}
+
+// New returns a new instance of Canary with options
+func New(client *marathon.Client, options Options) *Canary {
+
+	if client != nil {
+		if len(options.Steps) == 0 {
+			options.Steps = DefaultSteps
+		}
+		if options.Bake <= 0 {
+			options.Bake = DefaultBake
+		}
+		if options.Interval <= 0 {
+			options.Interval = DefaultInterval
+		}
+		if options.Timeout <= 0 {
+			options.Timeout = DefaultTimeout
+		}
+		return &Canary{
+			client:  client,
+			options: options,
+		}
+	}
+	return nil
+}
+
+// Rollout deploys tag to a canary of app id and promotes it step by step while it stays
+// healthy, the canary is removed and app id restored if any step fails
+func (c *Canary) Rollout(id, tag string) (*Report, error) {
+
+	return c.RolloutContext(context.Background(), id, tag)
+}
+
+// RolloutContext deploys tag to a canary of app id and promotes it step by step while it stays
+// healthy, honoring ctx
+func (c *Canary) RolloutContext(ctx context.Context, id, tag string) (*Report, error) {
+
+	if len(id) == 0 {
+		return nil, errors.New("app cannot be null nor empty")
+	}
+	if err := validSteps(c.options.Steps); err != nil {
+		return nil, err
+	}
+
+	stable := application.New(c.client).GetContext(ctx, id)
+	if err := stable.LastError(); err != nil {
+		return nil, err
+	}
+	original := stable.AsRaw()
+	total := original.Instances
+	if total < 1 {
+		return nil, fmt.Errorf("the Marathon app %s has no instances to roll out", id)
+	}
+
+	definition, err := original.Clone(original.ID + Suffix)
+	if err != nil {
+		return nil, err
+	}
+	if definition.Labels == nil {
+		definition.Labels = make(map[string]string)
+	}
+	definition.Labels[LabelCanary] = original.ID
+
+	report := &Report{App: original.ID, Canary: definition.ID, Tag: tag}
+	canary := application.New(c.client).Set(definition)
+
+	abort := func(step Step, err error) (*Report, error) {
+		step.Err = err
+		c.record(report, step)
+		c.abort(original, definition.ID, canary.DeploymentID())
+		report.Aborted = true
+		return report, err
+	}
+
+	// The canary is created with the new tag on its first step
+	created := false
+
+	for _, percent := range c.options.Steps {
+		start := time.Now()
+		step := Step{Percent: percent}
+
+		if percent == 100 {
+			if err := c.promote(ctx, stable, canary, tag, total, &step); err != nil {
+				return abort(step, err)
+			}
+			step.Duration = time.Since(start)
+			c.record(report, step)
+			report.Promoted = true
+			return report, nil
+		}
+
+		step.Canary = instancesOf(total, percent)
+		step.Stable = total - step.Canary
+
+		if !created {
+			// Setting the tag sends the whole definition, creating the canary with its instances
+			if err := c.create(ctx, canary, tag, step.Canary); err != nil {
+				return abort(step, fmt.Errorf("unable to create canary %s: %w", definition.ID, err))
+			}
+			created = true
+		} else if err := c.scale(ctx, canary, step.Canary); err != nil {
+			return abort(step, fmt.Errorf("unable to scale canary %s: %w", definition.ID, err))
+		}
+		if err := c.scale(ctx, stable, step.Stable); err != nil {
+			return abort(step, fmt.Errorf("unable to scale %s: %w", original.ID, err))
+		}
+
+		err := c.bake(ctx, definition, &step)
+		step.Duration = time.Since(start)
+		if err != nil {
+			return abort(step, err)
+		}
+		c.record(report, step)
+	}
+	return report, nil
+}
+
+// create sends the canary with tag and instances, then waits its deployment
+func (c *Canary) create(ctx context.Context, canary *application.Application, tag string, instances int) error {
+
+	definition := canary.AsRaw()
+	definition.Instances = instances
+	if err := canary.Set(definition).SetTagContext(ctx, tag, c.options.Force); err != nil {
+		return err
+	}
+	return deployment.New(c.client).AwaitContext(ctx, canary.DeploymentID(), c.options.Timeout)
+}
+
+// scale changes the instances of app and waits its deployment
+func (c *Canary) scale(ctx context.Context, app *application.Application, instances int) error {
+
+	if err := app.ScaleContext(ctx, instances, c.options.Force); err != nil {
+		return err
+	}
+	return deployment.New(c.client).AwaitContext(ctx, app.DeploymentID(), c.options.Timeout)
+}
+
+// bake watches the tasks of the canary until Bake elapses, it fails if they restart more than
+// MaxRestarts times or if they are not all healthy at the end; if the canary defines health
+// checks its tasks must have passed them, not only be running
+func (c *Canary) bake(ctx context.Context, canary application.AppDefinition, step *Step) error {
+
+	id := canary.ID
+	checked := len(canary.HealthChecks) > 0
+
+	finish := time.Now().Add(c.options.Bake)
+	seen := make(map[string]bool)
+
+	for {
+		current, err := tasks.New(c.client).GetContext(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Instances are fixed while baking, so every task beyond them replaces a dead one
+		for _, task := range current.AsRaw() {
+			seen[task.ID] = true
+		}
+		if restarts := len(seen) - step.Canary; restarts > step.Restarts {
+			step.Restarts = restarts
+		}
+		step.Healthy = healthy(current.Healthy(), checked)
+		step.Unhealthy = len(current.Unhealthy())
+
+		if step.Restarts > c.options.MaxRestarts {
+			return fmt.Errorf("canary %s tasks restarted %d times, more than %d", id, step.Restarts, c.options.MaxRestarts)
+		}
+		if !time.Now().Before(finish) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.options.Interval):
+		}
+	}
+
+	if step.Healthy < step.Canary || step.Unhealthy > 0 {
+		return fmt.Errorf("canary %s has %d of %d tasks healthy", id, step.Healthy, step.Canary)
+	}
+	return nil
+}
+
+// promote sets tag on the stable app with all its instances, once its tasks are healthy the
+// canary is removed
+func (c *Canary) promote(ctx context.Context, stable, canary *application.Application, tag string, total int, step *Step) error {
+
+	step.Stable = total
+
+	stable.Batch()
+	if err := stable.SetTagContext(ctx, tag, c.options.Force); err != nil {
+		return err
+	}
+	_ = stable.ScaleContext(ctx, total, c.options.Force)
+	if err := stable.CommitContext(ctx, c.options.Force); err != nil {
+		return fmt.Errorf("unable to promote %s: %w", tag, err)
+	}
+
+	result, err := stable.AwaitContext(ctx, stable.DeploymentID(), c.options.Timeout)
+	if result != nil {
+		step.Healthy = result.TasksHealthy
+		step.Unhealthy = result.TasksUnhealthy
+	}
+	if err != nil {
+		return fmt.Errorf("%s is not healthy with %s: %w", stable.AsRaw().ID, tag, err)
+	}
+
+	if err := canary.DestroyContext(ctx); err != nil && !marathon.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// abort cancels the last canary deployment, removes the canary and restores the stable app as
+// it was before the rollout; it runs even if the rollout context is done
+func (c *Canary) abort(original application.AppDefinition, canary, deploymentID string) {
+
+	ctx := context.Background()
+	marathon.Logger.Debug("Canary: Abort %s", original.ID)
+
+	if len(deploymentID) > 0 {
+		_ = deployment.New(c.client).RollbackContext(ctx, deploymentID)
+	}
+	if err := application.New(c.client).Set(application.AppDefinition{ID: canary}).DestroyContext(ctx); err != nil && !marathon.IsNotFound(err) {
+		marathon.Logger.Debug("Canary: Abort unable to destroy canary %s [%+v]", canary, err)
+	}
+	restored := application.New(c.client).Set(original)
+	if err := restored.ApplyContext(ctx, true); err != nil {
+		marathon.Logger.Debug("Canary: Abort unable to restore %s [%+v]", original.ID, err)
+		return
+	}
+	_ = deployment.New(c.client).AwaitContext(ctx, restored.DeploymentID(), c.options.Timeout)
+}
+
+// record appends step to report and notifies it
+func (c *Canary) record(report *Report, step Step) {
+
+	marathon.Logger.Debug("Canary: %s step %d%% [%+v]", report.App, step.Percent, step)
+
+	report.Steps = append(report.Steps, step)
+	if c.options.Progress != nil {
+		c.options.Progress(step)
+	}
+}
+
+// String formats the report as a table with a row per step
+func (r *Report) String() string {
+
+	buffer := &bytes.Buffer{}
+	writer := tabwriter.NewWriter(buffer, 0, 4, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(writer, "STEP\tCANARY\tSTABLE\tHEALTHY\tUNHEALTHY\tRESTARTS\tDURATION\tRESULT\n")
+	for _, step := range r.Steps {
+		result := "ok"
+		if step.Err != nil {
+			result = step.Err.Error()
+		}
+		_, _ = fmt.Fprintf(writer, "%d%%\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			step.Percent, step.Canary, step.Stable, step.Healthy, step.Unhealthy, step.Restarts, step.Duration.Round(time.Millisecond), result)
+	}
+	_ = writer.Flush()
+
+	switch {
+	case r.Promoted:
+		buffer.WriteString(fmt.Sprintf("%s promoted to %s\n", r.App, r.Tag))
+	case r.Aborted:
+		buffer.WriteString(fmt.Sprintf("%s rollout of %s aborted\n", r.App, r.Tag))
+	}
+	return buffer.String()
+}
+
+// validSteps returns an error if steps are not increasing percents ending at 100
+func validSteps(steps []int) error {
+
+	previous := 0
+	for _, percent := range steps {
+		if percent <= previous || percent > 100 {
+			return fmt.Errorf("invalid canary steps %v, they must be increasing percents up to 100", steps)
+		}
+		previous = percent
+	}
+	if previous != 100 {
+		return fmt.Errorf("invalid canary steps %v, the last one must be 100", steps)
+	}
+	return nil
+}
+
+// instancesOf returns percent of total instances rounded up, at least one and at most total
+func instancesOf(total, percent int) int {
+
+	instances := (total*percent + 99) / 100
+	if instances < 1 {
+		instances = 1
+	}
+	if instances > total {
+		instances = total
+	}
+	return instances
+}
+
+// healthy returns the number of tasks with their health checks passing, if checked is true tasks
+// without health check results yet are not healthy
+func healthy(running []marathon.TaskMarathon, checked bool) int {
+
+	count := 0
+	for _, task := range running {
+		if !checked || len(task.HealthCheckResults) > 0 {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,218 @@
+package canary
+
+import (
+	"encoding/json"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// storeRedis saves the redis app of the mock server as id with 10 instances
+func storeRedis(t *testing.T, id string) application.AppDefinition {
+
+	redisApp := application.App{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.AppRedis), &redisApp))
+
+	app := redisApp.App
+	app.ID = id
+	app.Instances = 10
+
+	content, err := json.Marshal(app)
+	assert.Nil(t, err)
+	mockserver.StoreApp(string(content))
+	return app
+}
+
+// storedApp returns the app id saved in the mock server, nil if it does not exist
+func storedApp(t *testing.T, id string) *application.AppDefinition {
+
+	content := mockserver.StoredApp(id)
+	if len(content) == 0 {
+		return nil
+	}
+	app := &application.AppDefinition{}
+	assert.Nil(t, json.Unmarshal([]byte(content), app))
+	return app
+}
+
+func TestNew(t *testing.T) {
+
+	t.Run("nil if client is nil", func(t *testing.T) {
+
+		assert.Nil(t, New(nil, Options{}))
+	})
+
+	t.Run("default options", func(t *testing.T) {
+
+		_canary := New(marathon.New("http://127.0.0.1:8080"), Options{})
+
+		assert.Equal(t, DefaultSteps, _canary.options.Steps)
+		assert.Equal(t, DefaultBake, _canary.options.Bake)
+		assert.Equal(t, DefaultInterval, _canary.options.Interval)
+		assert.Equal(t, DefaultTimeout, _canary.options.Timeout)
+	})
+}
+
+func Test_validSteps(t *testing.T) {
+
+	assert.Nil(t, validSteps([]int{10, 50, 100}))
+	assert.Nil(t, validSteps([]int{100}))
+	assert.NotNil(t, validSteps([]int{50, 10, 100}))
+	assert.NotNil(t, validSteps([]int{10, 50}))
+	assert.NotNil(t, validSteps([]int{0, 100}))
+	assert.NotNil(t, validSteps([]int{10, 150}))
+}
+
+func Test_instancesOf(t *testing.T) {
+
+	assert.Equal(t, 1, instancesOf(10, 10))
+	assert.Equal(t, 2, instancesOf(11, 10))
+	assert.Equal(t, 1, instancesOf(3, 1))
+	assert.Equal(t, 5, instancesOf(10, 50))
+	assert.Equal(t, 3, instancesOf(3, 100))
+}
+
+func TestCanary_Rollout(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	client := marathon.New(server.URL)
+	options := Options{Bake: 50 * time.Millisecond, Interval: 10 * time.Millisecond, Timeout: 5 * time.Second}
+
+	t.Run("error if app is empty, does not exist or steps are invalid", func(t *testing.T) {
+
+		_, err := New(client, options).Rollout("", "6.0.9")
+		assert.Equal(t, "app cannot be null nor empty", err.Error())
+
+		_, err = New(client, options).Rollout("/stored/missing", "6.0.9")
+		assert.True(t, marathon.IsNotFound(err))
+
+		_, err = New(client, Options{Steps: []int{10, 50}}).Rollout("/stored/missing", "6.0.9")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("promote step by step", func(t *testing.T) {
+
+		storeRedis(t, "/stored/queue")
+
+		var progress []int
+		promoting := options
+		promoting.Progress = func(step Step) { progress = append(progress, step.Percent) }
+
+		report, err := New(client, promoting).Rollout("/stored/queue", "6.0.9")
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.True(t, report.Promoted)
+		assert.False(t, report.Aborted)
+		assert.Equal(t, "/stored/queue-canary", report.Canary)
+		assert.Equal(t, []int{10, 50, 100}, progress)
+
+		assert.Len(t, report.Steps, 3)
+		assert.Equal(t, []int{1, 9, 1, 0}, []int{report.Steps[0].Canary, report.Steps[0].Stable, report.Steps[0].Healthy, report.Steps[0].Restarts})
+		assert.Equal(t, []int{5, 5, 5, 0}, []int{report.Steps[1].Canary, report.Steps[1].Stable, report.Steps[1].Healthy, report.Steps[1].Restarts})
+		assert.Equal(t, []int{0, 10, 10}, []int{report.Steps[2].Canary, report.Steps[2].Stable, report.Steps[2].Healthy})
+
+		// The stable app runs the new tag and the canary is gone
+		stable := storedApp(t, "/stored/queue")
+		assert.Equal(t, "docker.io/redis-ha:6.0.9", stable.Container.Docker.Image)
+		assert.Equal(t, 10, stable.Instances)
+		assert.Nil(t, storedApp(t, "/stored/queue-canary"))
+
+		assert.Contains(t, report.String(), "/stored/queue promoted to 6.0.9")
+	})
+
+	t.Run("canary of a host networking app gets its own service ports", func(t *testing.T) {
+
+		// Marathon returns both ports and portDefinitions of the stable app
+		app := application.AppDefinition{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.HostApp), &app))
+		app.ID = "/stored/host/api"
+		app.Container = marathon.Container{Type: marathon.ContainerDocker, Docker: marathon.Docker{Image: "docker.io/python:3.8"}}
+		content, _ := json.Marshal(app)
+		mockserver.StoreApp(string(content))
+
+		var canary *application.AppDefinition
+		watching := options
+		watching.Progress = func(step Step) {
+			if canary == nil {
+				canary = storedApp(t, "/stored/host/api-canary")
+			}
+		}
+
+		report, err := New(client, watching).Rollout("/stored/host/api", "3.9")
+
+		// We get not error, the canary did not reuse the ports of the stable app
+		assert.Nil(t, err)
+		assert.True(t, report.Promoted)
+		assert.NotNil(t, canary)
+		assert.Empty(t, canary.Ports)
+		assert.Equal(t, 0, canary.PortDefinitions[0].Port)
+	})
+
+	t.Run("abort if canary tasks restart", func(t *testing.T) {
+
+		storeRedis(t, "/stored/cache")
+
+		report, err := New(client, options).Rollout("/stored/cache", "6.0.9-flapping")
+
+		// We get an error on the first step and the stable app is restored
+		assert.NotNil(t, err)
+		assert.True(t, report.Aborted)
+		assert.Len(t, report.Steps, 1)
+		assert.Equal(t, err, report.Steps[0].Err)
+		assert.True(t, report.Steps[0].Restarts > 0)
+
+		stable := storedApp(t, "/stored/cache")
+		assert.Equal(t, "docker.io/redis-ha:5.0.5", stable.Container.Docker.Image)
+		assert.Equal(t, 10, stable.Instances)
+		assert.Nil(t, storedApp(t, "/stored/cache-canary"))
+
+		assert.Contains(t, report.String(), "/stored/cache rollout of 6.0.9-flapping aborted")
+	})
+
+	t.Run("abort if canary health checks did not report", func(t *testing.T) {
+
+		storeRedis(t, "/stored/checked")
+
+		report, err := New(client, options).Rollout("/stored/checked", "6.0.9-unchecked")
+
+		// Running tasks are not enough if the app has health checks
+		assert.NotNil(t, err)
+		assert.True(t, report.Aborted)
+		assert.Equal(t, []int{0, 0}, []int{report.Steps[0].Healthy, report.Steps[0].Unhealthy})
+		assert.Nil(t, storedApp(t, "/stored/checked-canary"))
+	})
+
+	t.Run("running tasks are enough without health checks", func(t *testing.T) {
+
+		app := storeRedis(t, "/stored/unchecked")
+		app.HealthChecks = nil
+		content, _ := json.Marshal(app)
+		mockserver.StoreApp(string(content))
+
+		report, err := New(client, options).Rollout("/stored/unchecked", "6.0.9-unchecked")
+
+		assert.Nil(t, err)
+		assert.True(t, report.Promoted)
+	})
+
+	t.Run("abort if canary tasks are unhealthy", func(t *testing.T) {
+
+		storeRedis(t, "/stored/store")
+
+		report, err := New(client, options).Rollout("/stored/store", "6.0.9-unhealthy")
+
+		assert.NotNil(t, err)
+		assert.True(t, report.Aborted)
+		assert.Equal(t, []int{0, 1}, []int{report.Steps[0].Healthy, report.Steps[0].Unhealthy})
+		assert.Equal(t, 10, storedApp(t, "/stored/store").Instances)
+		assert.Nil(t, storedApp(t, "/stored/store-canary"))
+	})
+}
@@ -0,0 +1,174 @@
+package marathon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewWithEndpoints returns a new Client given a list of Marathon servers base url,
+// requests are routed to the leader (or any healthy member) with failover between them
+func NewWithEndpoints(endpoints []string) *Client {
+
+	Logger.Debug("Creating Marathon Client with endpoints = %v", endpoints)
+
+	var members []string
+	var first *url.URL
+
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimSpace(endpoint)
+
+		baseURL, err := url.Parse(endpoint)
+		if len(endpoint) == 0 || err != nil {
+			Logger.Debug("Invalid baseURL %s", endpoint)
+			return nil
+		}
+		if first == nil {
+			first = baseURL
+		}
+		members = append(members, baseURL.String())
+	}
+	if first == nil {
+		Logger.Debug("Invalid baseURL")
+		return nil
+	}
+
+	_client := &Client{}
+	if _client.New(first) == nil {
+		return nil
+	}
+	_client.members = members
+	return _client
+}
+
+// Endpoints returns all Marathon servers known by the Client
+func (mc *Client) Endpoints() []string {
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return append([]string(nil), mc.members...)
+}
+
+// Endpoint returns the Marathon server used by next requests
+func (mc *Client) Endpoint() string {
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return mc.baseURL
+}
+
+// Discover probes every Marathon server and routes next requests to the leader
+func (mc *Client) Discover() error {
+
+	return mc.DiscoverContext(context.Background())
+}
+
+// DiscoverContext probes every Marathon server and routes next requests to the leader, honoring ctx
+func (mc *Client) DiscoverContext(ctx context.Context) error {
+
+	return mc.discover(ctx, "")
+}
+
+// discover probes /ping and /v2/leader of every member, selecting the leader if it is
+// healthy, otherwise any healthy member, failed endpoint is never selected
+func (mc *Client) discover(ctx context.Context, failed string) error {
+
+	members := mc.Endpoints()
+	if len(members) == 0 {
+		return nil
+	}
+
+	var leader string
+	var healthy []string
+	for _, endpoint := range members {
+		reported, err := mc.probe(ctx, endpoint)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		Logger.Debug("Discover: %s healthy=%v leader=%s", endpoint, err == nil, reported)
+
+		if err != nil || endpoint == failed {
+			continue
+		}
+		healthy = append(healthy, endpoint)
+		if len(leader) == 0 {
+			leader = reported
+		}
+	}
+	if len(healthy) == 0 {
+		return fmt.Errorf("unable to find a healthy Marathon server in %v", members)
+	}
+
+	// Prefer the leader, every request sent to a follower would be proxied to it
+	selected := healthy[0]
+	for _, endpoint := range healthy {
+		if parsed, err := url.Parse(endpoint); err == nil && len(leader) > 0 && parsed.Host == leader {
+			selected = endpoint
+		}
+	}
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	Logger.Debug("Discover: routing requests to %s", selected)
+	mc.baseURL = selected
+	if mc.Session != nil {
+		mc.Session.Base(selected)
+	}
+	return nil
+}
+
+// probe checks a Marathon server answers /ping and returns the leader it knows
+func (mc *Client) probe(ctx context.Context, endpoint string) (string, error) {
+
+	for _, path := range []string{APIPing, APILeader} {
+
+		requestURL, err := buildURL(endpoint, path, nil)
+		if err != nil {
+			return "", err
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+		if err != nil {
+			return "", err
+		}
+		request.Header = mc.headers()
+
+		response, err := mc.httpClient().Do(request)
+		if err != nil {
+			return "", err
+		}
+
+		leader := &Leader{}
+		if path == APILeader && response.StatusCode == http.StatusOK {
+			err = json.NewDecoder(response.Body).Decode(leader)
+		}
+		response.Body.Close()
+
+		switch {
+		case path == APIPing && response.StatusCode != http.StatusOK:
+			return "", fmt.Errorf("%s answered %d to %s", endpoint, response.StatusCode, path)
+		case path == APILeader && err == nil:
+			return leader.Leader, nil
+		}
+	}
+	// Server answers /ping but does not know its leader
+	return "", nil
+}
+
+// failover routes next requests away from failed endpoint, if there is other healthy member
+func (mc *Client) failover(ctx context.Context, failed string) bool {
+
+	if len(mc.Endpoints()) < 2 {
+		return false
+	}
+	Logger.Debug("Failover: %s is unavailable", failed)
+	if err := mc.discover(ctx, failed); err != nil {
+		return false
+	}
+	return mc.Endpoint() != failed
+}
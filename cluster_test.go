@@ -0,0 +1,202 @@
+package marathon
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_NewWithEndpoints(t *testing.T) {
+
+	t.Run("nil Client if send empty endpoints", func(t *testing.T) {
+
+		// Try to create Client
+		_client := NewWithEndpoints(nil)
+
+		// Client is nil
+		assert.Nil(t, _client)
+	})
+
+	t.Run("nil Client if send an invalid endpoint", func(t *testing.T) {
+
+		// Try to create Client
+		_client := NewWithEndpoints([]string{"http://127.0.0.1:8080", ""})
+
+		// Client is nil
+		assert.Nil(t, _client)
+	})
+
+	t.Run("valid Client if send valid endpoints", func(t *testing.T) {
+
+		// Try to create Client
+		_client := NewWithEndpoints([]string{"http://127.0.0.1:8080", "http://127.0.0.2:8080"})
+
+		// Client is not nil
+		assert.NotNil(t, _client)
+
+		// Check some values
+		assert.Equal(t, []string{"http://127.0.0.1:8080", "http://127.0.0.2:8080"}, _client.Endpoints())
+		assert.Equal(t, "http://127.0.0.1:8080", _client.Endpoint())
+	})
+
+	t.Run("valid Client if send a comma separated list to New", func(t *testing.T) {
+
+		// Try to create Client
+		_client := New("http://127.0.0.1:8080,http://127.0.0.2:8080")
+
+		// Client is not nil
+		assert.NotNil(t, _client)
+
+		// Check some values
+		assert.Len(t, _client.Endpoints(), 2)
+	})
+}
+
+func TestClient_Discover(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We create a follower which knows the Mock Server as leader
+	leaderURL, _ := url.Parse(server.URL)
+	follower := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case APIPing:
+			w.WriteHeader(http.StatusOK)
+		case APILeader:
+			buffer, _ := json.Marshal(&Leader{Leader: leaderURL.Host})
+			_, _ = w.Write(buffer)
+		default:
+			w.WriteHeader(http.StatusTemporaryRedirect)
+		}
+	}))
+	defer follower.Close()
+
+	// Try to create Client
+	_client := NewWithEndpoints([]string{follower.URL, server.URL})
+
+	// Fire up Discover
+	err := _client.Discover()
+
+	// We get nil error
+	assert.Nil(t, err)
+
+	// Requests are routed to the leader
+	assert.Equal(t, server.URL, _client.Endpoint())
+}
+
+func TestClient_Failover(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("failover when an endpoint is unreachable", func(t *testing.T) {
+
+		// We create a server and shutdown it to get an unreachable endpoint
+		down := httptest.NewServer(http.NotFoundHandler())
+		down.Close()
+
+		// Try to create Client
+		_client := NewWithEndpoints([]string{down.URL, server.URL})
+
+		// Fire up CheckConnection
+		err := _client.CheckConnection()
+
+		// We get nil error
+		assert.Nil(t, err)
+
+		// Check some values
+		assert.Equal(t, server.URL, _client.Endpoint())
+		assert.Equal(t, "v1.0.0", _client.Version())
+	})
+
+	t.Run("failover when an endpoint answers 503", func(t *testing.T) {
+
+		// We create a server without a leader
+		unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer unavailable.Close()
+
+		// Try to create Client
+		_client := NewWithEndpoints([]string{unavailable.URL, server.URL})
+
+		// Fire up CheckConnection
+		err := _client.CheckConnection()
+
+		// We get nil error
+		assert.Nil(t, err)
+
+		// Check some values
+		assert.Equal(t, http.StatusOK, _client.StatusCode())
+		assert.Equal(t, server.URL, _client.Endpoint())
+	})
+
+	t.Run("failover of a POST that never reached the server", func(t *testing.T) {
+
+		// We create a server and shutdown it to get an unreachable endpoint
+		down := httptest.NewServer(http.NotFoundHandler())
+		down.Close()
+
+		// Try to create Client
+		_client := NewWithEndpoints([]string{down.URL, server.URL})
+
+		// Fire up a POST
+		err := _client.Do(context.Background(), http.MethodPost, APIInfo, nil, map[string]string{}, nil, nil)
+
+		// We get nil error, it was sent to the other endpoint
+		assert.Nil(t, err)
+		assert.Equal(t, server.URL, _client.Endpoint())
+	})
+
+	t.Run("no failover of a POST dropped after it was sent", func(t *testing.T) {
+
+		// We create a server dropping connections once it reads the request
+		var received int32
+		dropping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&received, 1)
+			connection, _, _ := w.(http.Hijacker).Hijack()
+			_ = connection.Close()
+		}))
+		defer dropping.Close()
+
+		// Try to create Client
+		_client := NewWithEndpoints([]string{dropping.URL, server.URL})
+
+		// Fire up a POST
+		err := _client.Do(context.Background(), http.MethodPost, APIInfo, nil, map[string]string{}, nil, nil)
+
+		// We get the error, the request could have been applied
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&received))
+		assert.Equal(t, dropping.URL, _client.Endpoint())
+
+		// A GET is retried against the other endpoint
+		assert.Nil(t, _client.Do(context.Background(), http.MethodGet, APIInfo, nil, nil, nil, nil))
+		assert.Equal(t, server.URL, _client.Endpoint())
+	})
+
+	t.Run("get error when all endpoints are unreachable", func(t *testing.T) {
+
+		// We create a server and shutdown it to get an unreachable endpoint
+		down := httptest.NewServer(http.NotFoundHandler())
+		down.Close()
+
+		// Try to create Client
+		_client := NewWithEndpoints([]string{down.URL, down.URL + "/"})
+
+		// Fire up CheckConnection
+		err := _client.CheckConnection()
+
+		// We get error
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+// Command marathon-reconcile keeps a Marathon server in sync with a directory of app and group
+// definitions, reconciling them periodically and exposing its status over HTTP
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/reconcile"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+
+	var options reconcile.Options
+
+	base := flag.String("marathon", "http://127.0.0.1:8080", "Marathon URL, several ones separated by commas")
+	username := flag.String("username", "", "Marathon basic auth username")
+	password := flag.String("password", "", "Marathon basic auth password")
+	dir := flag.String("dir", ".", "directory of app and group definitions, .json and .yaml files")
+	listen := flag.String("listen", ":9090", "address serving /status and /healthz, empty to disable")
+	once := flag.Bool("once", false, "reconcile once and exit, with status 1 if it failed")
+	flag.DurationVar(&options.Interval, "interval", reconcile.DefaultInterval, "time between reconciliations")
+	flag.BoolVar(&options.Prune, "prune", false, "delete apps and groups inside defined groups but missing in their definition")
+	flag.BoolVar(&options.Force, "force", false, "change apps even if they are locked by other deployments")
+	flag.BoolVar(&options.DryRun, "dry-run", false, "only report drift without correcting it")
+	flag.Parse()
+
+	client := marathon.New(*base)
+	if client == nil {
+		log.Fatalf("invalid Marathon URL %s", *base)
+	}
+	if len(*username) > 0 {
+		client.SetBasicAuth(*username, *password)
+	}
+
+	options.Report = func(result reconcile.Result) {
+		log.Printf("reconciled %s", result)
+		for _, object := range result.Objects {
+			if object.State == reconcile.Drifted || object.State == reconcile.Corrected || object.State == reconcile.Failed {
+				log.Printf("  %s %s %s (%s) %s", object.State, object.Kind, object.ID, object.File, object.Error)
+			}
+		}
+	}
+	reconciler := reconcile.New(client, *dir, options)
+
+	if *once {
+		if reconciler.Reconcile().Failed() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(*listen) > 0 {
+		go func() {
+			if err := http.ListenAndServe(*listen, reconciler); err != nil {
+				log.Fatalf("unable to serve status: %s", err)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	log.Printf("reconciling %s every %v", *dir, options.Interval)
+	_ = reconciler.Run(ctx)
+}
@@ -0,0 +1,30 @@
+package marathon
+
+// ConflictPolicy tells what to do when a definition changed on Marathon server between
+// the moment it was read and the moment local changes are sent back
+type ConflictPolicy int
+
+const (
+	// Overwrite sends local changes anyway, last write wins
+	Overwrite ConflictPolicy = iota
+	// FailOnChange returns a ModifiedError and sends nothing
+	FailOnChange
+	// RebaseOnChange applies local changes on top of the current definition if they touch
+	// fields not changed on the server, otherwise it returns a ModifiedError
+	RebaseOnChange
+)
+
+// String returns the name of a ConflictPolicy
+func (cp ConflictPolicy) String() string {
+
+	switch cp {
+	case Overwrite:
+		return "overwrite"
+	case FailOnChange:
+		return "fail"
+	case RebaseOnChange:
+		return "rebase"
+	default:
+		return "unknown"
+	}
+}
@@ -11,16 +11,40 @@ const (
 	APIApps string = APIBase + "/apps/"
 	// APIGroups Groups endpoint
 	APIGroups string = APIBase + "/groups/"
+	// APIPods Pods endpoint
+	APIPods string = APIBase + "/pods/"
 	// APIDeployments Deployments endpoint
 	APIDeployments string = APIBase + "/deployments/"
+	// APITasks Tasks endpoint
+	APITasks string = APIBase + "/tasks"
+	// APITasksDelete Tasks bulk kill endpoint
+	APITasksDelete string = APITasks + "/delete"
+	// APIQueue Launch queue endpoint
+	APIQueue string = APIBase + "/queue/"
+	// APIMetrics Server metrics endpoint
+	APIMetrics string = "/metrics"
 	// APIPing Check connection endpoint resource
 	APIPing string = "/ping"
 	// APIInfo Server Info endpoint
 	APIInfo string = APIBase + "/info"
+	// APILeader Server Leader endpoint
+	APILeader string = APIBase + "/leader"
+	// APIPlugins Server plugins endpoint
+	APIPlugins string = APIBase + "/plugins"
+	// APIEvents Server-sent events endpoint
+	APIEvents string = APIBase + "/events"
+	// EventsBuffer size of the channel used to deliver events
+	EventsBuffer = 64
+	// EventsBackoff initial wait before reconnect to the events endpoint
+	EventsBackoff = 1 * time.Second
+	// EventsMaxBackoff maximum wait before reconnect to the events endpoint
+	EventsMaxBackoff = 30 * time.Second
 	// APIVersions Apps Configs versions endpoint
 	APIVersions string = APIApps + "%s/versions"
 	// APIConfigByVersion Apps Definition by version endpoint
 	APIConfigByVersion = APIVersions + "/%s"
 	// DockerImageRegEx RegEx used for docker images
+	//
+	// Deprecated: it only matches images with a registry and a tag, use ParseImage instead
 	DockerImageRegEx = `^(([a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)+)(?::(\d+))?)((?:([a-zA-Z0-9-\/]+)?))\/([a-zA-Z0-9-_]+):(.*)$`
 )
@@ -1,36 +1,103 @@
 package marathon
 
-// Container saves in a structured way the information of the containers of a task
+import "encoding/json"
+
+// Container types supported by Marathon
+const (
+	ContainerDocker string = "DOCKER"
+	ContainerMesos  string = "MESOS"
+)
+
+// Container saves in a structured way the information of the containers of a task,
+// Docker holds the image for both DOCKER and MESOS (Universal Container Runtime) types
 type Container struct {
 	Type         string        `json:"type"`
 	Docker       Docker        `json:"docker"`
 	Volumes      []Volume      `json:"volumes,omitempty"`
 	PortMappings []PortMapping `json:"portMappings,omitempty"`
+	LinuxInfo    *LinuxInfo    `json:"linuxInfo,omitempty"`
+
+	// Extra holds fields not modeled (or zero valued) in the original payload, re-emitted on marshal
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Docker holds all information of a docker representation of a task
 type Docker struct {
 	Image          string             `json:"image"`
 	Network        string             `json:"network,omitempty"`
+	PortMappings   []PortMapping      `json:"portMappings,omitempty"`
 	Privileged     bool               `json:"privileged"`
 	Parameters     []DockerParameters `json:"parameters,omitempty"`
 	ForcePullImage bool               `json:"forcePullImage"`
+	PullConfig     *PullConfig        `json:"pullConfig,omitempty"`
+
+	// Extra holds fields not modeled (or zero valued) in the original payload, re-emitted on marshal
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// PullConfig holds the secret used to pull an image from a private registry
+type PullConfig struct {
+	Secret string `json:"secret"`
+}
+
+// LinuxInfo holds Linux specific settings of a MESOS container
+type LinuxInfo struct {
+	Seccomp *Seccomp `json:"seccomp,omitempty"`
+	IPCInfo *IPCInfo `json:"ipcInfo,omitempty"`
+}
+
+// Seccomp holds the seccomp profile of a MESOS container
+type Seccomp struct {
+	ProfileName string `json:"profileName,omitempty"`
+	Unconfined  bool   `json:"unconfined"`
 }
 
-// Volume is Docker's Volume representation
+// IPCInfo holds the IPC namespace mode of a MESOS container
+type IPCInfo struct {
+	Mode    string `json:"mode"`
+	ShmSize int    `json:"shmSize,omitempty"`
+}
+
+// Volume is Container's Volume representation, a host volume sets HostPath, otherwise
+// one of Persistent, External or Secret describes it
 type Volume struct {
-	ContainerPath string `json:"containerPath"`
-	HostPath      string `json:"hostPath"`
-	Mode          string `json:"mode,omitempty"`
+	ContainerPath string            `json:"containerPath"`
+	HostPath      string            `json:"hostPath,omitempty"`
+	Mode          string            `json:"mode,omitempty"`
+	Persistent    *PersistentVolume `json:"persistent,omitempty"`
+	External      *ExternalVolume   `json:"external,omitempty"`
+	Secret        string            `json:"secret,omitempty"`
 }
 
-// PortMapping Docker's PortMapping representation
+// PersistentVolume is a local persistent volume, reserved on the agent running the task
+type PersistentVolume struct {
+	Type        string     `json:"type,omitempty"`
+	Size        int        `json:"size"`
+	MaxSize     int        `json:"maxSize,omitempty"`
+	ProfileName string     `json:"profileName,omitempty"`
+	Constraints [][]string `json:"constraints,omitempty"`
+}
+
+// ExternalVolume is a volume provided by an external storage provider (e.g. dvdi)
+type ExternalVolume struct {
+	Name     string            `json:"name"`
+	Provider string            `json:"provider"`
+	Options  map[string]string `json:"options,omitempty"`
+	Size     int               `json:"size,omitempty"`
+}
+
+// PortMapping Container's PortMapping representation
 type PortMapping struct {
+	Name          string            `json:"name,omitempty"`
 	ContainerPort int               `json:"containerPort,omitempty"`
 	HostPort      int               `json:"hostPort,omitempty"`
 	Labels        map[string]string `json:"labels,omitempty"`
 	Protocol      string            `json:"protocol,omitempty"`
 	ServicePort   int               `json:"servicePort,omitempty"`
+	NetworkNames  []string          `json:"networkNames,omitempty"`
+
+	// Extra holds fields not modeled (or zero valued) in the original payload, re-emitted on marshal
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // DockerParameters is Docker exec Parameters representation
@@ -44,3 +111,75 @@ type Queue struct {
 	Type   string `json:"type"`
 	Docker Docker `json:"docker"`
 }
+
+// UnmarshalJSON decodes a Container keeping fields not modeled into Extra
+func (c *Container) UnmarshalJSON(content []byte) error {
+
+	type container Container
+	if err := json.Unmarshal(content, (*container)(c)); err != nil {
+		return err
+	}
+
+	extra, err := UnknownFields(content, c)
+	c.Extra = extra
+	return err
+}
+
+// MarshalJSON encodes a Container including fields kept into Extra
+func (c Container) MarshalJSON() ([]byte, error) {
+
+	type container Container
+	encoded, err := json.Marshal(container(c))
+	if err != nil {
+		return nil, err
+	}
+	return MergeFields(encoded, c.Extra)
+}
+
+// UnmarshalJSON decodes a Docker keeping fields not modeled into Extra
+func (d *Docker) UnmarshalJSON(content []byte) error {
+
+	type docker Docker
+	if err := json.Unmarshal(content, (*docker)(d)); err != nil {
+		return err
+	}
+
+	extra, err := UnknownFields(content, d)
+	d.Extra = extra
+	return err
+}
+
+// MarshalJSON encodes a Docker including fields kept into Extra
+func (d Docker) MarshalJSON() ([]byte, error) {
+
+	type docker Docker
+	encoded, err := json.Marshal(docker(d))
+	if err != nil {
+		return nil, err
+	}
+	return MergeFields(encoded, d.Extra)
+}
+
+// UnmarshalJSON decodes a PortMapping keeping fields not modeled into Extra
+func (pm *PortMapping) UnmarshalJSON(content []byte) error {
+
+	type portMapping PortMapping
+	if err := json.Unmarshal(content, (*portMapping)(pm)); err != nil {
+		return err
+	}
+
+	extra, err := UnknownFields(content, pm)
+	pm.Extra = extra
+	return err
+}
+
+// MarshalJSON encodes a PortMapping including fields kept into Extra
+func (pm PortMapping) MarshalJSON() ([]byte, error) {
+
+	type portMapping PortMapping
+	encoded, err := json.Marshal(portMapping(pm))
+	if err != nil {
+		return nil, err
+	}
+	return MergeFields(encoded, pm.Extra)
+}
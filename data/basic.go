@@ -4,7 +4,20 @@ import "time"
 
 // FailureMessage all failed request match with this datatype
 type FailureMessage struct {
-	Message string `json:"message,omitempty"`
+	Message     string              `json:"message,omitempty"`
+	Details     []FailureDetail     `json:"details,omitempty"`
+	Deployments []FailureDeployment `json:"deployments,omitempty"`
+}
+
+// FailureDetail holds the errors of a field rejected by Marathon validation
+type FailureDetail struct {
+	Path   string   `json:"path"`
+	Errors []string `json:"errors"`
+}
+
+// FailureDeployment holds a deployment that locks a resource
+type FailureDeployment struct {
+	ID string `json:"id"`
 }
 
 // Response is default Marathon API response when launch changes via deployments
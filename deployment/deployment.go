@@ -1,20 +1,28 @@
 package deployment
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dotWicho/marathon"
 	"github.com/dotWicho/marathon/data"
+	"net/http"
 	"time"
 )
 
 //===
 
+// ErrTimeout is returned when a deployment still exists after the timeout
+var ErrTimeout = errors.New("exit by timeout... deployment still existing")
+
 // Marathon Deployments interface
 type deployments interface {
 	Get() (*Deployments, error)
+	GetContext(ctx context.Context) (*Deployments, error)
 	Rollback(id string) error
+	RollbackContext(ctx context.Context, id string) error
 	Await(id string, timeout time.Duration) error
+	AwaitContext(ctx context.Context, id string, timeout time.Duration) error
 }
 
 // Deployments is Marathon Deployments implementation
@@ -92,8 +100,17 @@ func New(client *marathon.Client) *Deployments {
 // Get allows to establish the internal structures
 func (md *Deployments) Get() (*Deployments, error) {
 
-	if _, err := md.client.Session.BodyAsJSON(nil).Get(marathon.APIDeployments, &md.deployments, md.fail); err != nil {
-		return md, errors.New("unable to get deployments")
+	return md.GetContext(context.Background())
+}
+
+// GetContext allows to establish the internal structures, honoring ctx
+func (md *Deployments) GetContext(ctx context.Context) (*Deployments, error) {
+
+	if err := md.client.Do(ctx, http.MethodGet, marathon.APIDeployments, nil, nil, &md.deployments, md.fail); err != nil {
+		if ctx.Err() != nil {
+			return md, ctx.Err()
+		}
+		return md, fmt.Errorf("unable to get deployments: %w", err)
 	}
 	return md, nil
 }
@@ -101,11 +118,17 @@ func (md *Deployments) Get() (*Deployments, error) {
 // Rollback cancel a Marathon deployment
 func (md *Deployments) Rollback(id string) error {
 
+	return md.RollbackContext(context.Background(), id)
+}
+
+// RollbackContext cancel a Marathon deployment, honoring ctx
+func (md *Deployments) RollbackContext(ctx context.Context, id string) error {
+
 	if len(id) > 0 {
 
 		path := fmt.Sprintf("%s%s", marathon.APIDeployments, id)
 
-		if _, err := md.client.Session.BodyAsJSON(nil).Delete(path, md.deploy, md.fail); err != nil {
+		if err := md.client.Do(ctx, http.MethodDelete, path, nil, nil, md.deploy, md.fail); err != nil {
 			return err
 		}
 		return nil
@@ -116,8 +139,17 @@ func (md *Deployments) Rollback(id string) error {
 // Await wait a Marathon deployment finish or timeout
 func (md *Deployments) Await(id string, timeout time.Duration) error {
 
+	return md.AwaitContext(context.Background(), id, timeout)
+}
+
+// AwaitContext wait a Marathon deployment finish or timeout, returns ctx error if ctx is done first.
+// The deployment is finished once it is missing from the deployments (or they are not found),
+// other failures reading them are retried and the last one is returned at timeout
+func (md *Deployments) AwaitContext(ctx context.Context, id string, timeout time.Duration) error {
+
 	// define break condition
 	var found bool
+	var failure error
 
 	// Start time is Now
 	start := time.Now()
@@ -132,24 +164,39 @@ func (md *Deployments) Await(id string, timeout time.Duration) error {
 		// Deployment not found by default
 		found = false
 
-		if _, err := md.Get(); err != nil {
-			break
-		}
-
-		for _, deploy := range md.deployments {
-			if id == deploy.ID {
-				found = true
+		_, failure = md.GetContext(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case marathon.IsNotFound(failure):
+			failure = nil
+		case failure != nil:
+			// A transient failure does not tell whether the deployment finished
+			found = true
+		default:
+			for _, deploy := range md.deployments {
+				if id == deploy.ID {
+					found = true
+				}
 			}
 		}
 
 		if !found || time.Now().After(finish) {
 			break
 		}
-		time.Sleep(1 * time.Second)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
 	}
 
+	if failure != nil {
+		return failure
+	}
 	if found {
-		return fmt.Errorf("exit by timeout... deployment still existing")
+		return ErrTimeout
 	}
 	return nil
 }
@@ -1,9 +1,13 @@
 package deployment
 
 import (
+	"context"
 	"github.com/dotWicho/marathon"
 	"github.com/dotWicho/marathon/mockserver"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -177,3 +181,85 @@ func TestDeployments_Await(t *testing.T) {
 
 	})
 }
+
+func TestDeployments_AwaitContext(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	//
+	var timeout time.Duration = 5 * time.Second
+	mockserver.ResetDeployments(mockserver.SomeDeployments)
+
+	t.Run("returns ctx error when ctx is cancelled before Deploy finish", func(t *testing.T) {
+
+		// Try to create Deployment
+		_deploy := New(marathon.New(server.URL))
+
+		// deploy Id to check
+		id := "97c136bf-5a28-4821-9d94-480d9fbb01c8"
+
+		// We define a context shorter than timeout
+		ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+		defer cancel()
+
+		// Fire up AwaitContext of deploy
+		start := time.Now()
+		err := _deploy.AwaitContext(ctx, id, timeout)
+
+		// We get context error before timeout was reached
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.True(t, time.Since(start) < timeout)
+	})
+}
+
+func TestDeployments_AwaitFailures(t *testing.T) {
+
+	// We create a server answering status codes, one by request and the last one forever
+	answering := func(codes ...int) *httptest.Server {
+		var requests int32
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			index := int(atomic.AddInt32(&requests, 1)) - 1
+			if index >= len(codes) {
+				index = len(codes) - 1
+			}
+			w.WriteHeader(codes[index])
+			if codes[index] == http.StatusOK {
+				_, _ = w.Write([]byte(`[]`))
+			}
+		}))
+	}
+
+	// deploy Id to check
+	id := "97c136bf-5a28-4821-9d94-480d9fbb01c8"
+
+	t.Run("returns err when deployments cannot be read until timeout", func(t *testing.T) {
+
+		server := answering(http.StatusInternalServerError)
+		defer server.Close()
+
+		err := New(marathon.New(server.URL)).Await(id, 1500*time.Millisecond)
+
+		// The deployment is not taken as finished
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "unable to get deployments")
+		assert.NotEqual(t, ErrTimeout, err)
+	})
+
+	t.Run("retries transient failures", func(t *testing.T) {
+
+		server := answering(http.StatusInternalServerError, http.StatusOK)
+		defer server.Close()
+
+		assert.Nil(t, New(marathon.New(server.URL)).Await(id, 5*time.Second))
+	})
+
+	t.Run("returns nil when deployments are not found", func(t *testing.T) {
+
+		server := answering(http.StatusNotFound)
+		defer server.Close()
+
+		assert.Nil(t, New(marathon.New(server.URL)).Await(id, 5*time.Second))
+	})
+}
@@ -0,0 +1,538 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/groups"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind of a Change
+type Kind string
+
+const (
+	// Added field or element, only To is set
+	Added Kind = "added"
+	// Removed field or element, only From is set
+	Removed Kind = "removed"
+	// Changed field, both From and To are set
+	Changed Kind = "changed"
+)
+
+// ANSI colors used by Unified
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorCyan   = "\033[36m"
+	colorReset  = "\033[0m"
+	colorHeader = "\033[1m"
+)
+
+// groupStatusFields are Group fields changed by Marathon on every deployment, never compared
+var groupStatusFields = []string{"version", "versionInfo"}
+
+// statusFields are App and Group fields managed by Marathon, Definitions never compares them
+var statusFields = map[string]bool{
+	"version":               true,
+	"versionInfo":           true,
+	"tasksStaged":           true,
+	"tasksRunning":          true,
+	"tasksHealthy":          true,
+	"tasksUnhealthy":        true,
+	"deployments":           true,
+	"tasks":                 true,
+	"lastTaskFailure":       true,
+	"taskStats":             true,
+	"readinessCheckResults": true,
+}
+
+// defaultedFields are App and Group fields Marathon fills with defaults if a definition leaves
+// them out, written as paths below the App or Group with array elements as []
+var defaultedFields = map[string]bool{
+	"acceptedResourceRoles":                       true,
+	"backoffFactor":                               true,
+	"backoffSeconds":                              true,
+	"container.docker.forcePullImage":             true,
+	"container.docker.portMappings[].protocol":    true,
+	"container.docker.portMappings[].servicePort": true,
+	"container.docker.privileged":                 true,
+	"container.portMappings[].protocol":           true,
+	"container.portMappings[].servicePort":        true,
+	"cpus":                                        true,
+	"disk":                                        true,
+	"enforceRole":                                 true,
+	"executor":                                    true,
+	"fetch[].cache":                               true,
+	"fetch[].executable":                          true,
+	"fetch[].extract":                             true,
+	"gpus":                                        true,
+	"healthChecks[].delaySeconds":                 true,
+	"healthChecks[].gracePeriodSeconds":           true,
+	"healthChecks[].ignoreHttp1xx":                true,
+	"healthChecks[].intervalSeconds":              true,
+	"healthChecks[].ipProtocol":                   true,
+	"healthChecks[].maxConsecutiveFailures":       true,
+	"healthChecks[].path":                         true,
+	"healthChecks[].portIndex":                    true,
+	"healthChecks[].protocol":                     true,
+	"healthChecks[].timeoutSeconds":               true,
+	"killSelection":                               true,
+	"maxLaunchDelaySeconds":                       true,
+	"networks":                                    true,
+	"portDefinitions":                             true,
+	"portDefinitions[].port":                      true,
+	"portDefinitions[].protocol":                  true,
+	"ports":                                       true,
+	"requirePorts":                                true,
+	"role":                                        true,
+	"storeUrls":                                   true,
+	"taskKillGracePeriodSeconds":                  true,
+	"tty":                                         true,
+	"unreachableStrategy":                         true,
+	"upgradeStrategy":                             true,
+	"uris":                                        true,
+}
+
+// Change of a single field between two definitions
+//
+// Path is the location of the field: object keys are joined with dots (env.LOG_LEVEL) and
+// array elements are referenced by id or name if they have one (apps[/infra/redis].cpus),
+// by value if they are plain values (constraints[["hostname","UNIQUE"]]) or by index
+type Change struct {
+	Path string      `json:"path"`
+	Kind Kind        `json:"kind"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// Changes is the list of changes between two definitions, sorted by Path
+type Changes []Change
+
+// Apps returns the changes needed to turn app from into app to
+func Apps(from, to application.AppDefinition) (Changes, error) {
+
+	return JSON(from, to)
+}
+
+// Groups returns the changes needed to turn group from into group to, apps and groups
+// inside are matched by id; versions are not compared
+func Groups(from, to groups.Group) (Changes, error) {
+
+	return JSON(from, to, groupStatusFields...)
+}
+
+// AppVersions returns the changes of app id from one of its versions to another one
+func AppVersions(ctx context.Context, client *marathon.Client, id, from, to string) (Changes, error) {
+
+	older := application.New(client).GetContext(ctx, id).ConfigContext(ctx, from)
+	if err := older.LastError(); err != nil {
+		return nil, err
+	}
+	newer := application.New(client).GetContext(ctx, id).ConfigContext(ctx, to)
+	if err := newer.LastError(); err != nil {
+		return nil, err
+	}
+	return Apps(older.AsRaw(), newer.AsRaw())
+}
+
+// AppFile returns the changes needed to turn the app running on Marathon server into the
+// app defined in fileName
+func AppFile(ctx context.Context, client *marathon.Client, fileName string) (Changes, error) {
+
+	local := application.New(client).Load(fileName)
+	if err := local.LastError(); err != nil {
+		return nil, err
+	}
+	live := application.New(client).GetContext(ctx, local.AsRaw().ID)
+	if err := live.LastError(); err != nil {
+		return nil, err
+	}
+	return Apps(live.AsRaw(), local.AsRaw())
+}
+
+// JSON returns the changes between the JSON encodings of from and to, top level fields
+// named in ignore are not compared
+func JSON(from, to interface{}, ignore ...string) (Changes, error) {
+
+	older, err := decode(from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := decode(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return changesOf(older, newer, ignore), nil
+}
+
+// Definitions returns the changes needed to turn the live state into the definition desired,
+// top level fields named in ignore are not compared. Fields managed by Marathon (version, tasks
+// counters, deployments...) are never compared, and fields Marathon fills with defaults are only
+// compared if desired sets them; a definition encodes some of them even if they are left out, so
+// zero values count as not set. Any other field missing in desired is a change, as are fields
+// missing on one side and empty on the other one
+func Definitions(live, desired interface{}, ignore ...string) (Changes, error) {
+
+	older, err := decode(live)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := decode(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	undefined(older, newer, "")
+	return changesOf(older, newer, ignore), nil
+}
+
+// unset returns true if value is a JSON zero value (null, false, 0, "" or []) or an object whose
+// fields are all unset
+func unset(value interface{}) bool {
+
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !typed
+	case float64:
+		return typed == 0
+	case string:
+		return len(typed) == 0
+	case []interface{}:
+		return len(typed) == 0
+	case map[string]interface{}:
+		for _, field := range typed {
+			if !unset(field) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// changesOf returns the changes between the generic JSON representations from and to
+func changesOf(from, to interface{}, ignore []string) Changes {
+
+	for _, name := range ignore {
+		if object, ok := from.(map[string]interface{}); ok {
+			delete(object, name)
+		}
+		if object, ok := to.(map[string]interface{}); ok {
+			delete(object, name)
+		}
+	}
+
+	changes := Changes{}
+	compare("", from, to, &changes)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// undefined removes from both sides the fields not compared by Definitions, path is the location
+// of live and desired below their App or Group, elements of apps and groups are Apps and Groups
+func undefined(live, desired interface{}, path string) {
+
+	switch older := live.(type) {
+	case map[string]interface{}:
+		newer, ok := desired.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for field, value := range older {
+			name := join(path, field)
+			wanted := newer[field]
+			if (len(path) == 0 && statusFields[field]) || (unset(wanted) && (defaultedFields[name] || unset(value))) {
+				delete(older, field)
+				delete(newer, field)
+				continue
+			}
+			undefined(value, wanted, name)
+		}
+		for field, wanted := range newer {
+			if _, found := older[field]; !found && ((len(path) == 0 && statusFields[field]) || unset(wanted)) {
+				delete(newer, field)
+			}
+		}
+	case []interface{}:
+		newer, ok := desired.([]interface{})
+		if !ok {
+			return
+		}
+		element := path + "[]"
+		if path == "apps" || path == "groups" {
+			element = ""
+		}
+		if key := keyOf(older, newer); len(key) > 0 {
+			wanted := index(newer, key)
+			for id, value := range index(older, key) {
+				undefined(value, wanted[id], element)
+			}
+			return
+		}
+		for position := 0; position < len(older) && position < len(newer); position++ {
+			undefined(older[position], newer[position], element)
+		}
+	}
+}
+
+// Empty returns true if there are no changes
+func (c Changes) Empty() bool {
+
+	return len(c) == 0
+}
+
+// Paths returns the path of every change
+func (c Changes) Paths() []string {
+
+	paths := make([]string, 0, len(c))
+	for _, change := range c {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// Find returns the change of path, nil if path did not change
+func (c Changes) Find(path string) *Change {
+
+	for index := range c {
+		if c[index].Path == path {
+			return &c[index]
+		}
+	}
+	return nil
+}
+
+// String returns the changes as an uncolored unified text
+func (c Changes) String() string {
+
+	return c.Unified("from", "to", false)
+}
+
+// Unified renders changes as a unified text, from and to label both sides, if color is true
+// removed values are red, added values are green and paths are cyan
+func (c Changes) Unified(from, to string, color bool) string {
+
+	paint := func(code, text string) string {
+		if color {
+			return code + text + colorReset
+		}
+		return text
+	}
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(paint(colorHeader, "--- "+from) + "\n")
+	buffer.WriteString(paint(colorHeader, "+++ "+to) + "\n")
+
+	for _, change := range c {
+		buffer.WriteString(paint(colorCyan, "@@ "+change.Path+" @@") + "\n")
+		if change.Kind != Added {
+			buffer.WriteString(paint(colorRed, "- "+render(change.From)) + "\n")
+		}
+		if change.Kind != Removed {
+			buffer.WriteString(paint(colorGreen, "+ "+render(change.To)) + "\n")
+		}
+	}
+	return buffer.String()
+}
+
+// decode returns the generic JSON representation of value
+func decode(value interface{}) (interface{}, error) {
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	err = json.Unmarshal(encoded, &decoded)
+	return decoded, err
+}
+
+// compare appends to changes every difference between from and to found below path
+func compare(path string, from, to interface{}, changes *Changes) {
+
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+
+	switch older := from.(type) {
+	case map[string]interface{}:
+		if newer, ok := to.(map[string]interface{}); ok {
+			compareObjects(path, older, newer, changes)
+			return
+		}
+	case []interface{}:
+		if newer, ok := to.([]interface{}); ok {
+			compareArrays(path, older, newer, changes)
+			return
+		}
+	}
+
+	switch {
+	case from == nil:
+		*changes = append(*changes, Change{Path: path, Kind: Added, To: to})
+	case to == nil:
+		*changes = append(*changes, Change{Path: path, Kind: Removed, From: from})
+	default:
+		*changes = append(*changes, Change{Path: path, Kind: Changed, From: from, To: to})
+	}
+}
+
+// compareObjects compares two JSON objects field by field
+func compareObjects(path string, from, to map[string]interface{}, changes *Changes) {
+
+	names := make(map[string]bool)
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+
+	for name := range names {
+		compare(join(path, name), from[name], to[name], changes)
+	}
+}
+
+// compareArrays compares two JSON arrays, elements are matched by key if they have one
+func compareArrays(path string, from, to []interface{}, changes *Changes) {
+
+	if key := keyOf(from, to); len(key) > 0 {
+		older := index(from, key)
+		newer := index(to, key)
+		for id, element := range older {
+			compare(fmt.Sprintf("%s[%s]", path, id), element, newer[id], changes)
+		}
+		for id, element := range newer {
+			if _, ok := older[id]; !ok {
+				compare(fmt.Sprintf("%s[%s]", path, id), nil, element, changes)
+			}
+		}
+		return
+	}
+
+	if plain(from) && plain(to) {
+		removed := subtract(from, to)
+		added := subtract(to, from)
+		if len(removed)+len(added) == 0 {
+			// Same values in another order
+			*changes = append(*changes, Change{Path: path, Kind: Changed, From: from, To: to})
+			return
+		}
+		for _, element := range removed {
+			*changes = append(*changes, Change{Path: fmt.Sprintf("%s[%s]", path, render(element)), Kind: Removed, From: element})
+		}
+		for _, element := range added {
+			*changes = append(*changes, Change{Path: fmt.Sprintf("%s[%s]", path, render(element)), Kind: Added, To: element})
+		}
+		return
+	}
+
+	for position := 0; position < len(from) || position < len(to); position++ {
+		var older, newer interface{}
+		if position < len(from) {
+			older = from[position]
+		}
+		if position < len(to) {
+			newer = to[position]
+		}
+		compare(fmt.Sprintf("%s[%d]", path, position), older, newer, changes)
+	}
+}
+
+// keyOf returns the field ("id" or "name") identifying every element of both arrays, if any
+func keyOf(from, to []interface{}) string {
+
+	for _, key := range []string{"id", "name"} {
+		// Keys must be set and unique on each side to match elements
+		if len(from)+len(to) > 0 && len(index(from, key)) == len(from) && len(index(to, key)) == len(to) {
+			return key
+		}
+	}
+	return ""
+}
+
+// index returns the elements of an array of objects by the value of their key field
+func index(elements []interface{}, key string) map[string]interface{} {
+
+	indexed := make(map[string]interface{})
+	for _, element := range elements {
+		if object, ok := element.(map[string]interface{}); ok {
+			if id, ok := object[key].(string); ok && len(id) > 0 {
+				indexed[id] = element
+			}
+		}
+	}
+	return indexed
+}
+
+// plain returns true if elements holds no objects, only values or arrays of values
+func plain(elements []interface{}) bool {
+
+	for _, element := range elements {
+		switch value := element.(type) {
+		case map[string]interface{}:
+			return false
+		case []interface{}:
+			if !plain(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// subtract returns the elements of from missing in to, repeated values count once each
+func subtract(from, to []interface{}) []interface{} {
+
+	left := append([]interface{}{}, to...)
+
+	var missing []interface{}
+	for _, element := range from {
+		found := -1
+		for position, candidate := range left {
+			if reflect.DeepEqual(element, candidate) {
+				found = position
+				break
+			}
+		}
+		if found < 0 {
+			missing = append(missing, element)
+			continue
+		}
+		left = append(left[:found], left[found+1:]...)
+	}
+	return missing
+}
+
+// join appends name to path
+func join(path, name string) string {
+
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
+
+// render returns the compact JSON representation of value
+func render(value interface{}) string {
+
+	if text, ok := value.(string); ok {
+		return strconv.Quote(text)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(string(encoded))
+}
@@ -0,0 +1,266 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/groups"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestApps(t *testing.T) {
+
+	// We define some vars
+	from := application.App{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.AppRedisPrevious), &from))
+	to := application.App{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.AppRedis), &to))
+
+	t.Run("no changes between equal apps", func(t *testing.T) {
+
+		changes, err := Apps(to.App, to.App)
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.True(t, changes.Empty())
+	})
+
+	t.Run("field level changes between two apps", func(t *testing.T) {
+
+		changes, err := Apps(from.App, to.App)
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"container.docker.image",
+			"cpus",
+			"env.REDISMODE",
+			"env.REDISPRTY",
+			"healthChecks[0].gracePeriodSeconds",
+			"labels.ENVIRONMENT",
+		}, changes.Paths())
+
+		assert.Equal(t, Change{Path: "container.docker.image", Kind: Changed, From: "docker.io/redis-ha:5.0.4", To: "docker.io/redis-ha:5.0.5"}, *changes.Find("container.docker.image"))
+		assert.Equal(t, Change{Path: "env.REDISMODE", Kind: Removed, From: "standalone"}, *changes.Find("env.REDISMODE"))
+		assert.Equal(t, Change{Path: "env.REDISPRTY", Kind: Added, To: "2"}, *changes.Find("env.REDISPRTY"))
+		assert.Nil(t, changes.Find("mem"))
+	})
+
+	t.Run("constraints are compared as values", func(t *testing.T) {
+
+		older := to.App
+		older.Constraints = []application.TaskConstraints{{"hostname", "UNIQUE"}, {"rack", "GROUP_BY"}}
+		newer := to.App
+		newer.Constraints = []application.TaskConstraints{{"rack", "GROUP_BY"}, {"zone", "LIKE", "a"}}
+
+		changes, err := Apps(older, newer)
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, Changes{
+			{Path: `constraints[["hostname","UNIQUE"]]`, Kind: Removed, From: []interface{}{"hostname", "UNIQUE"}},
+			{Path: `constraints[["zone","LIKE","a"]]`, Kind: Added, To: []interface{}{"zone", "LIKE", "a"}},
+		}, changes)
+	})
+}
+
+func TestDefinitions(t *testing.T) {
+
+	// We define some vars
+	live := application.AppDefinition{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.HostApp), &live))
+
+	// The definition of the live app, without the fields Marathon fills
+	desired := application.AppDefinition{
+		ID:              live.ID,
+		Cmd:             live.Cmd,
+		Cpus:            live.Cpus,
+		Mem:             live.Mem,
+		Instances:       live.Instances,
+		Labels:          map[string]string{"HAPROXY_0_VHOST": "web.example.com", "HAPROXY_GROUP": "external"},
+		Networks:        live.Networks,
+		PortDefinitions: []application.PortDefinition{{Port: 10105, Name: "http", Protocol: "tcp"}},
+	}
+
+	t.Run("fields left to Marathon defaults are not changes", func(t *testing.T) {
+
+		changes, err := Definitions(live, desired)
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.True(t, changes.Empty(), changes.String())
+
+		// Compared as they are every default is a change
+		changes, _ = Apps(live, desired)
+		assert.Contains(t, changes.Paths(), "backoffFactor")
+		assert.Contains(t, changes.Paths(), "executor")
+	})
+
+	t.Run("fields set in the definition are compared", func(t *testing.T) {
+
+		changed := desired
+		changed.Instances = 3
+		changed.Labels = map[string]string{"HAPROXY_0_VHOST": "web.example.com"}
+		changed.PortDefinitions = nil
+		changed.Networks = append(changed.Networks, application.Network{Mode: "container", Name: "dcos"})
+
+		changes, err := Definitions(live, changed)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Changes{
+			{Path: "instances", Kind: Changed, From: 2.0, To: 3.0},
+			{Path: "labels.HAPROXY_GROUP", Kind: Removed, From: "external"},
+			{Path: "networks[1]", Kind: Added, To: map[string]interface{}{"mode": "container", "name": "dcos"}},
+		}, changes)
+	})
+
+	t.Run("fields removed from the definition are changes", func(t *testing.T) {
+
+		changed := desired
+		changed.Labels = nil
+
+		changes, err := Definitions(live, changed)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Changes{
+			{Path: "labels", Kind: Removed, From: map[string]interface{}{"HAPROXY_0_VHOST": "web.example.com", "HAPROXY_GROUP": "external"}},
+		}, changes)
+	})
+
+	t.Run("drift only on the live side is a change", func(t *testing.T) {
+
+		drifted := live
+		drifted.Constraints = []application.TaskConstraints{{"hostname", "UNIQUE"}}
+		drifted.Env = map[string]string{"DEBUG": "true"}
+
+		changes, err := Definitions(drifted, desired)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"constraints", "env"}, changes.Paths())
+	})
+
+	t.Run("live state is not modified", func(t *testing.T) {
+
+		_, _ = Definitions(live, desired)
+		assert.Equal(t, 1.15, live.BackoffFactor)
+	})
+}
+
+func TestGroups(t *testing.T) {
+
+	// We define some vars
+	from := groups.Group{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), &from))
+	to := groups.Group{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), &to))
+
+	// Change an app and remove another one
+	to.Version = to.Version.Add(1)
+	to.Apps[0].Cpus = to.Apps[0].Cpus + 1
+	removed := to.Apps[len(to.Apps)-1].ID
+	to.Apps = to.Apps[:len(to.Apps)-1]
+
+	changes, err := Groups(from, to)
+
+	// We get not error, the version is not compared and apps are matched by id
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"apps[" + to.Apps[0].ID + "].cpus", "apps[" + removed + "]"}, changes.Paths())
+	assert.Equal(t, Removed, changes.Find("apps["+removed+"]").Kind)
+}
+
+func TestAppVersions(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	client := marathon.New(server.URL)
+
+	t.Run("compare two versions of an app", func(t *testing.T) {
+
+		changes, err := AppVersions(context.Background(), client, "/infra/redis-1", "2021-01-16T18:27:41.662Z", "2021-01-21T20:27:42.725Z")
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Len(t, changes, 6)
+	})
+
+	t.Run("error if app does not exist", func(t *testing.T) {
+
+		_, err := AppVersions(context.Background(), client, "/infra/missing", "2021-01-16T18:27:41.662Z", "2021-01-21T20:27:42.725Z")
+
+		assert.True(t, marathon.IsNotFound(err))
+	})
+}
+
+func TestAppFile(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	client := marathon.New(server.URL)
+	fileName := "dumpfile-diff.json"
+	defer os.Remove(fileName)
+
+	// Dump a live app and change it
+	_app := application.New(client).Get("/infra/redis-1")
+	assert.Nil(t, _app.LastError())
+	_app.Env()["REDISPRTY"] = "3"
+	assert.Nil(t, _app.Dump(fileName))
+
+	changes, err := AppFile(context.Background(), client, fileName)
+
+	// We get not error
+	assert.Nil(t, err)
+	assert.Equal(t, Changes{{Path: "env.REDISPRTY", Kind: Changed, From: "2", To: "3"}}, changes)
+}
+
+func TestChanges_Unified(t *testing.T) {
+
+	// We define some vars
+	changes := Changes{
+		{Path: "cpus", Kind: Changed, From: 0.5, To: 1.0},
+		{Path: "env.REDISMODE", Kind: Removed, From: "standalone"},
+		{Path: "env.REDISPRTY", Kind: Added, To: "2"},
+	}
+
+	t.Run("uncolored text", func(t *testing.T) {
+
+		assert.Equal(t, strings.Join([]string{
+			"--- 2021-01-16T18:27:41.662Z",
+			"+++ live",
+			"@@ cpus @@",
+			"- 0.5",
+			"+ 1",
+			"@@ env.REDISMODE @@",
+			`- "standalone"`,
+			"@@ env.REDISPRTY @@",
+			`+ "2"`,
+			"",
+		}, "\n"), changes.Unified("2021-01-16T18:27:41.662Z", "live", false))
+	})
+
+	t.Run("colored text", func(t *testing.T) {
+
+		text := changes.Unified("from", "to", true)
+
+		assert.Contains(t, text, colorRed+`- "standalone"`+colorReset)
+		assert.Contains(t, text, colorGreen+`+ "2"`+colorReset)
+	})
+
+	t.Run("machine readable output", func(t *testing.T) {
+
+		encoded, err := json.Marshal(changes[:1])
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `[{"path":"cpus","kind":"changed","from":0.5,"to":1}]`, string(encoded))
+	})
+}
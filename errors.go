@@ -0,0 +1,129 @@
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon/data"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error is returned when Marathon answers a request with a failure status code
+type Error struct {
+	StatusCode  int
+	Message     string
+	Details     []data.FailureDetail
+	Deployments []string
+}
+
+// NewError returns an Error given the status code and the failure sent by Marathon
+func NewError(statusCode int, failure *data.FailureMessage) *Error {
+
+	apiError := &Error{StatusCode: statusCode}
+	if failure != nil {
+		apiError.Message = failure.Message
+		apiError.Details = failure.Details
+		for _, deployment := range failure.Deployments {
+			apiError.Deployments = append(apiError.Deployments, deployment.ID)
+		}
+	}
+	if len(apiError.Message) == 0 {
+		apiError.Message = http.StatusText(statusCode)
+	}
+	return apiError
+}
+
+// Error returns a readable representation of Marathon failure
+func (e *Error) Error() string {
+
+	message := fmt.Sprintf("marathon: %d %s", e.StatusCode, e.Message)
+	if len(e.Details) > 0 {
+		details := make([]string, 0, len(e.Details))
+		for _, detail := range e.Details {
+			details = append(details, fmt.Sprintf("%s: %s", detail.Path, strings.Join(detail.Errors, ", ")))
+		}
+		message = fmt.Sprintf("%s (%s)", message, strings.Join(details, "; "))
+	}
+	if len(e.Deployments) > 0 {
+		message = fmt.Sprintf("%s [deployments: %s]", message, strings.Join(e.Deployments, ", "))
+	}
+	return message
+}
+
+// ErrModified is the cause of every ModifiedError
+var ErrModified = errors.New("definition changed since it was read")
+
+// ModifiedError is returned when a definition changed on Marathon server since it was read,
+// Fields holds the fields changed both locally and on the server, if known
+type ModifiedError struct {
+	ID      string
+	Read    time.Time
+	Current time.Time
+	Fields  []string
+}
+
+// Error returns a readable representation of the change
+func (e *ModifiedError) Error() string {
+
+	message := fmt.Sprintf("marathon: %s changed since it was read (version %s, now %s)", e.ID,
+		e.Read.Format(time.RFC3339Nano), e.Current.Format(time.RFC3339Nano))
+	if len(e.Fields) > 0 {
+		message = fmt.Sprintf("%s [fields: %s]", message, strings.Join(e.Fields, ", "))
+	}
+	return message
+}
+
+// Unwrap returns ErrModified, so errors.Is(err, ErrModified) holds for every ModifiedError
+func (e *ModifiedError) Unwrap() error {
+
+	return ErrModified
+}
+
+// IsModified returns true if err is a ModifiedError, the definition changed since it was read
+func IsModified(err error) bool {
+
+	return errors.Is(err, ErrModified)
+}
+
+// IsNotFound returns true if err is a Marathon 404 failure
+func IsNotFound(err error) bool {
+
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsConflict returns true if err is a Marathon 409 failure, the resource is locked by a deployment
+func IsConflict(err error) bool {
+
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+// IsValidation returns true if err is a Marathon 422 failure or ValidationErrors found
+// before sending it, the definition is invalid
+func IsValidation(err error) bool {
+
+	var invalid ValidationErrors
+	return hasStatusCode(err, http.StatusUnprocessableEntity) || errors.As(err, &invalid)
+}
+
+// IsUnauthorized returns true if err is a Marathon 401 or 403 failure
+func IsUnauthorized(err error) bool {
+
+	return hasStatusCode(err, http.StatusUnauthorized) || hasStatusCode(err, http.StatusForbidden)
+}
+
+// IsUnavailable returns true if err is a Marathon 503 failure, there is no leader
+func IsUnavailable(err error) bool {
+
+	return hasStatusCode(err, http.StatusServiceUnavailable)
+}
+
+// hasStatusCode returns true if err is an Error with statusCode
+func hasStatusCode(err error, statusCode int) bool {
+
+	var apiError *Error
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode == statusCode
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package marathon
+
+import (
+	"context"
+	"fmt"
+	"github.com/dotWicho/marathon/data"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_NewError(t *testing.T) {
+
+	t.Run("use status text when failure has no message", func(t *testing.T) {
+
+		// Try to create Error
+		err := NewError(http.StatusNotFound, nil)
+
+		// Check some values
+		assert.Equal(t, "Not Found", err.Message)
+		assert.Equal(t, "marathon: 404 Not Found", err.Error())
+	})
+
+	t.Run("keep details and deployments of failure", func(t *testing.T) {
+
+		// We define some vars
+		failure := &data.FailureMessage{
+			Message:     "Object is not valid",
+			Details:     []data.FailureDetail{{Path: "/cpus", Errors: []string{"error.min"}}},
+			Deployments: []data.FailureDeployment{{ID: "97c136bf"}},
+		}
+
+		// Try to create Error
+		err := NewError(http.StatusUnprocessableEntity, failure)
+
+		// Check some values
+		assert.Equal(t, []string{"97c136bf"}, err.Deployments)
+		assert.Equal(t, "marathon: 422 Object is not valid (/cpus: error.min) [deployments: 97c136bf]", err.Error())
+	})
+}
+
+func Test_IsErrors(t *testing.T) {
+
+	// We define some vars
+	notFound := NewError(http.StatusNotFound, nil)
+	wrapped := fmt.Errorf("wrapped: %w", NewError(http.StatusConflict, nil))
+
+	// Check our helpers
+	assert.True(t, IsNotFound(notFound))
+	assert.False(t, IsConflict(notFound))
+	assert.True(t, IsConflict(wrapped))
+	assert.True(t, IsValidation(NewError(http.StatusUnprocessableEntity, nil)))
+	assert.True(t, IsUnauthorized(NewError(http.StatusForbidden, nil)))
+	assert.True(t, IsUnavailable(NewError(http.StatusServiceUnavailable, nil)))
+	assert.False(t, IsNotFound(fmt.Errorf("not a Marathon error")))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestClient_DoErrors(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Try to create Client
+	_client := New(server.URL)
+
+	t.Run("get Error with deployments on 409", func(t *testing.T) {
+
+		// We define some vars
+		failure := &data.FailureMessage{}
+
+		// Fire up Do
+		err := _client.Do(context.Background(), http.MethodPut, APIApps+"infra/locked", nil, struct{}{}, nil, failure)
+
+		// We get a conflict
+		assert.True(t, IsConflict(err))
+		assert.Equal(t, []string{"97c136bf-5a28-4821-9d94-480d9fbb01c8"}, err.(*Error).Deployments)
+
+		// failure was decoded too
+		assert.NotEmpty(t, failure.Message)
+	})
+
+	t.Run("get Error with details on 422", func(t *testing.T) {
+
+		// Fire up Do
+		err := _client.Do(context.Background(), http.MethodPut, APIApps+"infra/invalid", nil, struct{}{}, nil, nil)
+
+		// We get a validation error
+		assert.True(t, IsValidation(err))
+		assert.Len(t, err.(*Error).Details, 2)
+		assert.Equal(t, "/cpus", err.(*Error).Details[0].Path)
+	})
+
+	t.Run("get Error on 404", func(t *testing.T) {
+
+		// Fire up Do
+		err := _client.Do(context.Background(), http.MethodGet, APIApps+"infra/missing", nil, nil, nil, nil)
+
+		// We get a not found error
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, http.StatusNotFound, _client.StatusCode())
+	})
+}
+
+func TestModifiedError(t *testing.T) {
+
+	// We define some vars
+	read := time.Date(2021, 1, 21, 20, 0, 1, 0, time.UTC)
+	err := fmt.Errorf("apply: %w", &ModifiedError{ID: "/infra/redis-1", Read: read, Current: read.Add(time.Second), Fields: []string{"env"}})
+
+	// Check some values
+	assert.True(t, IsModified(err))
+	assert.False(t, IsConflict(err))
+	assert.Equal(t, "apply: marathon: /infra/redis-1 changed since it was read (version 2021-01-21T20:00:01Z, now 2021-01-21T20:00:02Z) [fields: env]", err.Error())
+}
+
+func TestValidationErrors(t *testing.T) {
+
+	t.Run("empty errors are not an error", func(t *testing.T) {
+
+		var errs ValidationErrors
+		assert.Nil(t, errs.Err())
+	})
+
+	t.Run("every problem is reported", func(t *testing.T) {
+
+		var errs ValidationErrors
+		errs.Add("/cpus", "cpus must be %v or more", 0.001)
+		errs.Merge(ValidationErrors{{Path: "/id", Message: "id cannot be empty"}})
+
+		err := fmt.Errorf("apply: %w", errs.Err())
+
+		assert.True(t, IsValidation(err))
+		assert.Equal(t, []string{"/cpus", "/id"}, errs.Paths())
+		assert.Equal(t, "apply: invalid definition (/cpus: cpus must be 0.001 or more; /id: id cannot be empty)", err.Error())
+	})
+}
@@ -0,0 +1,409 @@
+package marathon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Marathon event types sent over /v2/events
+const (
+	EventAPIPost                   = "api_post_event"
+	EventStatusUpdate              = "status_update_event"
+	EventInstanceChanged           = "instance_changed_event"
+	EventInstanceHealthChanged     = "instance_health_changed_event"
+	EventHealthStatusChanged       = "health_status_changed_event"
+	EventFailedHealthCheck         = "failed_health_check_event"
+	EventUnhealthyTaskKill         = "unhealthy_task_kill_event"
+	EventUnhealthyInstanceKill     = "unhealthy_instance_kill_event"
+	EventAddHealthCheck            = "add_health_check_event"
+	EventRemoveHealthCheck         = "remove_health_check_event"
+	EventAppTerminated             = "app_terminated_event"
+	EventGroupChangeSuccess        = "group_change_success"
+	EventGroupChangeFailed         = "group_change_failed"
+	EventDeploymentInfo            = "deployment_info"
+	EventDeploymentSuccess         = "deployment_success"
+	EventDeploymentFailed          = "deployment_failed"
+	EventDeploymentStepSuccess     = "deployment_step_success"
+	EventDeploymentStepFailure     = "deployment_step_failure"
+	EventFrameworkMessage          = "framework_message_event"
+	EventSubscribe                 = "subscribe_event"
+	EventUnsubscribe               = "unsubscribe_event"
+	EventEventStreamAttached       = "event_stream_attached"
+	EventEventStreamDetached       = "event_stream_detached"
+	EventPodCreated                = "pod_created_event"
+	EventPodUpdated                = "pod_updated_event"
+	EventPodDeleted                = "pod_deleted_event"
+	EventUnknownInstanceTerminated = "unknown_instance_terminated_event"
+)
+
+// Event is a Marathon event received from /v2/events,
+// Data holds a pointer to the typed struct of the event (i.e. *StatusUpdateEvent)
+type Event struct {
+	Type string
+	Data interface{}
+	Raw  json.RawMessage
+}
+
+// EventHandler function type
+type EventHandler func(event *Event)
+
+// BaseEvent holds fields shared by every Marathon event
+type BaseEvent struct {
+	EventType string    `json:"eventType"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// APIPostEvent is sent when an app definition is changed via the API
+type APIPostEvent struct {
+	BaseEvent
+	ClientIP      string          `json:"clientIp"`
+	URI           string          `json:"uri"`
+	AppDefinition json.RawMessage `json:"appDefinition"`
+}
+
+// StatusUpdateEvent is sent when a task changes its status
+type StatusUpdateEvent struct {
+	BaseEvent
+	SlaveID     string      `json:"slaveId"`
+	TaskID      string      `json:"taskId"`
+	TaskStatus  string      `json:"taskStatus"`
+	Message     string      `json:"message"`
+	AppID       string      `json:"appId"`
+	Host        string      `json:"host"`
+	IPAddresses []IPAddress `json:"ipAddresses"`
+	Ports       []int       `json:"ports"`
+	Version     string      `json:"version"`
+}
+
+// InstanceChangedEvent is sent when an instance changes its condition
+type InstanceChangedEvent struct {
+	BaseEvent
+	InstanceID     string `json:"instanceId"`
+	Condition      string `json:"condition"`
+	RunSpecID      string `json:"runSpecId"`
+	AgentID        string `json:"agentId"`
+	Host           string `json:"host"`
+	RunSpecVersion string `json:"runSpecVersion"`
+}
+
+// HealthStatusChangedEvent is sent when a task changes its health status
+type HealthStatusChangedEvent struct {
+	BaseEvent
+	AppID      string `json:"appId"`
+	TaskID     string `json:"taskId"`
+	InstanceID string `json:"instanceId"`
+	Version    string `json:"version"`
+	Alive      bool   `json:"alive"`
+}
+
+// FailedHealthCheckEvent is sent when a health check of a task fails
+type FailedHealthCheckEvent struct {
+	BaseEvent
+	AppID       string      `json:"appId"`
+	TaskID      string      `json:"taskId"`
+	InstanceID  string      `json:"instanceId"`
+	HealthCheck Healthcheck `json:"healthCheck"`
+}
+
+// UnhealthyTaskKillEvent is sent when a task is killed because it is unhealthy
+type UnhealthyTaskKillEvent struct {
+	BaseEvent
+	AppID      string `json:"appId"`
+	TaskID     string `json:"taskId"`
+	InstanceID string `json:"instanceId"`
+	Version    string `json:"version"`
+	Reason     string `json:"reason"`
+	Host       string `json:"host"`
+	SlaveID    string `json:"slaveId"`
+}
+
+// HealthCheckEvent is sent when a health check is added or removed
+type HealthCheckEvent struct {
+	BaseEvent
+	AppID       string      `json:"appId"`
+	Version     string      `json:"version"`
+	HealthCheck Healthcheck `json:"healthCheck"`
+}
+
+// AppTerminatedEvent is sent when an app is destroyed
+type AppTerminatedEvent struct {
+	BaseEvent
+	AppID string `json:"appId"`
+}
+
+// GroupChangeEvent is sent when a group change succeed or fail
+type GroupChangeEvent struct {
+	BaseEvent
+	GroupID string `json:"groupId"`
+	Version string `json:"version"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// DeploymentEvent is sent when a deployment starts, finishes or fails
+type DeploymentEvent struct {
+	BaseEvent
+	ID          string          `json:"id"`
+	Plan        DeploymentPlan  `json:"plan"`
+	CurrentStep *DeploymentStep `json:"currentStep,omitempty"`
+}
+
+// DeploymentPlan holds the steps of a deployment sent on events
+type DeploymentPlan struct {
+	ID      string           `json:"id"`
+	Version string           `json:"version"`
+	Steps   []DeploymentStep `json:"steps"`
+}
+
+// DeploymentStep holds the actions of a deployment step
+type DeploymentStep struct {
+	Actions []DeploymentAction `json:"actions"`
+}
+
+// DeploymentAction is a single action of a deployment step
+type DeploymentAction struct {
+	Action string `json:"action"`
+	App    string `json:"app,omitempty"`
+	Pod    string `json:"pod,omitempty"`
+}
+
+// FrameworkMessageEvent is sent when an executor sends a message to Marathon
+type FrameworkMessageEvent struct {
+	BaseEvent
+	SlaveID    string `json:"slaveId"`
+	ExecutorID string `json:"executorId"`
+	Message    string `json:"message"`
+}
+
+// SubscriptionEvent is sent when an event subscriber is added or removed
+type SubscriptionEvent struct {
+	BaseEvent
+	ClientIP      string `json:"clientIp"`
+	CallbackURL   string `json:"callbackUrl"`
+	RemoteAddress string `json:"remoteAddress,omitempty"`
+}
+
+// PodEvent is sent when a pod is created, updated or deleted
+type PodEvent struct {
+	BaseEvent
+	ClientIP string `json:"clientIp"`
+	URI      string `json:"uri"`
+}
+
+// newEventData returns an empty typed struct for eventType
+func newEventData(eventType string) interface{} {
+
+	switch eventType {
+	case EventAPIPost:
+		return &APIPostEvent{}
+	case EventStatusUpdate:
+		return &StatusUpdateEvent{}
+	case EventInstanceChanged:
+		return &InstanceChangedEvent{}
+	case EventHealthStatusChanged, EventInstanceHealthChanged:
+		return &HealthStatusChangedEvent{}
+	case EventFailedHealthCheck:
+		return &FailedHealthCheckEvent{}
+	case EventUnhealthyTaskKill, EventUnhealthyInstanceKill:
+		return &UnhealthyTaskKillEvent{}
+	case EventAddHealthCheck, EventRemoveHealthCheck:
+		return &HealthCheckEvent{}
+	case EventAppTerminated:
+		return &AppTerminatedEvent{}
+	case EventGroupChangeSuccess, EventGroupChangeFailed:
+		return &GroupChangeEvent{}
+	case EventDeploymentInfo, EventDeploymentSuccess, EventDeploymentFailed, EventDeploymentStepSuccess, EventDeploymentStepFailure:
+		return &DeploymentEvent{}
+	case EventFrameworkMessage:
+		return &FrameworkMessageEvent{}
+	case EventSubscribe, EventUnsubscribe, EventEventStreamAttached, EventEventStreamDetached:
+		return &SubscriptionEvent{}
+	case EventPodCreated, EventPodUpdated, EventPodDeleted:
+		return &PodEvent{}
+	default:
+		return &BaseEvent{}
+	}
+}
+
+// decodeEvent decodes the data of a server-sent event into an Event
+func decodeEvent(eventType string, data []byte) (*Event, error) {
+
+	base := &BaseEvent{}
+	if err := json.Unmarshal(data, base); err != nil {
+		return nil, err
+	}
+	if len(base.EventType) > 0 {
+		eventType = base.EventType
+	}
+
+	event := &Event{Type: eventType, Data: newEventData(eventType), Raw: json.RawMessage(data)}
+	if err := json.Unmarshal(data, event.Data); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Subscribe attaches to the Marathon event stream and delivers events over the returned channel,
+// types (if any) filters the events received. The stream is reconnected with backoff on failures
+// and the channel is closed when ctx is done
+func (mc *Client) Subscribe(ctx context.Context, types ...string) (<-chan *Event, error) {
+
+	response, err := mc.attach(ctx, types)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan *Event, EventsBuffer)
+	go func() {
+		defer close(events)
+
+		backoff := EventsBackoff
+		for {
+			if response != nil {
+				mc.stream(ctx, response.Body, types, events)
+				response.Body.Close()
+				backoff = EventsBackoff
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			Logger.Debug("Events: reconnecting to %s", mc.Endpoint())
+			if response, err = mc.attach(ctx, types); err != nil {
+				Logger.Debug("Events: unable to reconnect [%+v]", err)
+				if backoff *= 2; backoff > EventsMaxBackoff {
+					backoff = EventsMaxBackoff
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// SubscribeFunc attaches to the Marathon event stream and calls handler for every event,
+// types (if any) filters the events received. It blocks until ctx is done
+func (mc *Client) SubscribeFunc(ctx context.Context, handler EventHandler, types ...string) error {
+
+	events, err := mc.Subscribe(ctx, types...)
+	if err != nil {
+		return err
+	}
+	for event := range events {
+		handler(event)
+	}
+	return ctx.Err()
+}
+
+// attach opens the event stream against the current endpoint, with failover to other endpoints
+func (mc *Client) attach(ctx context.Context, types []string) (*http.Response, error) {
+
+	params := url.Values{}
+	for _, eventType := range types {
+		params.Add("event_type", eventType)
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		endpoint := mc.Endpoint()
+		requestURL, err := buildURL(endpoint, APIEvents, params)
+		if err != nil {
+			return nil, err
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.Header = mc.headers()
+		request.Header.Set("Accept", "text/event-stream")
+
+		// The event stream is long lived, so we can't use the Client timeout
+		streamClient := &http.Client{Transport: mc.httpClient().Transport}
+
+		response, err := streamClient.Do(request)
+		if err == nil && response.StatusCode == http.StatusOK {
+			Logger.Debug("Events: attached to %s", requestURL)
+			return response, nil
+		}
+
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			err = failed(response, nil)
+			response.Body.Close()
+		}
+		// Just connection errors or 503 are worth to try against other endpoint
+		retry := isTransport(err) || IsUnavailable(err)
+		if !retry || attempt >= len(mc.Endpoints()) || !mc.failover(ctx, endpoint) {
+			return nil, err
+		}
+	}
+}
+
+// stream reads server-sent events from body until it is closed or ctx is done
+func (mc *Client) stream(ctx context.Context, body io.Reader, types []string, events chan<- *Event) {
+
+	reader := bufio.NewReader(body)
+
+	var eventType string
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case len(line) == 0 && len(data) > 0:
+			// Blank line dispatch the event
+			event, decodeErr := decodeEvent(eventType, []byte(strings.Join(data, "\n")))
+			if decodeErr != nil {
+				Logger.Debug("Events: unable to decode %s [%+v]", eventType, decodeErr)
+			} else if matchEvent(event.Type, types) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			eventType, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+
+		if err != nil {
+			Logger.Debug("Events: stream closed [%+v]", err)
+			return
+		}
+	}
+}
+
+// matchEvent returns true if eventType is one of types, or types is empty
+func matchEvent(eventType string, types []string) bool {
+
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransport returns true if err is not a Marathon failure response
+func isTransport(err error) bool {
+
+	var apiError *Error
+	return !errors.As(err, &apiError)
+}
@@ -0,0 +1,161 @@
+package marathon
+
+import (
+	"context"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_decodeEvent(t *testing.T) {
+
+	t.Run("decode a known event into its typed struct", func(t *testing.T) {
+
+		// Try to decode a status_update_event
+		event, err := decodeEvent("", []byte(mockserver.SomeEvents[1]))
+
+		// We get nil error
+		assert.Nil(t, err)
+
+		// Check some values
+		assert.Equal(t, EventStatusUpdate, event.Type)
+		assert.IsType(t, &StatusUpdateEvent{}, event.Data)
+		assert.Equal(t, "TASK_RUNNING", event.Data.(*StatusUpdateEvent).TaskStatus)
+		assert.Equal(t, "172.17.0.2", event.Data.(*StatusUpdateEvent).IPAddresses[0].IPAddress)
+	})
+
+	t.Run("decode an unknown event into BaseEvent", func(t *testing.T) {
+
+		// Try to decode an unknown event
+		event, err := decodeEvent("", []byte(`{"eventType":"brand_new_event","timestamp":"2021-01-21T20:27:42.725Z"}`))
+
+		// We get nil error
+		assert.Nil(t, err)
+
+		// Check some values
+		assert.Equal(t, "brand_new_event", event.Type)
+		assert.IsType(t, &BaseEvent{}, event.Data)
+	})
+
+	t.Run("get error on invalid data", func(t *testing.T) {
+
+		// Try to decode invalid data
+		_, err := decodeEvent(EventStatusUpdate, []byte(`{`))
+
+		// We get error
+		assert.NotNil(t, err)
+	})
+}
+
+func TestClient_Subscribe(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("receive all events", func(t *testing.T) {
+
+		// Try to create Client
+		_client := New(server.URL)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// Fire up Subscribe
+		events, err := _client.Subscribe(ctx)
+
+		// We get nil error
+		assert.Nil(t, err)
+
+		// Check the events received
+		for _, expected := range []string{EventEventStreamAttached, EventStatusUpdate, EventHealthStatusChanged, EventDeploymentSuccess} {
+			event := <-events
+			assert.Equal(t, expected, event.Type)
+		}
+	})
+
+	t.Run("receive filtered events and reconnect when stream is closed", func(t *testing.T) {
+
+		// Try to create Client
+		_client := New(server.URL)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// Fire up Subscribe
+		events, err := _client.Subscribe(ctx, EventDeploymentSuccess)
+
+		// We get nil error
+		assert.Nil(t, err)
+
+		// Mock Server closes the stream after each batch, so we get the event twice
+		for i := 0; i < 2; i++ {
+			event := <-events
+			assert.Equal(t, EventDeploymentSuccess, event.Type)
+			assert.Equal(t, "/infra/redis-1", event.Data.(*DeploymentEvent).Plan.Steps[0].Actions[0].App)
+		}
+	})
+
+	t.Run("channel is closed when ctx is done", func(t *testing.T) {
+
+		// Try to create Client
+		_client := New(server.URL)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Fire up Subscribe
+		events, err := _client.Subscribe(ctx, EventAppTerminated)
+		assert.Nil(t, err)
+
+		cancel()
+
+		// Channel must be closed
+		_, open := <-events
+		assert.False(t, open)
+	})
+
+	t.Run("get error when events endpoint fails", func(t *testing.T) {
+
+		// We create a server without events support
+		forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer forbidden.Close()
+
+		// Try to create Client
+		_client := New(forbidden.URL)
+
+		// Fire up Subscribe
+		events, err := _client.Subscribe(context.Background())
+
+		// We get error
+		assert.Nil(t, events)
+		assert.True(t, IsUnauthorized(err))
+	})
+}
+
+func TestClient_SubscribeFunc(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Try to create Client
+	_client := New(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Fire up SubscribeFunc, cancel once we get our event
+	var received []string
+	err := _client.SubscribeFunc(ctx, func(event *Event) {
+		received = append(received, event.Type)
+		cancel()
+	}, EventStatusUpdate)
+
+	// We get context error
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, EventStatusUpdate, received[0])
+}
@@ -0,0 +1,310 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//===
+
+// ContentType of the Prometheus text exposition format
+const ContentType string = "text/plain; version=0.0.4; charset=utf-8"
+
+// invalidChars matches characters not allowed in Prometheus metric names
+var invalidChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// quantiles maps Prometheus quantile labels to Marathon percentiles
+var quantiles = []struct {
+	label string
+	value func(p percentiles) float64
+}{
+	{"0.5", func(p percentiles) float64 { return p.P50 }},
+	{"0.75", func(p percentiles) float64 { return p.P75 }},
+	{"0.95", func(p percentiles) float64 { return p.P95 }},
+	{"0.98", func(p percentiles) float64 { return p.P98 }},
+	{"0.99", func(p percentiles) float64 { return p.P99 }},
+	{"0.999", func(p percentiles) float64 { return p.P999 }},
+}
+
+// appFamilies are the gauges of the runtime state of every App
+var appFamilies = []struct {
+	name  string
+	help  string
+	value func(app *application.AppStatus) float64
+}{
+	{"marathon_app_instances", "Instances requested by the App", func(app *application.AppStatus) float64 { return float64(app.Instances) }},
+	{"marathon_app_tasks_staged", "Tasks of the App staged", func(app *application.AppStatus) float64 { return float64(app.TasksStaged) }},
+	{"marathon_app_tasks_running", "Tasks of the App running", func(app *application.AppStatus) float64 { return float64(app.TasksRunning) }},
+	{"marathon_app_tasks_healthy", "Tasks of the App healthy", func(app *application.AppStatus) float64 { return float64(app.TasksHealthy) }},
+	{"marathon_app_tasks_unhealthy", "Tasks of the App unhealthy", func(app *application.AppStatus) float64 { return float64(app.TasksUnhealthy) }},
+	{"marathon_app_cpus", "CPUs requested by each App instance", func(app *application.AppStatus) float64 { return app.Cpus }},
+	{"marathon_app_memory_megabytes", "Memory requested by each App instance", func(app *application.AppStatus) float64 { return app.Mem }},
+	{"marathon_app_deployments", "Deployments in progress affecting the App", func(app *application.AppStatus) float64 { return float64(len(app.Deployments)) }},
+}
+
+// Marathon Prometheus exporter interface
+type exporter interface {
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+	Collect(ctx context.Context, w io.Writer) error
+}
+
+// Exporter is an http.Handler exposing Marathon metrics and Apps state in Prometheus text format
+type Exporter struct {
+	client *marathon.Client
+}
+
+// apps holds the Apps state returned by /v2/apps
+type apps struct {
+	Apps []application.AppStatus `json:"apps"`
+}
+
+// percentiles holds the distribution shared by histograms and timers
+type percentiles struct {
+	Count                               int64
+	Mean, P50, P75, P95, P98, P99, P999 float64
+}
+
+// New returns a new instance of Marathon Prometheus exporter
+func New(client *marathon.Client) *Exporter {
+
+	if client != nil {
+		return &Exporter{
+			client: client,
+		}
+	}
+	return nil
+}
+
+// ServeHTTP collects Marathon metrics and Apps state on every scrape, marathon_up reports
+// whether Marathon answered, so a failed scrape is still a valid exposition
+func (me *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	buffer := &bytes.Buffer{}
+	err := me.Collect(r.Context(), buffer)
+
+	up := 1
+	if err != nil {
+		marathon.Logger.Debug("Exporter: unable to collect Marathon metrics: %s", err)
+		up = 0
+	}
+	writeFamily(buffer, "marathon_up", "gauge", "Whether Marathon answered the last scrape")
+	writeSample(buffer, "marathon_up", nil, float64(up))
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buffer.Bytes())
+}
+
+// Collect writes Marathon metrics and Apps state in Prometheus text format into w
+func (me *Exporter) Collect(ctx context.Context, w io.Writer) error {
+
+	metrics, err := me.client.MetricsContext(ctx)
+	if err != nil {
+		return err
+	}
+	state := &apps{}
+	if err := me.client.Do(ctx, http.MethodGet, marathon.APIApps, nil, nil, state, nil); err != nil {
+		return err
+	}
+
+	buffer := &bytes.Buffer{}
+	writeMetrics(buffer, metrics)
+	writeApps(buffer, state.Apps)
+
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
+
+// names holds the Prometheus metric names already written
+type names map[string]bool
+
+// unique returns the Prometheus name of the Marathon metric name, if it or any name derived from
+// it with suffixes is already used, as different Marathon names may be sanitized into the same
+// one, it is suffixed with _2, _3... The names derived are marked as used
+func (n names) unique(name string, suffixes ...string) string {
+
+	base := Name(name)
+	metric := base
+	for count := 2; n.used(metric, suffixes); count++ {
+		metric = fmt.Sprintf("%s_%d", base, count)
+	}
+	for _, suffix := range suffixes {
+		n[metric+suffix] = true
+	}
+	return metric
+}
+
+// used returns true if any name derived from metric with suffixes is already used
+func (n names) used(metric string, suffixes []string) bool {
+
+	for _, suffix := range suffixes {
+		if n[metric+suffix] {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMetrics converts Marathon metrics, histograms and timers are exposed as summaries
+func writeMetrics(w io.Writer, metrics *marathon.Metrics) {
+
+	// Names of the families written besides Marathon metrics are never reused
+	written := names{"marathon_up": true}
+	for _, family := range appFamilies {
+		written[family.name] = true
+	}
+
+	for _, name := range sortedKeys(metrics.Counters) {
+		metric := written.unique(name, "")
+		writeFamily(w, metric, "counter", "Marathon counter "+name)
+		writeSample(w, metric, nil, float64(metrics.Counters[name].Count))
+	}
+	for _, name := range sortedKeys(metrics.Gauges) {
+		metric := written.unique(name, "")
+		writeFamily(w, metric, "gauge", "Marathon gauge "+name)
+		writeSample(w, metric, nil, metrics.Gauges[name].Value)
+	}
+	for _, name := range sortedKeys(metrics.Histograms) {
+		histogram := metrics.Histograms[name]
+		writeSummary(w, written.unique(name, "", "_sum", "_count"), "Marathon histogram "+name, percentiles{
+			Count: histogram.Count, Mean: histogram.Mean,
+			P50: histogram.P50, P75: histogram.P75, P95: histogram.P95, P98: histogram.P98, P99: histogram.P99, P999: histogram.P999,
+		})
+	}
+	for _, name := range sortedKeys(metrics.Meters) {
+		meter := metrics.Meters[name]
+		metric := written.unique(name, "_total", "_rate")
+		writeFamily(w, metric+"_total", "counter", "Marathon meter "+name)
+		writeSample(w, metric+"_total", nil, float64(meter.Count))
+		writeRates(w, metric, name, meter.M1Rate, meter.M5Rate, meter.M15Rate, meter.MeanRate)
+	}
+	for _, name := range sortedKeys(metrics.Timers) {
+		timer := metrics.Timers[name]
+		metric := written.unique(name, "", "_sum", "_count", "_rate")
+		writeSummary(w, metric, "Marathon timer "+name, percentiles{
+			Count: timer.Count, Mean: timer.Mean,
+			P50: timer.P50, P75: timer.P75, P95: timer.P95, P98: timer.P98, P99: timer.P99, P999: timer.P999,
+		})
+		writeRates(w, metric, name, timer.M1Rate, timer.M5Rate, timer.M15Rate, timer.MeanRate)
+	}
+}
+
+// writeApps converts the runtime state of every App, labeled with its id and group
+func writeApps(w io.Writer, apps []application.AppStatus) {
+
+	sort.Slice(apps, func(i, j int) bool { return apps[i].ID < apps[j].ID })
+
+	for _, family := range appFamilies {
+		writeFamily(w, family.name, "gauge", family.help)
+		for index := range apps {
+			labels := [][2]string{{"app_id", apps[index].ID}, {"group", path.Dir(apps[index].ID)}}
+			writeSample(w, family.name, labels, family.value(&apps[index]))
+		}
+	}
+}
+
+// writeSummary writes a distribution as a Prometheus summary, sum is estimated from mean
+func writeSummary(w io.Writer, metric, help string, p percentiles) {
+
+	writeFamily(w, metric, "summary", help)
+	for _, quantile := range quantiles {
+		writeSample(w, metric, [][2]string{{"quantile", quantile.label}}, quantile.value(p))
+	}
+	writeSample(w, metric+"_sum", nil, p.Mean*float64(p.Count))
+	writeSample(w, metric+"_count", nil, float64(p.Count))
+}
+
+// writeRates writes the moving average rates of a meter or timer as a gauge labeled by window
+func writeRates(w io.Writer, metric, name string, m1, m5, m15, mean float64) {
+
+	writeFamily(w, metric+"_rate", "gauge", "Marathon rate "+name)
+	writeSample(w, metric+"_rate", [][2]string{{"window", "1m"}}, m1)
+	writeSample(w, metric+"_rate", [][2]string{{"window", "5m"}}, m5)
+	writeSample(w, metric+"_rate", [][2]string{{"window", "15m"}}, m15)
+	writeSample(w, metric+"_rate", [][2]string{{"window", "mean"}}, mean)
+}
+
+// writeFamily writes HELP and TYPE lines of a metric family
+func writeFamily(w io.Writer, metric, kind, help string) {
+
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric, escape(help, false), metric, kind)
+}
+
+// writeSample writes a sample line with its labels
+func writeSample(w io.Writer, metric string, labels [][2]string, value float64) {
+
+	var builder strings.Builder
+	builder.WriteString(metric)
+	if len(labels) > 0 {
+		builder.WriteByte('{')
+		for index, label := range labels {
+			if index > 0 {
+				builder.WriteByte(',')
+			}
+			fmt.Fprintf(&builder, "%s=\"%s\"", label[0], escape(label[1], true))
+		}
+		builder.WriteByte('}')
+	}
+	_, _ = fmt.Fprintf(w, "%s %s\n", builder.String(), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// Name converts a Marathon metric name into a valid Prometheus metric name
+func Name(name string) string {
+
+	metric := invalidChars.ReplaceAllString(name, "_")
+	if len(metric) > 0 && metric[0] >= '0' && metric[0] <= '9' {
+		metric = "_" + metric
+	}
+	return metric
+}
+
+// escape escapes backslashes and newlines, and double quotes on label values
+func escape(value string, quotes bool) string {
+
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	if quotes {
+		value = strings.Replace(value, `"`, `\"`, -1)
+	}
+	return value
+}
+
+// sortedKeys returns the sorted metric names of a metric map
+func sortedKeys(metrics interface{}) []string {
+
+	var names []string
+	switch typed := metrics.(type) {
+	case map[string]marathon.Counter:
+		for name := range typed {
+			names = append(names, name)
+		}
+	case map[string]marathon.Gauge:
+		for name := range typed {
+			names = append(names, name)
+		}
+	case map[string]marathon.Histogram:
+		for name := range typed {
+			names = append(names, name)
+		}
+	case map[string]marathon.Meter:
+		for name := range typed {
+			names = append(names, name)
+		}
+	case map[string]marathon.Timer:
+		for name := range typed {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"bytes"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_New(t *testing.T) {
+
+	t.Run("nil Exporter if send nil client", func(t *testing.T) {
+
+		// Try to create Exporter
+		_exporter := New(nil)
+
+		// Exporter is nil
+		assert.Nil(t, _exporter)
+	})
+
+	t.Run("valid Exporter if send valid client", func(t *testing.T) {
+
+		// Try to create Exporter
+		_exporter := New(marathon.New("http://127.0.0.1:8080"))
+
+		// Exporter is not nil
+		assert.NotNil(t, _exporter)
+	})
+}
+
+func Test_Name(t *testing.T) {
+
+	assert.Equal(t, "marathon_mesos_offers_used_counter", Name("marathon.mesos.offers.used.counter"))
+	assert.Equal(t, "marathon_http_responses_5xx_rate_meter", Name("marathon.http.responses.5xx.rate.meter"))
+	assert.Equal(t, "_5xx", Name("5xx"))
+}
+
+func Test_writeMetrics(t *testing.T) {
+
+	t.Run("names sanitized into the same one are suffixed", func(t *testing.T) {
+
+		// We define some vars
+		buffer := &bytes.Buffer{}
+		metrics := &marathon.Metrics{
+			Counters: map[string]marathon.Counter{"jvm.threads": {Count: 1}, "jvm-threads": {Count: 2}},
+			Gauges:   map[string]marathon.Gauge{"jvm.threads": {Value: 3}, "marathon.up": {Value: 4}},
+			Meters:   map[string]marathon.Meter{"jvm.threads": {Count: 5}},
+			Timers:   map[string]marathon.Timer{"jvm.threads.total": {Count: 6}},
+		}
+
+		writeMetrics(buffer, metrics)
+		text := buffer.String()
+
+		// Every family is written once
+		assert.Contains(t, text, "# TYPE jvm_threads counter\njvm_threads 2\n")
+		assert.Contains(t, text, "# TYPE jvm_threads_2 counter\njvm_threads_2 1\n")
+		assert.Contains(t, text, "# TYPE jvm_threads_3 gauge\njvm_threads_3 3\n")
+		assert.Contains(t, text, "# TYPE marathon_up_2 gauge\nmarathon_up_2 4\n")
+		// The meter takes jvm_threads_total, so the timer is suffixed
+		assert.Contains(t, text, "# TYPE jvm_threads_total counter\njvm_threads_total 5\n")
+		assert.Contains(t, text, "# TYPE jvm_threads_total_2 summary\n")
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(line, "# TYPE ") {
+				assert.Equal(t, 1, strings.Count(text, line+"\n"), line)
+			}
+		}
+	})
+}
+
+func TestExporter_ServeHTTP(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("expose metrics and apps state of a valid server", func(t *testing.T) {
+
+		// Scrape the Exporter
+		recorder := httptest.NewRecorder()
+		New(marathon.New(server.URL)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		body, _ := ioutil.ReadAll(recorder.Body)
+		text := string(body)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+
+		// Marathon metrics
+		assert.Contains(t, text, "# TYPE marathon_mesos_offers_used_counter counter\nmarathon_mesos_offers_used_counter 12\n")
+		assert.Contains(t, text, "marathon_jvm_memory_heap_usage_gauge 0.42\n")
+		assert.Contains(t, text, "# TYPE marathon_http_requests_size_histogram_bytes summary\n")
+		assert.Contains(t, text, "marathon_http_requests_size_histogram_bytes{quantile=\"0.5\"} 5\n")
+		assert.Contains(t, text, "marathon_http_requests_size_histogram_bytes_count 10\n")
+		assert.Contains(t, text, "marathon_http_responses_5xx_rate_meter_total 2\n")
+		assert.Contains(t, text, "marathon_http_responses_5xx_rate_meter_rate{window=\"1m\"} 0.1\n")
+		assert.Contains(t, text, "marathon_http_requests_duration_timer_seconds{quantile=\"0.99\"} 0.15\n")
+
+		// Apps state
+		assert.Contains(t, text, "marathon_app_tasks_running{app_id=\"/infra/redis-1\",group=\"/infra\"} 1\n")
+		assert.Contains(t, text, "marathon_app_tasks_unhealthy{app_id=\"/infra/redis-1\",group=\"/infra\"} 1\n")
+		assert.Contains(t, text, "marathon_app_deployments{app_id=\"/infra/redis-1\",group=\"/infra\"} 1\n")
+		assert.Contains(t, text, "marathon_app_memory_megabytes{app_id=\"/infra/broker-0\",group=\"/infra\"} 8192\n")
+
+		assert.Contains(t, text, "marathon_up 1\n")
+	})
+
+	t.Run("report down if server is unreachable", func(t *testing.T) {
+
+		// Scrape the Exporter
+		recorder := httptest.NewRecorder()
+		New(marathon.New("http://127.0.0.1:1")).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "# HELP marathon_up Whether Marathon answered the last scrape\n# TYPE marathon_up gauge\nmarathon_up 0\n", recorder.Body.String())
+	})
+}
+
+func Test_escape(t *testing.T) {
+
+	assert.Equal(t, `a\\b\nc\"d`, escape("a\\b\nc\"d", true))
+	assert.Equal(t, `a\\b\nc"d`, escape("a\\b\nc\"d", false))
+}
@@ -1,13 +1,16 @@
 package filtered
 
 import (
+	"context"
 	"fmt"
 	"github.com/dotWicho/marathon"
 	"github.com/dotWicho/marathon/application"
 	"github.com/dotWicho/marathon/data"
 	"github.com/dotWicho/utilities"
+	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // FilterFunction is a type to create callback functions
@@ -31,11 +34,19 @@ type AppSummary struct {
 // filteredApps Marathon Application FilteredApps interface
 type filteredApps interface {
 	Get(filter string) *Apps
+	GetContext(ctx context.Context, filter string) *Apps
 	Scale(instances int, force bool) error
+	ScaleContext(ctx context.Context, instances int, force bool) error
 	Stop(force bool) error
+	StopContext(ctx context.Context, force bool) error
 	Start(instances int, force bool) error
+	StartContext(ctx context.Context, instances int, force bool) error
 	Restart(force bool) error
+	RestartContext(ctx context.Context, force bool) error
 	Suspend(force bool) error
+	SuspendContext(ctx context.Context, force bool) error
+	ApplyAndWait(force bool, timeout time.Duration) ([]*application.DeployResult, error)
+	ApplyAndWaitContext(ctx context.Context, force bool, timeout time.Duration) ([]*application.DeployResult, error)
 
 	Load(fileName, filter string) *Apps
 	Dump(fileName string) (err error)
@@ -45,6 +56,7 @@ type filteredApps interface {
 
 	AsMap() map[string]AppSummary
 	AsRaw() []application.AppDefinition
+	LastError() error
 }
 
 // FilteredApps is a Marathon Applications by filter implementation
@@ -56,6 +68,7 @@ type Apps struct {
 	//
 	deploy *data.Response
 	fail   *data.FailureMessage
+	err    error
 }
 
 // NewFilteredApps returns a new instance of Marathon filteredApps implementation
@@ -75,12 +88,18 @@ func NewFilteredApps(client *marathon.Client) *Apps {
 // Get allows to establish the internal structures to referenced id
 func (fa *Apps) Get(filter string) *Apps {
 
+	return fa.GetContext(context.Background(), filter)
+}
+
+// GetContext allows to establish the internal structures to referenced id, honoring ctx
+func (fa *Apps) GetContext(ctx context.Context, filter string) *Apps {
+
 	if len(filter) > 0 {
 
 		marathon.Logger.Debug("FilteredApps: Get (%s)", filter)
 		_apps := &apps{}
 
-		if _, err := fa.client.Session.BodyAsJSON(nil).Get(marathon.APIApps, _apps, fa.fail); err != nil {
+		if fa.err = fa.client.Do(ctx, http.MethodGet, marathon.APIApps, nil, nil, _apps, fa.fail); fa.err != nil {
 			fa.apps.Apps = nil
 			return fa
 		}
@@ -100,6 +119,12 @@ func (fa *Apps) Get(filter string) *Apps {
 // Scale allows change instances numbers of a Marathon filteredApps
 func (fa *Apps) Scale(instances int, force bool) error {
 
+	return fa.ScaleContext(context.Background(), instances, force)
+}
+
+// ScaleContext allows change instances numbers of a Marathon filteredApps, honoring ctx
+func (fa *Apps) ScaleContext(ctx context.Context, instances int, force bool) error {
+
 	if fa.apps != nil && len(fa.apps.Apps) > 0 {
 
 		marathon.Logger.Debug("FilteredApps: Scale %d %v [%+v] %d", instances, force, fa.apps.Apps, len(fa.apps.Apps))
@@ -107,7 +132,7 @@ func (fa *Apps) Scale(instances int, force bool) error {
 		appHandler := application.New(fa.client)
 		for index, app := range fa.apps.Apps {
 
-			if err := appHandler.Get(app.ID).Scale(instances, force); err != nil {
+			if err := appHandler.GetContext(ctx, app.ID).ScaleContext(ctx, instances, force); err != nil {
 				return err
 			}
 			fa.apps.Apps[index].Instances = instances
@@ -120,6 +145,12 @@ func (fa *Apps) Scale(instances int, force bool) error {
 // Stop sets instances of a Marathon filteredApps to 0
 func (fa *Apps) Stop(force bool) error {
 
+	return fa.StopContext(context.Background(), force)
+}
+
+// StopContext sets instances of a Marathon filteredApps to 0, honoring ctx
+func (fa *Apps) StopContext(ctx context.Context, force bool) error {
+
 	if fa.apps != nil && len(fa.apps.Apps) > 0 {
 
 		marathon.Logger.Debug("FilteredApps: Stop %v [%+v] %d", force, fa.apps.Apps, len(fa.apps.Apps))
@@ -127,7 +158,7 @@ func (fa *Apps) Stop(force bool) error {
 		appHandler := application.New(fa.client)
 		for index, app := range fa.apps.Apps {
 
-			if err := appHandler.Get(app.ID).Stop(force); err != nil {
+			if err := appHandler.GetContext(ctx, app.ID).StopContext(ctx, force); err != nil {
 				return err
 			}
 			fa.apps.Apps[index].Instances = 0
@@ -140,13 +171,19 @@ func (fa *Apps) Stop(force bool) error {
 // Start sets instances of a Marathon filteredApps to a number provided
 func (fa *Apps) Start(instances int, force bool) error {
 
+	return fa.StartContext(context.Background(), instances, force)
+}
+
+// StartContext sets instances of a Marathon filteredApps to a number provided, honoring ctx
+func (fa *Apps) StartContext(ctx context.Context, instances int, force bool) error {
+
 	if fa.apps != nil && len(fa.apps.Apps) > 0 {
 
 		marathon.Logger.Debug("FilteredApps: Start %v [%+v] %d", force, fa.apps.Apps, len(fa.apps.Apps))
 
 		appHandler := application.New(fa.client)
 		for index, app := range fa.apps.Apps {
-			if err := appHandler.Get(app.ID).Start(instances, force); err != nil {
+			if err := appHandler.GetContext(ctx, app.ID).StartContext(ctx, instances, force); err != nil {
 				return err
 			}
 			fa.apps.Apps[index].Instances = instances
@@ -159,13 +196,19 @@ func (fa *Apps) Start(instances int, force bool) error {
 // Restart use an endpoint to trigger a Marathon filteredApps restart
 func (fa *Apps) Restart(force bool) error {
 
+	return fa.RestartContext(context.Background(), force)
+}
+
+// RestartContext use an endpoint to trigger a Marathon filteredApps restart, honoring ctx
+func (fa *Apps) RestartContext(ctx context.Context, force bool) error {
+
 	if fa.apps != nil && len(fa.apps.Apps) > 0 {
 
 		marathon.Logger.Debug("FilteredApps: Restart %v [%+v] %d", force, fa.apps.Apps, len(fa.apps.Apps))
 
 		appHandler := application.New(fa.client)
 		for _, app := range fa.apps.Apps {
-			if err := appHandler.Get(app.ID).Restart(force); err != nil {
+			if err := appHandler.GetContext(ctx, app.ID).RestartContext(ctx, force); err != nil {
 				return err
 			}
 		}
@@ -180,6 +223,32 @@ func (fa *Apps) Suspend(force bool) error {
 	return fa.Stop(force)
 }
 
+// SuspendContext is an alias to StopContext
+func (fa *Apps) SuspendContext(ctx context.Context, force bool) error {
+
+	return fa.StopContext(ctx, force)
+}
+
+// ApplyAndWait sends the configuration of filteredApps and waits until every app deployment
+// finish and its tasks are running and healthy
+func (fa *Apps) ApplyAndWait(force bool, timeout time.Duration) ([]*application.DeployResult, error) {
+
+	return fa.ApplyAndWaitContext(context.Background(), force, timeout)
+}
+
+// ApplyAndWaitContext sends the configuration of filteredApps and waits until every app deployment
+// finish and its tasks are running and healthy, honoring ctx
+func (fa *Apps) ApplyAndWaitContext(ctx context.Context, force bool, timeout time.Duration) ([]*application.DeployResult, error) {
+
+	if fa.apps != nil && len(fa.apps.Apps) > 0 {
+
+		marathon.Logger.Debug("FilteredApps: ApplyAndWait %v %v %d", force, timeout, len(fa.apps.Apps))
+
+		return application.ApplyAllAndWait(ctx, fa.client, fa.apps.Apps, force, timeout)
+	}
+	return nil, fmt.Errorf("filteredApps ApplyAndWait was called with an empty set")
+}
+
 // Load allows create or update a Marathon filteredApps from file
 func (fa *Apps) Load(fileName, filter string) *Apps {
 
@@ -291,6 +360,12 @@ func (fa *Apps) AsMap() map[string]AppSummary {
 	return nil
 }
 
+// LastError returns the error of last Get, nil if it was successful
+func (fa *Apps) LastError() error {
+
+	return fa.err
+}
+
 // AsRaw returns a pointer of Application Info
 func (fa *Apps) AsRaw() []application.AppDefinition {
 
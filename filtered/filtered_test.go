@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_NewFilteredApps(t *testing.T) {
@@ -263,6 +264,44 @@ func TestFilteredApps_Suspend(t *testing.T) {
 	})
 }
 
+func TestFilteredApps_ApplyAndWait(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get error when ApplyAndWait is called with app empty", func(t *testing.T) {
+
+		// Try to create FilteredApp
+		_apps := NewFilteredApps(marathon.New(server.URL))
+
+		// try to ApplyAndWait with an empty set
+		results, err := _apps.ApplyAndWait(true, time.Second)
+
+		// We get an error
+		assert.Nil(t, results)
+		assert.NotNil(t, err)
+		assert.Equal(t, "filteredApps ApplyAndWait was called with an empty set", err.Error())
+	})
+
+	t.Run("get results when ApplyAndWait is called with valid apps", func(t *testing.T) {
+
+		// Try to create FilteredApp
+		_apps := NewFilteredApps(marathon.New(server.URL))
+
+		// try to Get and ApplyAndWait
+		results, err := _apps.Get("/infra").ApplyAndWait(true, 5*time.Second)
+
+		// We get not error
+		assert.Nil(t, err)
+
+		// Check some values on results
+		assert.Len(t, results, 2)
+		assert.Equal(t, "/infra/redis-1", results[0].AppID)
+		assert.Equal(t, 1, results[0].TasksHealthy)
+	})
+}
+
 func TestFilteredApps_Load(t *testing.T) {
 
 	// We create a Mock Server
@@ -7,4 +7,5 @@ require (
 	github.com/dotWicho/requist v1.2.5
 	github.com/dotWicho/utilities v1.0.1
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/yaml.v2 v2.2.4
 )
@@ -0,0 +1,57 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"time"
+)
+
+// OnConflict sets what Update and Apply do when the group changed on Marathon server since it
+// was read with Get: overwrite it (the default) or fail, groups are never rebased so
+// RebaseOnChange fails too
+func (mg *Groups) OnConflict(policy marathon.ConflictPolicy) *Groups {
+
+	mg.policy = policy
+	return mg
+}
+
+// Version returns the version of the group read with Get (or written later), zero if unknown
+func (mg *Groups) Version() time.Time {
+
+	return mg.version
+}
+
+// currentVersion returns the version of the group id as it is on Marathon server
+func (mg *Groups) currentVersion(ctx context.Context, id string) (time.Time, error) {
+
+	path := fmt.Sprintf("%s%s", marathon.APIGroups, utilities.DelInitialSlash(id))
+
+	var status struct {
+		Version time.Time `json:"version"`
+	}
+	if err := mg.client.Do(ctx, http.MethodGet, path, nil, nil, &status, mg.fail); err != nil {
+		return time.Time{}, err
+	}
+	return status.Version, nil
+}
+
+// checkVersion compares the version read with the current one on Marathon server before
+// sending changes, it returns a ModifiedError if it changed and the policy is not Overwrite
+func (mg *Groups) checkVersion(ctx context.Context) error {
+
+	if mg.policy == marathon.Overwrite || mg.version.IsZero() {
+		return nil
+	}
+
+	version, err := mg.currentVersion(ctx, mg.group.ID)
+	if err != nil {
+		return err
+	}
+	if !version.Equal(mg.version) {
+		return &marathon.ModifiedError{ID: mg.group.ID, Read: mg.version, Current: version}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/deployment"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTierTimeout is the time group operations wait for the deployments of a tier of apps
+const DefaultTierTimeout = 10 * time.Minute
+
+// CycleError is returned when the dependencies of apps and groups form a cycle
+type CycleError struct {
+	// Cycle holds the ids of the apps in the cycle, the first one is repeated at the end
+	Cycle []string
+}
+
+// Error returns the cycle found
+func (ce *CycleError) Error() string {
+
+	return fmt.Sprintf("dependency cycle between apps: %s", strings.Join(ce.Cycle, " -> "))
+}
+
+// deployFunc applies an operation to an app returning the id of the deployment started, if any
+type deployFunc func(app application.AppDefinition) (string, error)
+
+// dependentApp is an app of a group tree with the absolute ids it depends on
+type dependentApp struct {
+	app          application.AppDefinition
+	dependencies []string
+}
+
+// Tiers returns the apps of the group tree sorted by their dependencies and the ones of the groups
+// holding them: apps of a tier only depend on apps of previous tiers, dependencies out of the
+// tree are ignored as they are not managed by the group. Dependencies on a group are
+// dependencies on all its apps. A CycleError is returned if apps depend on each other
+func (g *Group) Tiers() ([][]application.AppDefinition, error) {
+
+	var apps []dependentApp
+	collectApps(g, "", nil, &apps)
+
+	// Edges from each app to the apps it depends on
+	edges := make([][]int, len(apps))
+	for index, node := range apps {
+		for _, dependency := range node.dependencies {
+			for other, candidate := range apps {
+				if other != index && (candidate.app.ID == dependency || strings.HasPrefix(candidate.app.ID, dependency+"/")) {
+					edges[index] = append(edges[index], other)
+				}
+			}
+		}
+	}
+
+	var tiers [][]application.AppDefinition
+	done := make([]bool, len(apps))
+	for left := len(apps); left > 0; {
+
+		var ready []int
+		for index := range apps {
+			if !done[index] && allDone(edges[index], done) {
+				ready = append(ready, index)
+			}
+		}
+		if len(ready) == 0 {
+			return nil, &CycleError{Cycle: findCycle(apps, edges, done)}
+		}
+
+		tier := make([]application.AppDefinition, 0, len(ready))
+		for _, index := range ready {
+			done[index] = true
+			tier = append(tier, apps[index].app)
+		}
+		tiers = append(tiers, tier)
+		left -= len(ready)
+	}
+	return tiers, nil
+}
+
+// WithTimeout sets how long group operations wait for the deployments of a tier of apps before
+// going on with the next one
+func (mg *Groups) WithTimeout(timeout time.Duration) *Groups {
+
+	mg.timeout = timeout
+	return mg
+}
+
+// inOrder runs deploy on every app of the group tree, dependencies first or last if reverse is
+// true, waiting for the deployments of each tier to finish before going on with the next one;
+// apps of a tier are traversed following the workers and error policy of mg
+func (mg *Groups) inOrder(ctx context.Context, reverse bool, deploy deployFunc) error {
+
+	tiers, err := mg.group.Tiers()
+	if err != nil {
+		return err
+	}
+	if reverse {
+		for left, right := 0, len(tiers)-1; left < right; left, right = left+1, right-1 {
+			tiers[left], tiers[right] = tiers[right], tiers[left]
+		}
+	}
+
+	for index, tier := range tiers {
+		marathon.Logger.Debug("Groups: %s tier %d of %d => %d apps", mg.group.ID, index+1, len(tiers), len(tier))
+
+		// With ContinueOnError the whole tier runs, but the next ones may depend on the failed apps
+		var mutex sync.Mutex
+		var deployments []string
+		err := mg.traverse(tier, func(app application.AppDefinition) error {
+			id, err := deploy(app)
+			if err == nil && len(id) > 0 {
+				mutex.Lock()
+				deployments = append(deployments, id)
+				mutex.Unlock()
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// The last tier is not awaited, as when operations run app by app
+		if index == len(tiers)-1 {
+			break
+		}
+		if err := mg.awaitTier(ctx, deployments); err != nil {
+			return fmt.Errorf("deployments of tier %d of group %s: %w", index+1, mg.group.ID, err)
+		}
+	}
+	return nil
+}
+
+// awaitTier waits until all deployments finish within the tier timeout
+func (mg *Groups) awaitTier(ctx context.Context, deployments []string) error {
+
+	finish := time.Now().Add(mg.timeout)
+	awaited := make(map[string]bool, len(deployments))
+	for _, id := range deployments {
+		if awaited[id] {
+			continue
+		}
+		awaited[id] = true
+		if err := deployment.New(mg.client).AwaitContext(ctx, id, time.Until(finish)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectApps appends every app of group with the dependencies inherited from its groups, ids are
+// resolved against the group holding them
+func collectApps(group *Group, parent string, inherited []string, apps *[]dependentApp) {
+
+	id := resolve(parent, group.ID)
+
+	dependencies := append([]string{}, inherited...)
+	for _, dependency := range group.Dependencies {
+		dependencies = append(dependencies, resolve(parent, dependency))
+	}
+
+	for _, app := range group.Apps {
+		node := dependentApp{app: app, dependencies: append([]string{}, dependencies...)}
+		node.app.ID = resolve(id, app.ID)
+		for _, dependency := range app.Dependencies {
+			node.dependencies = append(node.dependencies, resolve(id, dependency))
+		}
+		*apps = append(*apps, node)
+	}
+	for index := range group.Groups {
+		collectApps(&group.Groups[index], id, dependencies, apps)
+	}
+}
+
+// resolve returns the absolute id of id relative to parent
+func resolve(parent, id string) string {
+
+	if strings.HasPrefix(id, "/") {
+		return path.Clean(id)
+	}
+	return path.Join("/", parent, id)
+}
+
+// allDone returns true if all the apps in indexes are done
+func allDone(indexes []int, done []bool) bool {
+
+	for _, index := range indexes {
+		if !done[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// findCycle returns the ids of a cycle between apps not done yet
+func findCycle(apps []dependentApp, edges [][]int, done []bool) []string {
+
+	// Every app left depends on another app left, so walking its first one ends in a cycle
+	visited := make(map[int]int)
+	var walk []int
+	index := -1
+	for candidate := range apps {
+		if !done[candidate] {
+			index = candidate
+			break
+		}
+	}
+	for index >= 0 {
+		if start, ok := visited[index]; ok {
+			cycle := make([]string, 0, len(walk)-start+1)
+			for _, node := range walk[start:] {
+				cycle = append(cycle, apps[node].app.ID)
+			}
+			return append(cycle, apps[index].app.ID)
+		}
+		visited[index] = len(walk)
+		walk = append(walk, index)
+
+		next := -1
+		for _, other := range edges[index] {
+			if !done[other] {
+				next = other
+				break
+			}
+		}
+		index = next
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package groups
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/dotWicho/marathon"
 	"github.com/dotWicho/marathon/application"
 	"github.com/dotWicho/marathon/data"
+	"github.com/dotWicho/marathon/pods"
 	"github.com/dotWicho/utilities"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
@@ -15,20 +19,42 @@ import (
 // Marathon Groups interface
 type groups interface {
 	Get(id string) *Groups
+	GetContext(ctx context.Context, id string) *Groups
+	Set(group *Group) *Groups
 	Create(group *Group) error
+	CreateContext(ctx context.Context, group *Group) error
 	Destroy() error
+	DestroyContext(ctx context.Context) error
 	Update(group *Group) error
+	UpdateContext(ctx context.Context, group *Group) error
 
 	Scale(instances int, force bool) error
+	ScaleContext(ctx context.Context, instances int, force bool) error
 	Stop(force bool) error
+	StopContext(ctx context.Context, force bool) error
 	Start(instances int, force bool) error
+	StartContext(ctx context.Context, instances int, force bool) error
 	Restart(force bool) error
+	RestartContext(ctx context.Context, force bool) error
 	Suspend(force bool) error
+	SuspendContext(ctx context.Context, force bool) error
+
+	Apply(force bool) error
+	ApplyContext(ctx context.Context, force bool) error
+	ApplyAndWait(force bool, timeout time.Duration) ([]*application.DeployResult, error)
+	ApplyAndWaitContext(ctx context.Context, force bool, timeout time.Duration) ([]*application.DeployResult, error)
 
 	Load(fileName string) *Groups
 	Dump(fileName string) (err error)
 
 	AsRaw() *Group
+	LastError() error
+
+	OnConflict(policy marathon.ConflictPolicy) *Groups
+	Version() time.Time
+	WithTimeout(timeout time.Duration) *Groups
+	OnError(policy ErrorPolicy) *Groups
+	Workers(workers int) *Groups
 
 	traverseGroupsWithAppID(group *Group, callbackFunc CallBackFuncsWithAppID) (err error)
 	traverseGroupsWithAppDefinition(group *Group, callbackFunc CallBackFuncsWithAppDef) (err error)
@@ -41,9 +67,17 @@ type Groups struct {
 	//
 	group *Group
 
+	//
+	policy  marathon.ConflictPolicy
+	version time.Time
+	timeout time.Duration
+	onError ErrorPolicy
+	workers int
+
 	//
 	deploy *data.Response
 	fail   *data.FailureMessage
+	err    error
 }
 
 // Group encapsulates the data definitions of a Marathon Group
@@ -51,12 +85,15 @@ type Group struct {
 	ID           string                      `json:"id"`
 	Apps         []application.AppDefinition `json:"apps"`
 	Groups       []Group                     `json:"groups"`
-	Pods         []interface{}               `json:"pods"`
+	Pods         []pods.PodDefinition        `json:"pods"`
 	Dependencies []string                    `json:"dependencies,omitempty"`
 	Version      time.Time                   `json:"version,omitempty"`
 	VersionInfo  application.VersionInfo     `json:"versionInfo,omitempty"`
 	Executor     string                      `json:"executor,omitempty"`
 	EnforceRole  bool                        `json:"enforceRole,omitempty"`
+
+	// Extra holds fields not modeled (or zero valued) in the original payload, re-emitted on marshal
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // CallBackFuncsWithAppID function type
@@ -65,6 +102,45 @@ type CallBackFuncsWithAppID func(appID string) error
 // CallBackFuncsWithAppDef function type
 type CallBackFuncsWithAppDef func(app application.AppDefinition) error
 
+// UnmarshalJSON decodes a Group keeping fields not modeled into Extra
+func (g *Group) UnmarshalJSON(content []byte) error {
+
+	type group Group
+	if err := json.Unmarshal(content, (*group)(g)); err != nil {
+		return err
+	}
+
+	extra, err := marathon.UnknownFields(content, g)
+	g.Extra = extra
+	return err
+}
+
+// MarshalJSON encodes a Group including fields kept into Extra, a zero version and versionInfo
+// are not sent
+func (g Group) MarshalJSON() ([]byte, error) {
+
+	type group Group
+	aux := struct {
+		group
+		Version     *time.Time               `json:"version,omitempty"`
+		VersionInfo *application.VersionInfo `json:"versionInfo,omitempty"`
+	}{
+		group: group(g),
+	}
+	if !g.Version.IsZero() {
+		aux.Version = &g.Version
+	}
+	if g.VersionInfo != (application.VersionInfo{}) {
+		aux.VersionInfo = &g.VersionInfo
+	}
+
+	encoded, err := json.Marshal(aux)
+	if err != nil {
+		return nil, err
+	}
+	return marathon.MergeFields(encoded, g.Extra)
+}
+
 //=== Marathon Application methods
 
 // New returns a new instance of Marathon groups implementation
@@ -72,10 +148,12 @@ func New(client *marathon.Client) *Groups {
 
 	if client != nil {
 		return &Groups{
-			client: client,
-			group:  &Group{},
-			deploy: &data.Response{},
-			fail:   &data.FailureMessage{},
+			client:  client,
+			group:   &Group{},
+			timeout: DefaultTierTimeout,
+			workers: 1,
+			deploy:  &data.Response{},
+			fail:    &data.FailureMessage{},
 		}
 	}
 	return nil
@@ -84,14 +162,30 @@ func New(client *marathon.Client) *Groups {
 // Get allows to establish the internal structures to referenced id
 func (mg *Groups) Get(id string) *Groups {
 
+	return mg.GetContext(context.Background(), id)
+}
+
+// GetContext allows to establish the internal structures to referenced id, honoring ctx
+func (mg *Groups) GetContext(ctx context.Context, id string) *Groups {
+
 	if len(id) > 0 {
 		mg.clear()
 
 		path := fmt.Sprintf("%s%s", marathon.APIGroups, utilities.DelInitialSlash(id))
 
-		if _, err := mg.client.Session.BodyAsJSON(nil).Get(path, mg.group, mg.fail); err != nil {
+		if mg.err = mg.client.Do(ctx, http.MethodGet, path, nil, nil, mg.group, mg.fail); mg.err != nil {
 			mg.clear()
 		}
+		mg.version = mg.group.Version
+	}
+	return mg
+}
+
+// Set allows to establish the internal structures to group, without sending it to server
+func (mg *Groups) Set(group *Group) *Groups {
+
+	if group != nil && len(group.ID) > 0 {
+		mg.group = group
 	}
 	return mg
 }
@@ -99,11 +193,17 @@ func (mg *Groups) Get(id string) *Groups {
 // Create allows create a Marathon group into server
 func (mg *Groups) Create(group *Group) error {
 
+	return mg.CreateContext(context.Background(), group)
+}
+
+// CreateContext allows create a Marathon group into server, honoring ctx
+func (mg *Groups) CreateContext(ctx context.Context, group *Group) error {
+
 	if mg.group != nil && len(group.ID) > 0 {
 
 		path := fmt.Sprintf("%s%s", marathon.APIGroups, utilities.DelInitialSlash(group.ID))
 
-		if _, err := mg.client.Session.BodyAsJSON(group).Post(path, mg.deploy, mg.fail); err != nil {
+		if err := mg.client.Do(ctx, http.MethodPost, path, nil, group, mg.deploy, mg.fail); err != nil {
 			return err
 		}
 		mg.group = group
@@ -115,11 +215,17 @@ func (mg *Groups) Create(group *Group) error {
 // Destroy erase a Marathon group from server
 func (mg *Groups) Destroy() error {
 
+	return mg.DestroyContext(context.Background())
+}
+
+// DestroyContext erase a Marathon group from server, honoring ctx
+func (mg *Groups) DestroyContext(ctx context.Context) error {
+
 	if mg.group != nil && len(mg.group.ID) > 0 {
 
 		path := fmt.Sprintf("%s%s", marathon.APIGroups, utilities.DelInitialSlash(mg.group.ID))
 
-		if _, err := mg.client.Session.BodyAsJSON(nil).Delete(path, mg.deploy, mg.fail); err != nil {
+		if err := mg.client.Do(ctx, http.MethodDelete, path, nil, nil, mg.deploy, mg.fail); err != nil {
 			return err
 		}
 		mg.clear()
@@ -131,14 +237,26 @@ func (mg *Groups) Destroy() error {
 // Update allows change values into Marathon group
 func (mg *Groups) Update(group *Group) error {
 
+	return mg.UpdateContext(context.Background(), group)
+}
+
+// UpdateContext allows change values into Marathon group, the whole group tree is replaced in
+// a single request, honoring ctx
+func (mg *Groups) UpdateContext(ctx context.Context, group *Group) error {
+
 	if mg.group != nil && len(group.ID) > 0 {
 
+		if err := mg.checkVersion(ctx); err != nil {
+			return err
+		}
+
 		path := fmt.Sprintf("%s%s", marathon.APIGroups, utilities.DelInitialSlash(group.ID))
 
-		if _, err := mg.client.Session.BodyAsJSON(group).Post(path, mg.deploy, mg.fail); err != nil {
+		if err := mg.client.Do(ctx, http.MethodPut, path, nil, group, mg.deploy, mg.fail); err != nil {
 			return err
 		}
 		mg.group = group
+		mg.version = mg.deploy.Version
 		return nil
 	}
 	return errors.New("group cannot be null nor empty")
@@ -147,19 +265,26 @@ func (mg *Groups) Update(group *Group) error {
 // Scale allows change instances numbers of a Marathon group filteredApps
 func (mg *Groups) Scale(instances int, force bool) error {
 
-	if mg.group != nil && len(mg.group.ID) > 0 {
-		if appClient := application.New(mg.client); appClient != nil {
+	return mg.ScaleContext(context.Background(), instances, force)
+}
 
-			callbackFunc := func(appID string) error {
+// ScaleContext allows change instances numbers of a Marathon group filteredApps, dependencies
+// first, honoring ctx
+func (mg *Groups) ScaleContext(ctx context.Context, instances int, force bool) error {
 
-				if err := appClient.Get(appID).Scale(instances, force); err != nil {
-					return err
-				}
-				return nil
+	if mg.group != nil && len(mg.group.ID) > 0 {
+		deployFunc := func(app application.AppDefinition) (string, error) {
+
+			appClient := application.New(mg.client).GetContext(ctx, app.ID)
+			if err := appClient.LastError(); err != nil {
+				return "", err
+			}
+			if err := appClient.ScaleContext(ctx, instances, force); err != nil {
+				return "", err
 			}
-			return mg.traverseGroupsWithAppID(mg.group, callbackFunc)
+			return appClient.DeploymentID(), nil
 		}
-		return fmt.Errorf("unnable to connect")
+		return mg.inOrder(ctx, false, deployFunc)
 	}
 	return errors.New("group cannot be null nor empty")
 }
@@ -167,19 +292,25 @@ func (mg *Groups) Scale(instances int, force bool) error {
 // Stop sets instances of a Marathon group filteredApps to 0
 func (mg *Groups) Stop(force bool) error {
 
-	if mg.group != nil && len(mg.group.ID) > 0 {
-		if appClient := application.New(mg.client); appClient != nil {
+	return mg.StopContext(context.Background(), force)
+}
+
+// StopContext sets instances of a Marathon group filteredApps to 0, dependent apps first, honoring ctx
+func (mg *Groups) StopContext(ctx context.Context, force bool) error {
 
-			callbackFunc := func(appID string) error {
+	if mg.group != nil && len(mg.group.ID) > 0 {
+		deployFunc := func(app application.AppDefinition) (string, error) {
 
-				if err := appClient.Get(appID).Stop(force); err != nil {
-					return err
-				}
-				return nil
+			appClient := application.New(mg.client).GetContext(ctx, app.ID)
+			if err := appClient.LastError(); err != nil {
+				return "", err
+			}
+			if err := appClient.StopContext(ctx, force); err != nil {
+				return "", err
 			}
-			return mg.traverseGroupsWithAppID(mg.group, callbackFunc)
+			return appClient.DeploymentID(), nil
 		}
-		return fmt.Errorf("unnable to connect")
+		return mg.inOrder(ctx, true, deployFunc)
 	}
 	return errors.New("group cannot be null nor empty")
 }
@@ -187,19 +318,26 @@ func (mg *Groups) Stop(force bool) error {
 // Start sets instances of a Marathon group filteredApps to a number provided
 func (mg *Groups) Start(instances int, force bool) error {
 
-	if mg.group != nil && len(mg.group.ID) > 0 {
-		if appClient := application.New(mg.client); appClient != nil {
+	return mg.StartContext(context.Background(), instances, force)
+}
+
+// StartContext sets instances of a Marathon group filteredApps to a number provided, dependencies
+// first, honoring ctx
+func (mg *Groups) StartContext(ctx context.Context, instances int, force bool) error {
 
-			callbackFunc := func(appID string) error {
+	if mg.group != nil && len(mg.group.ID) > 0 {
+		deployFunc := func(app application.AppDefinition) (string, error) {
 
-				if err := appClient.Get(appID).Start(instances, force); err != nil {
-					return err
-				}
-				return nil
+			appClient := application.New(mg.client).GetContext(ctx, app.ID)
+			if err := appClient.LastError(); err != nil {
+				return "", err
+			}
+			if err := appClient.StartContext(ctx, instances, force); err != nil {
+				return "", err
 			}
-			return mg.traverseGroupsWithAppID(mg.group, callbackFunc)
+			return appClient.DeploymentID(), nil
 		}
-		return fmt.Errorf("unnable to connect")
+		return mg.inOrder(ctx, false, deployFunc)
 	}
 	return errors.New("group cannot be null nor empty")
 }
@@ -207,19 +345,26 @@ func (mg *Groups) Start(instances int, force bool) error {
 // Restart use an endpoint to trigger restart for all filteredApps in a Marathon group
 func (mg *Groups) Restart(force bool) error {
 
-	if mg.group != nil && len(mg.group.ID) > 0 {
-		if appClient := application.New(mg.client); appClient != nil {
+	return mg.RestartContext(context.Background(), force)
+}
 
-			callbackFunc := func(appID string) error {
+// RestartContext use an endpoint to trigger restart for all filteredApps in a Marathon group,
+// dependencies first, honoring ctx
+func (mg *Groups) RestartContext(ctx context.Context, force bool) error {
 
-				if err := appClient.Get(appID).Restart(force); err != nil {
-					return err
-				}
-				return nil
+	if mg.group != nil && len(mg.group.ID) > 0 {
+		deployFunc := func(app application.AppDefinition) (string, error) {
+
+			appClient := application.New(mg.client).GetContext(ctx, app.ID)
+			if err := appClient.LastError(); err != nil {
+				return "", err
+			}
+			if err := appClient.RestartContext(ctx, force); err != nil {
+				return "", err
 			}
-			return mg.traverseGroupsWithAppID(mg.group, callbackFunc)
+			return appClient.DeploymentID(), nil
 		}
-		return fmt.Errorf("unnable to connect")
+		return mg.inOrder(ctx, false, deployFunc)
 	}
 	return errors.New("group cannot be null nor empty")
 }
@@ -230,26 +375,79 @@ func (mg *Groups) Suspend(force bool) error {
 	return mg.Stop(force)
 }
 
+// SuspendContext is an alias for StopContext
+func (mg *Groups) SuspendContext(ctx context.Context, force bool) error {
+
+	return mg.StopContext(ctx, force)
+}
+
 // Apply uses the content of mg.group.Apps to apply the configuration
 func (mg *Groups) Apply(force bool) error {
 
+	return mg.ApplyContext(context.Background(), force)
+}
+
+// ApplyContext uses the content of mg.group.Apps to apply the configuration, dependencies first,
+// honoring ctx
+func (mg *Groups) ApplyContext(ctx context.Context, force bool) error {
+
 	if mg.group != nil && len(mg.group.ID) > 0 {
-		if appClient := application.New(mg.client); appClient != nil {
+		if err := mg.checkVersion(ctx); err != nil {
+			return err
+		}
 
-			callbackFunc := func(app application.AppDefinition) error {
+		deployFunc := func(app application.AppDefinition) (string, error) {
 
-				if err := appClient.Set(app).Apply(force); err != nil {
-					return err
-				}
-				return nil
+			appClient := application.New(mg.client)
+			if err := appClient.Set(app).ApplyContext(ctx, force); err != nil {
+				return "", err
 			}
-			return mg.traverseGroupsWithAppDefinition(mg.group, callbackFunc)
+			return appClient.DeploymentID(), nil
 		}
-		return fmt.Errorf("unnable to connect")
+		err := mg.inOrder(ctx, false, deployFunc)
+
+		// Every app applied changed the group version, read it again
+		if mg.policy != marathon.Overwrite && !mg.version.IsZero() {
+			mg.version, _ = mg.currentVersion(ctx, mg.group.ID)
+		}
+		return err
 	}
 	return errors.New("group cannot be null nor empty")
 }
 
+// ApplyAndWait uses the content of mg.group.Apps to apply the configuration and waits until
+// every app deployment finish and its tasks are running and healthy
+func (mg *Groups) ApplyAndWait(force bool, timeout time.Duration) ([]*application.DeployResult, error) {
+
+	return mg.ApplyAndWaitContext(context.Background(), force, timeout)
+}
+
+// ApplyAndWaitContext uses the content of mg.group.Apps to apply the configuration and waits until
+// every app deployment finish and its tasks are running and healthy, dependencies first, honoring ctx
+func (mg *Groups) ApplyAndWaitContext(ctx context.Context, force bool, timeout time.Duration) ([]*application.DeployResult, error) {
+
+	if mg.group != nil && len(mg.group.ID) > 0 {
+
+		tiers, err := mg.group.Tiers()
+		if err != nil {
+			return nil, err
+		}
+
+		// Apps of a tier are deployed together once the previous tier is running and healthy
+		finish := time.Now().Add(timeout)
+		var results []*application.DeployResult
+		for _, tier := range tiers {
+			tierResults, err := application.ApplyAllAndWait(ctx, mg.client, tier, force, time.Until(finish))
+			results = append(results, tierResults...)
+			if err != nil {
+				return results, err
+			}
+		}
+		return results, nil
+	}
+	return nil, errors.New("group cannot be null nor empty")
+}
+
 // Load permit read group information from a file
 func (mg *Groups) Load(fileName string) *Groups {
 
@@ -260,11 +458,13 @@ func (mg *Groups) Load(fileName string) *Groups {
 	switch filepath.Ext(strings.TrimSpace(fileName)) {
 	case ".json":
 		err = utilities.LoadDataFromJSON(mg.group, fileName)
+	case ".yaml":
+		err = marathon.LoadYAML(fileName, mg.group)
 	default:
 		err = fmt.Errorf("invalid filename extension")
 	}
 
-	if err != nil {
+	if mg.err = err; err != nil {
 		mg.clear()
 	}
 	return mg
@@ -287,56 +487,38 @@ func (mg *Groups) Dump(fileName string) (err error) {
 	return errors.New("group cannot be null nor empty")
 }
 
+// LastError returns the error of last Get or Load, nil if it was successful
+func (mg *Groups) LastError() error {
+
+	return mg.err
+}
+
 // AsRaw
 func (mg *Groups) AsRaw() *Group {
 
 	return mg.group
 }
 
-// traverseGroupsWithAppID cross the group structure executing a CallBackFuncsWithAppID
+// traverseGroupsWithAppID cross the group structure executing a CallBackFuncsWithAppID once per app
 func (mg *Groups) traverseGroupsWithAppID(group *Group, callbackFunc CallBackFuncsWithAppID) (err error) {
 
 	marathon.Logger.Debug("traverseGroups: GROUP ID => %s", group.ID)
-	if len(group.Apps) > 0 {
-		for _, app := range group.Apps {
-
-			err = callbackFunc(app.ID)
-		}
-	}
-	if len(group.Groups) > 0 {
-		for _, grp := range group.Groups {
-			for _, app := range grp.Apps {
-				err = callbackFunc(app.ID)
-			}
-			err = mg.traverseGroupsWithAppID(&grp, callbackFunc)
-		}
-	}
-	return nil
+	return mg.traverse(appsOf(group), func(app application.AppDefinition) error {
+		return callbackFunc(app.ID)
+	})
 }
 
 // traverseGroupsWithAppDefinition cross the group structure executing a CallBackFuncsWithAppDef
+// once per app
 func (mg *Groups) traverseGroupsWithAppDefinition(group *Group, callbackFunc CallBackFuncsWithAppDef) (err error) {
 
 	marathon.Logger.Debug("traverseGroups: GROUP ID => %s", group.ID)
-	if len(group.Apps) > 0 {
-		for _, app := range group.Apps {
-
-			err = callbackFunc(app)
-		}
-	}
-	if len(group.Groups) > 0 {
-		for _, grp := range group.Groups {
-			for _, app := range grp.Apps {
-				err = callbackFunc(app)
-			}
-			err = mg.traverseGroupsWithAppDefinition(&grp, callbackFunc)
-		}
-	}
-	return nil
+	return mg.traverse(appsOf(group), callbackFunc)
 }
 
 // clean clear internal structures
 func (mg *Groups) clear() {
 
 	mg.group = &Group{}
+	mg.version = time.Time{}
 }
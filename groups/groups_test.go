@@ -1,14 +1,20 @@
 package groups
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
 	"github.com/dotWicho/marathon/mockserver"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"sync"
 	"testing"
+	"time"
 )
 
 func Test_NewGroups(t *testing.T) {
@@ -484,6 +490,45 @@ func TestGroups_Apply(t *testing.T) {
 	})
 }
 
+func TestGroups_ApplyAndWait(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get error when ApplyAndWait is called with group empty", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL))
+
+		// try to ApplyAndWait with empty group
+		results, err := _group.ApplyAndWait(true, time.Second)
+
+		//
+		assert.Nil(t, results)
+		assert.NotNil(t, err)
+		assert.Equal(t, "group cannot be null nor empty", err.Error())
+	})
+
+	t.Run("get results until an app timeout when is called with a valid group", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL))
+
+		// try to Get and ApplyAndWait a valid group, /infra/kong-v2 never gets tasks running
+		results, err := _group.Get("/infra").ApplyAndWait(true, time.Second)
+
+		// We get timeout error
+		assert.Equal(t, application.ErrTimeout, err)
+
+		// Check some values on results
+		assert.Len(t, results, 2)
+		assert.Equal(t, "/infra/redis", results[0].AppID)
+		assert.Equal(t, "/infra/kong-v2", results[1].AppID)
+		assert.Equal(t, 0, results[1].TasksRunning)
+	})
+}
+
 func TestGroups_Load(t *testing.T) {
 
 	// We create a Mock Server
@@ -528,6 +573,29 @@ func TestGroups_Load(t *testing.T) {
 		// Check some values on response
 		assert.Equal(t, "/infra", _group.group.ID)
 	})
+
+	t.Run("get App ref when is called with a valid YAML file", func(t *testing.T) {
+
+		// we define some vars
+		fileName := "dumpfile.yaml"
+		content := "id: /web\ndependencies: [/infra]\napps:\n- id: nginx\n  cmd: nginx\n  instances: 2\n"
+
+		// We create out file to read as YAML
+		errFile := ioutil.WriteFile(fileName, []byte(content), 0644)
+		defer os.Remove(fileName)
+
+		// We get not error
+		assert.Nil(t, errFile)
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL)).Load(fileName)
+
+		// Check some values on response
+		assert.Equal(t, "/web", _group.group.ID)
+		assert.Equal(t, []string{"/infra"}, _group.group.Dependencies)
+		assert.Equal(t, "nginx", _group.group.Apps[0].ID)
+		assert.Equal(t, 2, _group.group.Apps[0].Instances)
+	})
 }
 
 func TestGroups_Dump(t *testing.T) {
@@ -581,3 +649,359 @@ func TestGroups_Dump(t *testing.T) {
 		assert.Equal(t, groupRef, file)
 	})
 }
+
+func TestGroup_JSON(t *testing.T) {
+
+	t.Run("encode keeps fields not modeled", func(t *testing.T) {
+
+		payload := `{"id":"/infra","apps":[{"id":"/infra/cmd","cmd":"sleep 100","cpus":0.1,"mem":32,"instances":1,"upgradeStrategy":{"maximumOverCapacity":1,"minimumHealthCapacity":1},"unreachableStrategy":{"inactiveAfterSeconds":0,"expungeAfterSeconds":0},"tty":false}],"groups":[{"id":"/infra/db","apps":[],"groups":[],"pods":[],"roleEnforcementPolicy":"top"}],"pods":[],"enforceRole":true,"maintenanceWindow":"sunday"}`
+
+		group := Group{}
+		assert.Nil(t, json.Unmarshal([]byte(payload), &group))
+
+		// Unknown fields are kept at every level
+		assert.Equal(t, json.RawMessage(`"sunday"`), group.Extra["maintenanceWindow"])
+		assert.Equal(t, json.RawMessage(`"top"`), group.Groups[0].Extra["roleEnforcementPolicy"])
+		assert.Equal(t, json.RawMessage(`false`), group.Apps[0].Extra["tty"])
+
+		content, err := json.Marshal(group)
+		assert.Nil(t, err)
+
+		var expected, actual map[string]interface{}
+		_ = json.Unmarshal([]byte(payload), &expected)
+		_ = json.Unmarshal(content, &actual)
+
+		// Zero version status is not part of the payload
+		for _, group := range []interface{}{actual, actual["groups"].([]interface{})[0]} {
+			delete(group.(map[string]interface{}), "version")
+			delete(group.(map[string]interface{}), "versionInfo")
+		}
+
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestGroups_OnConflict(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("overwrite by default", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL)).Get("/moving")
+		assert.Nil(t, _group.LastError())
+		assert.False(t, _group.Version().IsZero())
+
+		assert.Nil(t, _group.Update(_group.AsRaw()))
+	})
+
+	t.Run("fail if group changed since Get", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL)).Get("/moving").OnConflict(marathon.FailOnChange)
+
+		// Someone else changed it
+		assert.True(t, marathon.IsModified(_group.Update(_group.AsRaw())))
+		assert.True(t, marathon.IsModified(_group.Apply(false)))
+	})
+}
+
+func TestGroup_Validate(t *testing.T) {
+
+	t.Run("valid group", func(t *testing.T) {
+
+		group := Group{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), &group))
+		assert.Nil(t, group.Validate())
+	})
+
+	t.Run("every problem with its path", func(t *testing.T) {
+
+		group := Group{
+			ID: "/infra",
+			Apps: []application.AppDefinition{
+				{ID: "/infra/cache", Cmd: "sleep 100", Cpus: 0.1},
+				{ID: "/other/cache", Cmd: "sleep 100", Cpus: 0.1},
+				{ID: "cache", Cmd: "sleep 100", Cpus: -1},
+			},
+			Groups: []Group{
+				{ID: "/infra/db", Apps: []application.AppDefinition{{ID: "/infra/db/pg", Cpus: 0.1}}},
+			},
+			Dependencies: []string{"/Infra"},
+		}
+
+		err := group.Validate()
+
+		assert.True(t, marathon.IsValidation(err))
+		assert.Equal(t, []string{
+			"/dependencies(0)",
+			"/apps(1)/id",
+			"/apps(2)/id",
+			"/apps(2)/cpus",
+			"/groups(0)/apps(0)/cmd",
+		}, err.(marathon.ValidationErrors).Paths())
+	})
+}
+
+func TestGroup_Tiers(t *testing.T) {
+
+	t.Run("dependencies of apps and groups, relative ones included", func(t *testing.T) {
+
+		group := Group{
+			ID:   "/shop",
+			Apps: []application.AppDefinition{{ID: "/shop/web", Dependencies: []string{"data", "/other/auth"}}},
+			Groups: []Group{
+				{ID: "/shop/data", Apps: []application.AppDefinition{{ID: "/shop/data/db"}, {ID: "cache", Dependencies: []string{"db"}}}},
+				{ID: "/shop/tools", Apps: []application.AppDefinition{{ID: "/shop/tools/admin"}}, Dependencies: []string{"/shop/web"}},
+			},
+		}
+
+		tiers, err := group.Tiers()
+
+		// We get not error, dependencies out of the tree are ignored
+		assert.Nil(t, err)
+
+		var ids [][]string
+		for _, tier := range tiers {
+			var tierIDs []string
+			for _, app := range tier {
+				tierIDs = append(tierIDs, app.ID)
+			}
+			ids = append(ids, tierIDs)
+		}
+		assert.Equal(t, [][]string{{"/shop/data/db"}, {"/shop/data/cache"}, {"/shop/web"}, {"/shop/tools/admin"}}, ids)
+	})
+
+	t.Run("apps without dependencies in a single tier", func(t *testing.T) {
+
+		group := Group{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), &group))
+
+		tiers, err := group.Tiers()
+
+		// Every app once, in the order of the tree
+		assert.Nil(t, err)
+		assert.Len(t, tiers, 1)
+		assert.Len(t, tiers[0], 5)
+		assert.Equal(t, "/infra/redis", tiers[0][0].ID)
+		assert.Equal(t, "/infra/kafka/broker-2", tiers[0][4].ID)
+	})
+
+	t.Run("error on dependency cycles", func(t *testing.T) {
+
+		group := Group{
+			ID: "/shop",
+			Apps: []application.AppDefinition{
+				{ID: "/shop/web"},
+				{ID: "/shop/api", Dependencies: []string{"/shop/db"}},
+				{ID: "/shop/db", Dependencies: []string{"/shop/cache"}},
+				{ID: "/shop/cache", Dependencies: []string{"/shop/api"}},
+			},
+		}
+
+		_, err := group.Tiers()
+
+		assert.Equal(t, &CycleError{Cycle: []string{"/shop/api", "/shop/db", "/shop/cache", "/shop/api"}}, err)
+		assert.Equal(t, "dependency cycle between apps: /shop/api -> /shop/db -> /shop/cache -> /shop/api", err.Error())
+	})
+}
+
+func TestGroups_inOrder(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	group := &Group{
+		ID: "/shop",
+		Apps: []application.AppDefinition{
+			{ID: "/shop/web", Dependencies: []string{"/shop/api"}},
+			{ID: "/shop/api", Dependencies: []string{"/shop/db"}},
+			{ID: "/shop/db"},
+			{ID: "/shop/cache"},
+		},
+	}
+
+	t.Run("dependencies first", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL)).WithTimeout(time.Second)
+		_group.group = group
+
+		var order []string
+		err := _group.inOrder(context.Background(), false, func(app application.AppDefinition) (string, error) {
+			order = append(order, app.ID)
+			return "d4b75430-8ee6-47e9-95f2-6cf297aaac00", nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"/shop/db", "/shop/cache", "/shop/api", "/shop/web"}, order)
+	})
+
+	t.Run("dependent apps first when reverse", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL))
+		_group.group = group
+
+		var order []string
+		err := _group.inOrder(context.Background(), true, func(app application.AppDefinition) (string, error) {
+			order = append(order, app.ID)
+			return "", nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"/shop/web", "/shop/api", "/shop/db", "/shop/cache"}, order)
+	})
+
+	t.Run("wait for each tier deployments", func(t *testing.T) {
+
+		// Try to create Groups, the deployment 97c136bf does not finish before the timeout
+		mockserver.ResetDeployments(mockserver.SomeDeployments)
+		_group := New(marathon.New(server.URL)).WithTimeout(time.Millisecond)
+		_group.group = group
+
+		var order []string
+		err := _group.inOrder(context.Background(), false, func(app application.AppDefinition) (string, error) {
+			order = append(order, app.ID)
+			return "97c136bf-5a28-4821-9d94-480d9fbb01c8", nil
+		})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"/shop/db", "/shop/cache"}, order)
+	})
+
+	t.Run("operations reject dependency cycles", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL))
+		_group.group = &Group{
+			ID: "/shop",
+			Apps: []application.AppDefinition{
+				{ID: "/shop/api", Dependencies: []string{"/shop/db"}},
+				{ID: "/shop/db", Dependencies: []string{"/shop"}},
+			},
+		}
+
+		var cycle *CycleError
+		assert.True(t, errors.As(_group.Scale(1, true), &cycle))
+		assert.True(t, errors.As(_group.Stop(true), &cycle))
+		assert.True(t, errors.As(_group.Apply(true), &cycle))
+	})
+}
+
+func TestGroups_traverse(t *testing.T) {
+
+	// We define some vars
+	group := &Group{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), group))
+	failing := map[string]bool{"/infra/kong-v2": true, "/infra/kafka/broker-1": true}
+
+	t.Run("every app once", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New("http://127.0.0.1:8080"))
+
+		var visited []string
+		err := _group.traverseGroupsWithAppID(group, func(appID string) error {
+			visited = append(visited, appID)
+			return nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"/infra/redis", "/infra/kong-v2", "/infra/kafka/broker-0", "/infra/kafka/broker-1", "/infra/kafka/broker-2"}, visited)
+	})
+
+	t.Run("stop on first error", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New("http://127.0.0.1:8080"))
+
+		var visited []string
+		err := _group.traverseGroupsWithAppDefinition(group, func(app application.AppDefinition) error {
+			visited = append(visited, app.ID)
+			if failing[app.ID] {
+				return errors.New("failed")
+			}
+			return nil
+		})
+
+		assert.Equal(t, AppErrors{{AppID: "/infra/kong-v2", Err: errors.New("failed")}}, err)
+		assert.Equal(t, "/infra/kong-v2: failed", err.Error())
+		assert.Len(t, visited, 2)
+	})
+
+	t.Run("continue on error collecting every failure", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New("http://127.0.0.1:8080")).OnError(ContinueOnError)
+
+		var visited []string
+		err := _group.traverseGroupsWithAppID(group, func(appID string) error {
+			visited = append(visited, appID)
+			if failing[appID] {
+				return fmt.Errorf("unable to scale: %w", marathon.ErrModified)
+			}
+			return nil
+		})
+
+		assert.Len(t, visited, 5)
+		assert.Equal(t, []string{"/infra/kong-v2", "/infra/kafka/broker-1"}, err.(AppErrors).IDs())
+		assert.Equal(t, "2 apps failed: /infra/kong-v2: unable to scale: definition changed since it was read; /infra/kafka/broker-1: unable to scale: definition changed since it was read", err.Error())
+		assert.True(t, errors.Is(err, marathon.ErrModified))
+	})
+
+	t.Run("bounded workers", func(t *testing.T) {
+
+		// Try to create Groups
+		_group := New(marathon.New("http://127.0.0.1:8080")).OnError(ContinueOnError).Workers(2)
+
+		var mutex sync.Mutex
+		running, most, visited := 0, 0, 0
+		err := _group.traverseGroupsWithAppID(group, func(appID string) error {
+			mutex.Lock()
+			running, visited = running+1, visited+1
+			if running > most {
+				most = running
+			}
+			mutex.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			if failing[appID] {
+				return errors.New("failed")
+			}
+			return nil
+		})
+
+		// Failures keep the order of the apps
+		assert.Equal(t, []string{"/infra/kong-v2", "/infra/kafka/broker-1"}, err.(AppErrors).IDs())
+		assert.Equal(t, 5, visited)
+		assert.Equal(t, 2, most)
+	})
+
+	t.Run("group operations report failed apps", func(t *testing.T) {
+
+		// We create a Mock Server
+		server := mockserver.MockServer()
+		defer server.Close()
+
+		// Try to create Groups
+		_group := New(marathon.New(server.URL)).OnError(ContinueOnError).Workers(4)
+		_group.group = &Group{
+			ID:   "/infra",
+			Apps: []application.AppDefinition{{ID: "/infra/redis-1"}, {ID: "/infra/missing"}, {ID: "/infra/broker-0"}},
+		}
+
+		err := _group.Restart(true)
+
+		assert.Equal(t, []string{"/infra/missing"}, err.(AppErrors).IDs())
+		assert.True(t, marathon.IsNotFound(err))
+	})
+}
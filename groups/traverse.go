@@ -0,0 +1,178 @@
+package groups
+
+import (
+	"fmt"
+	"github.com/dotWicho/marathon/application"
+	"strings"
+	"sync"
+)
+
+// ErrorPolicy tells group operations what to do when an app fails
+type ErrorPolicy int
+
+const (
+	// StopOnError does not start other apps once an app fails, this is the default
+	StopOnError ErrorPolicy = iota
+	// ContinueOnError goes on with the other apps and reports every failure
+	ContinueOnError
+)
+
+// String returns the name of the policy
+func (ep ErrorPolicy) String() string {
+
+	switch ep {
+	case StopOnError:
+		return "stop"
+	case ContinueOnError:
+		return "continue"
+	}
+	return fmt.Sprintf("ErrorPolicy(%d)", int(ep))
+}
+
+// AppError is the failure of an operation on an app of a group
+type AppError struct {
+	AppID string
+	Err   error
+}
+
+// Error returns the app id with its error
+func (ae AppError) Error() string {
+
+	return fmt.Sprintf("%s: %v", ae.AppID, ae.Err)
+}
+
+// Unwrap returns the error of the app
+func (ae AppError) Unwrap() error {
+
+	return ae.Err
+}
+
+// AppErrors holds the failures of an operation on the apps of a group, in the order of the apps
+type AppErrors []AppError
+
+// Error returns every failure
+func (ae AppErrors) Error() string {
+
+	failures := make([]string, 0, len(ae))
+	for _, failure := range ae {
+		failures = append(failures, failure.Error())
+	}
+	if len(ae) == 1 {
+		return failures[0]
+	}
+	return fmt.Sprintf("%d apps failed: %s", len(ae), strings.Join(failures, "; "))
+}
+
+// Unwrap returns the first failure, so errors.Is and errors.As match it
+func (ae AppErrors) Unwrap() error {
+
+	if len(ae) > 0 {
+		return ae[0]
+	}
+	return nil
+}
+
+// IDs returns the id of every app failed
+func (ae AppErrors) IDs() []string {
+
+	ids := make([]string, 0, len(ae))
+	for _, failure := range ae {
+		ids = append(ids, failure.AppID)
+	}
+	return ids
+}
+
+// Err returns nil if there are no failures, the failures otherwise
+func (ae AppErrors) Err() error {
+
+	if len(ae) == 0 {
+		return nil
+	}
+	return ae
+}
+
+// OnError sets what group operations do when an app fails
+func (mg *Groups) OnError(policy ErrorPolicy) *Groups {
+
+	mg.onError = policy
+	return mg
+}
+
+// Workers sets how many apps group operations change at the same time, 1 by default
+func (mg *Groups) Workers(workers int) *Groups {
+
+	mg.workers = workers
+	return mg
+}
+
+// traverse runs callbackFunc on every app with up to mg.workers at the same time, failures are
+// returned as AppErrors; with StopOnError no other app is started once one fails
+func (mg *Groups) traverse(apps []application.AppDefinition, callbackFunc CallBackFuncsWithAppDef) error {
+
+	failures := make([]error, len(apps))
+
+	workers := mg.workers
+	if workers > len(apps) {
+		workers = len(apps)
+	}
+
+	if workers <= 1 {
+		for index, app := range apps {
+			if failures[index] = callbackFunc(app); failures[index] != nil && mg.onError == StopOnError {
+				break
+			}
+		}
+	} else {
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		failed := false
+
+		jobs := make(chan int)
+		for worker := 0; worker < workers; worker++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for index := range jobs {
+					if err := callbackFunc(apps[index]); err != nil {
+						mutex.Lock()
+						failures[index], failed = err, true
+						mutex.Unlock()
+					}
+				}
+			}()
+		}
+
+		for index := range apps {
+			mutex.Lock()
+			stop := failed && mg.onError == StopOnError
+			mutex.Unlock()
+			if stop {
+				break
+			}
+			jobs <- index
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	var errs AppErrors
+	for index, err := range failures {
+		if err != nil {
+			errs = append(errs, AppError{AppID: apps[index].ID, Err: err})
+		}
+	}
+	return errs.Err()
+}
+
+// appsOf returns every app of the group tree once, with ids resolved against the groups holding them
+func appsOf(group *Group) []application.AppDefinition {
+
+	var nodes []dependentApp
+	collectApps(group, "", nil, &nodes)
+
+	apps := make([]application.AppDefinition, 0, len(nodes))
+	for _, node := range nodes {
+		apps = append(apps, node.app)
+	}
+	return apps
+}
@@ -0,0 +1,72 @@
+package groups
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"path"
+	"strings"
+)
+
+// Validate checks the group, its apps and every group inside before sending them to Marathon
+// server, it returns every problem found as marathon.ValidationErrors, or nil if all are valid
+func (g *Group) Validate() error {
+
+	return g.validate("").Err()
+}
+
+// validate returns every problem of the group found below prefix
+func (g *Group) validate(prefix string) marathon.ValidationErrors {
+
+	var errs marathon.ValidationErrors
+
+	if err := application.ValidatePath(g.ID); err != nil {
+		errs.Add(prefix+"/id", "%v", err)
+	}
+	for index, dependency := range g.Dependencies {
+		if err := application.ValidatePath(dependency); err != nil {
+			errs.Add(fmt.Sprintf("%s/dependencies(%d)", prefix, index), "%v", err)
+		}
+	}
+
+	ids := make(map[string]bool)
+	child := func(at, id string) {
+		if !strings.HasPrefix(id, "/") {
+			id = path.Join(g.ID, id)
+		} else if path.Dir(id) != path.Clean(g.ID) {
+			errs.Add(at+"/id", "%s is not a child of group %s", id, g.ID)
+		}
+		if ids[id] {
+			errs.Add(at+"/id", "duplicated id %s", id)
+		}
+		ids[id] = true
+	}
+
+	for index := range g.Apps {
+		at := fmt.Sprintf("%s/apps(%d)", prefix, index)
+		child(at, g.Apps[index].ID)
+		errs.Merge(prefixed(at, g.Apps[index].Validate()))
+	}
+	for index := range g.Groups {
+		at := fmt.Sprintf("%s/groups(%d)", prefix, index)
+		child(at, g.Groups[index].ID)
+		errs.Merge(g.Groups[index].validate(at))
+	}
+	return errs
+}
+
+// prefixed returns the ValidationErrors of err with prefix added to every path
+func prefixed(prefix string, err error) marathon.ValidationErrors {
+
+	var invalid marathon.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+
+	errs := make(marathon.ValidationErrors, 0, len(invalid))
+	for _, item := range invalid {
+		errs.Add(prefix+item.Path, "%s", item.Message)
+	}
+	return errs
+}
@@ -4,15 +4,23 @@ import "time"
 
 // Healthcheck represents configuration of a health check of a Marathon task
 type Healthcheck struct {
-	GracePeriodSeconds     int    `json:"gracePeriodSeconds"`
-	IntervalSeconds        int    `json:"intervalSeconds"`
-	MaxConsecutiveFailures int    `json:"maxConsecutiveFailures"`
-	Path                   string `json:"path"`
-	PortIndex              int    `json:"portIndex"`
-	Protocol               string `json:"protocol"`
-	IPProtocol             string `json:"ipProtocol"`
-	TimeoutSeconds         int    `json:"timeoutSeconds"`
-	DelaySeconds           int    `json:"delaySeconds"`
+	GracePeriodSeconds     int                 `json:"gracePeriodSeconds"`
+	IntervalSeconds        int                 `json:"intervalSeconds"`
+	MaxConsecutiveFailures int                 `json:"maxConsecutiveFailures"`
+	Path                   string              `json:"path"`
+	PortIndex              int                 `json:"portIndex"`
+	Port                   int                 `json:"port,omitempty"`
+	Protocol               string              `json:"protocol"`
+	IPProtocol             string              `json:"ipProtocol"`
+	TimeoutSeconds         int                 `json:"timeoutSeconds"`
+	DelaySeconds           int                 `json:"delaySeconds"`
+	IgnoreHTTP1xx          bool                `json:"ignoreHttp1xx,omitempty"`
+	Command                *HealthcheckCommand `json:"command,omitempty"`
+}
+
+// HealthcheckCommand is the command run by COMMAND and MESOS_COMMAND health checks
+type HealthcheckCommand struct {
+	Value string `json:"value"`
 }
 
 // HealthcheckResult represents response of a Marathon health check
@@ -21,5 +29,8 @@ type HealthcheckResult struct {
 	ConsecutiveFailures int       `json:"consecutiveFailures"`
 	FirstSuccess        time.Time `json:"firstSuccess"`
 	InstanceID          string    `json:"instanceId"`
+	TaskID              string    `json:"taskId,omitempty"`
 	LastSuccess         time.Time `json:"lastSuccess"`
+	LastFailure         time.Time `json:"lastFailure,omitempty"`
+	LastFailureCause    string    `json:"lastFailureCause,omitempty"`
 }
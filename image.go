@@ -0,0 +1,191 @@
+package marathon
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultRegistry is the registry used by Docker when an image has none
+const DefaultRegistry = "docker.io"
+
+var (
+	// hostComponent is the syntax of every component of a registry host name
+	hostComponent = regexp.MustCompile(`^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])$`)
+	// pathComponent is the syntax of every component of a repository path
+	pathComponent = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*$`)
+	// imageTag is the syntax of a tag
+	imageTag = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	// imageDigest is the syntax of a digest
+	imageDigest = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+)
+
+// ImageReference is a Docker image reference: [registry[:port]/]repository[:tag][@digest]
+type ImageReference struct {
+	// Registry host, empty for Docker Hub
+	Registry string
+	// Port of the registry, 0 if it has none
+	Port int
+	// Repository path, e.g. redis, library/nginx or infra/redis-ha
+	Repository string
+	// Tag, empty if the image has none
+	Tag string
+	// Digest, e.g. sha256:4bb6..., empty if the image has none
+	Digest string
+}
+
+// ParseImage returns the ImageReference of image, or an error if image is not a valid reference
+func ParseImage(image string) (*ImageReference, error) {
+
+	if len(image) == 0 {
+		return nil, fmt.Errorf("image reference cannot be empty")
+	}
+
+	reference := &ImageReference{}
+	remainder := image
+
+	if at := strings.Index(remainder, "@"); at >= 0 {
+		reference.Digest = remainder[at+1:]
+		remainder = remainder[:at]
+		if !imageDigest.MatchString(reference.Digest) {
+			return nil, fmt.Errorf("invalid digest %q of image %s", reference.Digest, image)
+		}
+	}
+
+	// The first component is a registry if it looks like a host: it has a dot, a port or it is localhost
+	if slash := strings.Index(remainder, "/"); slash >= 0 {
+		host := remainder[:slash]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			if err := reference.setHost(host); err != nil {
+				return nil, fmt.Errorf("invalid registry of image %s: %v", image, err)
+			}
+			remainder = remainder[slash+1:]
+		}
+	}
+
+	if colon := strings.LastIndex(remainder, ":"); colon >= 0 && !strings.Contains(remainder[colon:], "/") {
+		reference.Tag = remainder[colon+1:]
+		remainder = remainder[:colon]
+		if !imageTag.MatchString(reference.Tag) {
+			return nil, fmt.Errorf("invalid tag %q of image %s", reference.Tag, image)
+		}
+	}
+
+	for _, component := range strings.Split(remainder, "/") {
+		if !pathComponent.MatchString(component) {
+			return nil, fmt.Errorf("invalid repository %q of image %s", remainder, image)
+		}
+	}
+	reference.Repository = remainder
+
+	return reference, nil
+}
+
+// setHost sets Registry and Port from host, a registry host name with an optional port
+func (ir *ImageReference) setHost(host string) error {
+
+	name := host
+	if colon := strings.LastIndex(host, ":"); colon >= 0 {
+		port, err := strconv.Atoi(host[colon+1:])
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port of %s", host)
+		}
+		name, ir.Port = host[:colon], port
+	}
+	for _, component := range strings.Split(name, ".") {
+		if !hostComponent.MatchString(component) {
+			return fmt.Errorf("invalid host %s", host)
+		}
+	}
+	ir.Registry = name
+	return nil
+}
+
+// Host returns the registry with its port, empty for Docker Hub
+func (ir ImageReference) Host() string {
+
+	if ir.Port > 0 {
+		return fmt.Sprintf("%s:%d", ir.Registry, ir.Port)
+	}
+	return ir.Registry
+}
+
+// Name returns the image without tag nor digest: [registry[:port]/]repository
+func (ir ImageReference) Name() string {
+
+	if host := ir.Host(); len(host) > 0 {
+		return host + "/" + ir.Repository
+	}
+	return ir.Repository
+}
+
+// String formats the image reference
+func (ir ImageReference) String() string {
+
+	image := ir.Name()
+	if len(ir.Tag) > 0 {
+		image += ":" + ir.Tag
+	}
+	if len(ir.Digest) > 0 {
+		image += "@" + ir.Digest
+	}
+	return image
+}
+
+// Normalized returns the fully qualified image reference used by Docker: the default registry,
+// library/ for official images and the latest tag if it has neither tag nor digest
+func (ir ImageReference) Normalized() ImageReference {
+
+	if len(ir.Registry) == 0 || (ir.Registry == "index.docker.io" && ir.Port == 0) {
+		ir.Registry = DefaultRegistry
+	}
+	if ir.Registry == DefaultRegistry && ir.Port == 0 && !strings.Contains(ir.Repository, "/") {
+		ir.Repository = "library/" + ir.Repository
+	}
+	if len(ir.Tag) == 0 && len(ir.Digest) == 0 {
+		ir.Tag = "latest"
+	}
+	return ir
+}
+
+// Equal returns true if both references point to the same image once normalized
+func (ir ImageReference) Equal(other ImageReference) bool {
+
+	return ir.Normalized() == other.Normalized()
+}
+
+// WithTag returns the reference with tag and without digest, as a digest would override the tag
+func (ir ImageReference) WithTag(tag string) (ImageReference, error) {
+
+	if !imageTag.MatchString(tag) {
+		return ir, fmt.Errorf("invalid tag %q", tag)
+	}
+	ir.Tag, ir.Digest = tag, ""
+	return ir, nil
+}
+
+// WithDigest returns the reference pinned to digest, the tag is kept as informative
+func (ir ImageReference) WithDigest(digest string) (ImageReference, error) {
+
+	if !imageDigest.MatchString(digest) {
+		return ir, fmt.Errorf("invalid digest %q", digest)
+	}
+	ir.Digest = digest
+	return ir, nil
+}
+
+// WithRegistry returns the reference on registry (host name with an optional port), an empty
+// registry means Docker Hub
+func (ir ImageReference) WithRegistry(registry string) (ImageReference, error) {
+
+	changed := ir
+	changed.Registry, changed.Port = "", 0
+	if len(registry) == 0 {
+		return changed, nil
+	}
+	if err := changed.setHost(registry); err != nil {
+		return ir, err
+	}
+	return changed, nil
+}
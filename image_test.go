@@ -0,0 +1,112 @@
+package marathon
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseImage(t *testing.T) {
+
+	// We define some vars
+	digest := "sha256:4bb6e9e1d1a2e5f4e5d6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8"
+
+	t.Run("valid references", func(t *testing.T) {
+
+		for image, expected := range map[string]ImageReference{
+			"redis":                              {Repository: "redis"},
+			"redis:6":                            {Repository: "redis", Tag: "6"},
+			"library/nginx":                      {Repository: "library/nginx"},
+			"docker.io/redis-ha:5.0.5":           {Registry: "docker.io", Repository: "redis-ha", Tag: "5.0.5"},
+			"localhost:5000/infra/redis":         {Registry: "localhost", Port: 5000, Repository: "infra/redis"},
+			"localhost/redis":                    {Registry: "localhost", Repository: "redis"},
+			"registry.local:443/a/b/c:1.0-rc_1":  {Registry: "registry.local", Port: 443, Repository: "a/b/c", Tag: "1.0-rc_1"},
+			"nginx@" + digest:                    {Repository: "nginx", Digest: digest},
+			"quay.io/coreos/etcd:v3.4@" + digest: {Registry: "quay.io", Repository: "coreos/etcd", Tag: "v3.4", Digest: digest},
+		} {
+			reference, err := ParseImage(image)
+
+			// We get not error and the same image formatted
+			assert.Nil(t, err, image)
+			assert.Equal(t, expected, *reference, image)
+			assert.Equal(t, image, reference.String())
+		}
+	})
+
+	t.Run("invalid references", func(t *testing.T) {
+
+		for _, image := range []string{
+			"",
+			"Redis",
+			"redis:",
+			"redis:-bad",
+			"redis@sha256:xyz",
+			"registry.local:99999/redis",
+			"-bad.io/redis",
+			"infra//redis",
+			"redis ha",
+		} {
+			_, err := ParseImage(image)
+			assert.NotNil(t, err, image)
+		}
+	})
+}
+
+func TestImageReference_Normalized(t *testing.T) {
+
+	redis, _ := ParseImage("redis")
+	hub, _ := ParseImage("docker.io/library/redis:latest")
+	other, _ := ParseImage("quay.io/redis")
+
+	assert.Equal(t, "docker.io/library/redis:latest", redis.Normalized().String())
+	assert.True(t, redis.Equal(*hub))
+	assert.False(t, redis.Equal(*other))
+	assert.Equal(t, "quay.io/redis:latest", other.Normalized().String())
+}
+
+func TestImageReference_With(t *testing.T) {
+
+	// We define some vars
+	digest := "sha256:4bb6e9e1d1a2e5f4e5d6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8"
+	image, _ := ParseImage("docker.io/redis-ha:5.0.5")
+
+	t.Run("change tag", func(t *testing.T) {
+
+		pinned, _ := image.WithDigest(digest)
+		changed, err := pinned.WithTag("6.0")
+
+		// The digest is dropped with the old tag
+		assert.Nil(t, err)
+		assert.Equal(t, "docker.io/redis-ha:6.0", changed.String())
+
+		_, err = image.WithTag("bad tag")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("change digest", func(t *testing.T) {
+
+		changed, err := image.WithDigest(digest)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "docker.io/redis-ha:5.0.5@"+digest, changed.String())
+
+		_, err = image.WithDigest("md5:1234")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("change registry", func(t *testing.T) {
+
+		changed, err := image.WithRegistry("registry.local:5000")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "registry.local:5000/redis-ha:5.0.5", changed.String())
+		assert.Equal(t, "registry.local:5000", changed.Host())
+
+		hub, err := changed.WithRegistry("")
+		assert.Nil(t, err)
+		assert.Equal(t, "redis-ha:5.0.5", hub.String())
+
+		unchanged, err := image.WithRegistry("bad_host:1")
+		assert.NotNil(t, err)
+		assert.Equal(t, *image, unchanged)
+	})
+}
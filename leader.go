@@ -1,6 +1,42 @@
 package marathon
 
+import (
+	"context"
+	"net/http"
+)
+
 // Leader Marathon server leader
 type Leader struct {
 	Leader string `json:"leader"`
 }
+
+// Leader fetches the Marathon server currently elected as leader
+func (mc *Client) Leader() (*Leader, error) {
+
+	return mc.LeaderContext(context.Background())
+}
+
+// LeaderContext fetches the Marathon server currently elected as leader, honoring ctx
+func (mc *Client) LeaderContext(ctx context.Context) (*Leader, error) {
+
+	leader := &Leader{}
+	if err := mc.Do(ctx, http.MethodGet, APILeader, nil, nil, leader, mc.fail); err != nil {
+		return nil, err
+	}
+	return leader, nil
+}
+
+// AbdicateLeader makes the current leader abdicate, triggering a new leader election
+func (mc *Client) AbdicateLeader() error {
+
+	return mc.AbdicateLeaderContext(context.Background())
+}
+
+// AbdicateLeaderContext makes the current leader abdicate, honoring ctx
+func (mc *Client) AbdicateLeaderContext(ctx context.Context) error {
+
+	if err := mc.Do(ctx, http.MethodDelete, APILeader, nil, nil, nil, mc.fail); err != nil {
+		return err
+	}
+	return nil
+}
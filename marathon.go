@@ -1,11 +1,15 @@
 package marathon
 
 import (
+	"context"
 	"fmt"
 	"github.com/dotWicho/logger"
 	"github.com/dotWicho/marathon/data"
 	"github.com/dotWicho/requist"
+	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -18,12 +22,31 @@ type client interface {
 	Connect(baseURL string)
 	StatusCode() int
 	CheckConnection() error
+	CheckConnectionContext(ctx context.Context) error
+	Do(ctx context.Context, method, path string, params url.Values, body, success, failure interface{}) error
+
+	// Marathon cluster interface
+	Endpoints() []string
+	Endpoint() string
+	Discover() error
+	DiscoverContext(ctx context.Context) error
 	SetTimeout(timeout time.Duration)
 	SetBasicAuth(username, password string)
 
+	// Marathon Metrics interface
+	Metrics() (*Metrics, error)
+	MetricsContext(ctx context.Context) (*Metrics, error)
+
 	// Marathon Info interface
+	Info() (*data.Info, error)
+	InfoContext(ctx context.Context) (*data.Info, error)
+	Plugins() (*Plugins, error)
+	PluginsContext(ctx context.Context) (*Plugins, error)
+	Leader() (*Leader, error)
+	LeaderContext(ctx context.Context) (*Leader, error)
+	AbdicateLeader() error
+	AbdicateLeaderContext(ctx context.Context) error
 	Version() string
-	Leader() string
 	Framework() string
 	Zookeeper() string
 }
@@ -42,11 +65,21 @@ type Client struct {
 	//
 	auth    string
 	baseURL string
+	members []string
+
+	//
+	http       *http.Client
+	statusCode int
+	mutex      sync.Mutex
 }
 
-// New returns a new Client given a Marathon server base url
+// New returns a new Client given a Marathon server base url, or a comma separated list of them
 func New(base string) *Client {
 
+	if strings.Contains(base, ",") {
+		return NewWithEndpoints(strings.Split(base, ","))
+	}
+
 	Logger.Debug("Creating Marathon Client with baseURL = %s", base)
 	baseURL, err := url.Parse(base)
 	if len(base) == 0 || err != nil {
@@ -87,6 +120,7 @@ func (mc *Client) New(base *url.URL) *Client {
 	if marathon.Session != nil {
 		requist.Logger = Logger
 		marathon.baseURL = base.String()
+		marathon.members = []string{marathon.baseURL}
 		marathon.info = &data.Info{}
 		marathon.fail = &data.FailureMessage{}
 
@@ -110,24 +144,41 @@ func (mc *Client) New(base *url.URL) *Client {
 // Connect sets baseURL and prepares the Client with this
 func (mc *Client) Connect(baseURL string) {
 	mc.Session = requist.New(baseURL)
+	if mc.Session != nil {
+		mc.baseURL = baseURL
+		mc.members = []string{baseURL}
+	}
 }
 
 // StatusCode returns last responseCode
 func (mc *Client) StatusCode() int {
-	return mc.Session.StatusCode()
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return mc.statusCode
 }
 
 // CheckConnection send a request to check Marathon server connectivity
 func (mc *Client) CheckConnection() error {
 
-	if _, err := mc.Session.Get(APIPing, nil, nil); err != nil {
+	return mc.CheckConnectionContext(context.Background())
+}
+
+// CheckConnectionContext send a request to check Marathon server connectivity, honoring ctx
+func (mc *Client) CheckConnectionContext(ctx context.Context) error {
+
+	if err := mc.Do(ctx, http.MethodGet, APIPing, nil, nil, nil, nil); err != nil {
 		Logger.Debug("CheckConnection unable to connect to Marathon server")
-		return fmt.Errorf("unable to connect to Marathon server %s", mc.baseURL)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("unable to connect to Marathon server %s: %w", mc.Endpoint(), err)
 	}
 	if mc.StatusCode() == 200 {
 		Logger.Debug("CheckConnection successful")
-		if _, err := mc.Session.Get(APIInfo, mc.info, mc.fail); err != nil {
-			return fmt.Errorf("unable to get info from Marathon server %s", mc.baseURL)
+		if err := mc.Do(ctx, http.MethodGet, APIInfo, nil, nil, mc.info, mc.fail); err != nil {
+			return fmt.Errorf("unable to get info from Marathon server %s: %w", mc.Endpoint(), err)
 		}
 		Logger.Debug("CheckConnection: Marathon version = %s", mc.info.Version)
 	}
@@ -139,6 +190,7 @@ func (mc *Client) SetTimeout(timeout time.Duration) {
 
 	mc.timeout = timeout
 	mc.Session.SetClientTimeout(mc.timeout)
+	mc.httpClient().Timeout = mc.timeout
 }
 
 // SetBasicAuth used if we need to set login parameters
@@ -150,16 +202,27 @@ func (mc *Client) SetBasicAuth(username, password string) {
 
 //=== Marathon Info interface definitions ===
 
-// MarathonVersion returns version of Marathon
-func (mc *Client) Version() string {
+// Info fetches Marathon server info, refreshing values returned by Version, Framework and Zookeeper
+func (mc *Client) Info() (*data.Info, error) {
 
-	return mc.info.Version
+	return mc.InfoContext(context.Background())
 }
 
-// MarathonLeader returns actual Marathon leader server
-func (mc *Client) Leader() string {
+// InfoContext fetches Marathon server info, honoring ctx
+func (mc *Client) InfoContext(ctx context.Context) (*data.Info, error) {
 
-	return mc.info.Leader
+	info := &data.Info{}
+	if err := mc.Do(ctx, http.MethodGet, APIInfo, nil, nil, info, mc.fail); err != nil {
+		return nil, err
+	}
+	mc.info = info
+	return info, nil
+}
+
+// MarathonVersion returns version of Marathon
+func (mc *Client) Version() string {
+
+	return mc.info.Version
 }
 
 // MarathonFramework returns the id of this Marathon on Mesos
@@ -1,8 +1,11 @@
 package marathon
 
 import (
+	"context"
+	"github.com/dotWicho/marathon/data"
 	"github.com/dotWicho/marathon/mockserver"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"net/url"
 	"testing"
 	"time"
@@ -202,10 +205,144 @@ func TestClient_CheckConnection(t *testing.T) {
 
 	// Check some values
 	assert.Equal(t, "v1.0.0", _client.Version())
-	assert.Equal(t, "127.0.0.10:8080", _client.Leader())
+	assert.Equal(t, "127.0.0.10:8080", _client.info.Leader)
 	assert.Equal(t, "97c136bf-5a28-4821-9d94-480d9fbb01c8", _client.Framework())
 	assert.Equal(t, "127.0.0.10:2181", _client.Zookeeper())
 
+	// Leader asks the server for the one elected now
+	leader, err := _client.Leader()
+	assert.Nil(t, err)
+	assert.Equal(t, server.Listener.Addr().String(), leader.Leader)
+}
+
+func TestClient_Info(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Try to create Client
+	_client := New(server.URL)
+
+	// Fire up Info
+	info, err := _client.Info()
+
+	// We get nil error and info is refreshed
+	assert.Nil(t, err)
+	assert.Equal(t, "mock_marathon", info.Name)
+	assert.Equal(t, "127.0.0.10:8080", info.Leader)
+	assert.Equal(t, "v1.0.0", _client.Version())
+}
+
+func TestClient_Plugins(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Fire up Plugins
+	plugins, err := New(server.URL).Plugins()
+
+	// We get nil error and loaded plugins
+	assert.Nil(t, err)
+	assert.Len(t, plugins.Plugins, 1)
+	assert.Equal(t, "1.2.3", plugins.Plugin("auth-plugin").Info.Version)
+	assert.Nil(t, plugins.Plugin("unknown"))
+}
+
+func TestClient_Leader(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get live leader", func(t *testing.T) {
+
+		// Fire up Leader
+		leader, err := New(server.URL).Leader()
+
+		// We get the server answering
+		assert.Nil(t, err)
+		assert.Equal(t, server.Listener.Addr().String(), leader.Leader)
+	})
+
+	t.Run("abdicate leader", func(t *testing.T) {
+
+		// Fire up AbdicateLeader
+		assert.Nil(t, New(server.URL).AbdicateLeader())
+	})
+
+	t.Run("error if server is unreachable", func(t *testing.T) {
+
+		// Fire up Leader
+		leader, err := New("http://127.0.0.1:1").Leader()
+
+		// We get an error
+		assert.NotNil(t, err)
+		assert.Nil(t, leader)
+	})
+}
+
+func TestClient_CheckConnectionContext(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Try to create Client
+	_client := New(server.URL)
+
+	// We cancel our context before fire up
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Fire up CheckConnectionContext
+	err := _client.CheckConnectionContext(ctx)
+
+	// We get context error
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestClient_Do(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("decode response on valid request", func(t *testing.T) {
+
+		// Try to create Client
+		_client := New(server.URL)
+
+		// We define some vars
+		info := &data.Info{}
+
+		// Fire up Do
+		err := _client.Do(context.Background(), http.MethodGet, APIInfo, nil, nil, info, nil)
+
+		// We get nil error
+		assert.Nil(t, err)
+
+		// Check some values
+		assert.Equal(t, http.StatusOK, _client.StatusCode())
+		assert.Equal(t, "v1.0.0", info.Version)
+	})
+
+	t.Run("get error when deadline is exceeded", func(t *testing.T) {
+
+		// Try to create Client
+		_client := New(server.URL)
+
+		// We define an already expired deadline
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-1*time.Second))
+		defer cancel()
+
+		// Fire up Do
+		err := _client.Do(ctx, http.MethodGet, APIInfo, nil, nil, &data.Info{}, nil)
+
+		// We get context error
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
 }
 
 func TestClient_SetTimeout(t *testing.T) {
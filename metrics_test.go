@@ -0,0 +1,93 @@
+package marathon
+
+import (
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClient_Metrics(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get Metrics of a valid server", func(t *testing.T) {
+
+		// Try to fetch Metrics
+		metrics, err := New(server.URL).Metrics()
+
+		// err must be nil
+		assert.Nil(t, err)
+		assert.NotNil(t, metrics)
+		assert.Equal(t, "4.0.0", metrics.Version)
+
+		// Every kind is parsed by name
+		assert.Len(t, metrics.Counters, 3)
+		assert.Len(t, metrics.Gauges, 3)
+		assert.Len(t, metrics.Histograms, 1)
+		assert.Len(t, metrics.Meters, 1)
+		assert.Len(t, metrics.Timers, 1)
+	})
+
+	t.Run("error if server is unreachable", func(t *testing.T) {
+
+		// Try to fetch Metrics
+		metrics, err := New("http://127.0.0.1:1").Metrics()
+
+		// err must be not nil
+		assert.NotNil(t, err)
+		assert.Nil(t, metrics)
+	})
+}
+
+func TestMetrics_Accessors(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	metrics, err := New(server.URL).Metrics()
+	assert.Nil(t, err)
+
+	t.Run("typed accessors", func(t *testing.T) {
+
+		count, ok := metrics.Counter("marathon.mesos.offers.used.counter")
+		assert.True(t, ok)
+		assert.Equal(t, int64(12), count)
+
+		_, ok = metrics.Counter("marathon.unknown.counter")
+		assert.False(t, ok)
+
+		value, ok := metrics.Gauge("marathon.jvm.memory.heap.usage.gauge")
+		assert.True(t, ok)
+		assert.Equal(t, 0.42, value)
+
+		// Non numeric gauges are kept as 0
+		value, ok = metrics.Gauge("marathon.jvm.threads.deadlocks.gauge")
+		assert.True(t, ok)
+		assert.Equal(t, float64(0), value)
+
+		assert.Equal(t, 5.5, metrics.Histogram("marathon.http.requests.size.histogram.bytes").Mean)
+		assert.Equal(t, 0.1, metrics.Meter("marathon.http.responses.5xx.rate.meter").M1Rate)
+		assert.Equal(t, "seconds", metrics.Timer("marathon.http.requests.duration.timer.seconds").DurationUnits)
+		assert.Nil(t, metrics.Histogram("marathon.unknown"))
+		assert.Nil(t, metrics.Meter("marathon.unknown"))
+		assert.Nil(t, metrics.Timer("marathon.unknown"))
+	})
+
+	t.Run("lookup by prefix", func(t *testing.T) {
+
+		assert.Len(t, metrics.CountersByPrefix("marathon.mesos.offers."), 2)
+		assert.Len(t, metrics.GaugesByPrefix("marathon.jvm."), 2)
+		assert.Len(t, metrics.HistogramsByPrefix("marathon.http."), 1)
+		assert.Len(t, metrics.MetersByPrefix("marathon.http."), 1)
+		assert.Len(t, metrics.TimersByPrefix("marathon.mesos."), 0)
+
+		assert.Equal(t, []string{
+			"marathon.http.requests.duration.timer.seconds",
+			"marathon.http.requests.size.histogram.bytes",
+			"marathon.http.responses.5xx.rate.meter",
+		}, metrics.Names("marathon.http."))
+	})
+}
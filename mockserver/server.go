@@ -2,9 +2,13 @@ package mockserver
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/dotWicho/marathon/data"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -65,7 +69,12 @@ var AppsArray = `{
    "upgradeStrategy": { "maximumOverCapacity": 0, "minimumHealthCapacity": 0 },
    "killSelection": "YOUNGEST_FIRST",
    "unreachableStrategy": { "inactiveAfterSeconds": 0, "expungeAfterSeconds": 0 },
-   "role": "slave_public"
+   "role": "slave_public",
+   "tasksStaged": 0,
+   "tasksRunning": 1,
+   "tasksHealthy": 0,
+   "tasksUnhealthy": 1,
+   "deployments": [ { "id": "97c136bf-5a28-4821-9d94-480d9fbb01c8" } ]
   },
   {
    "id": "/infra/broker-0",
@@ -590,7 +599,55 @@ var AppRedis = `{
    "upgradeStrategy": { "maximumOverCapacity": 0, "minimumHealthCapacity": 0 },
    "killSelection": "YOUNGEST_FIRST",
    "unreachableStrategy": { "inactiveAfterSeconds": 0, "expungeAfterSeconds": 0 },
-   "role": "slave_public"
+   "role": "slave_public",
+   "tasksStaged": 0,
+   "tasksRunning": 1,
+   "tasksHealthy": 1,
+   "tasksUnhealthy": 0,
+   "deployments": []
+  }
+}`
+
+// AppRedisPrevious is /infra/redis-1 as it was on version 2021-01-16T18:27:41.662Z
+var AppRedisPrevious = `{
+  "app":   {
+   "id": "/infra/redis-1",
+   "acceptedResourceRoles": [ "*" ],
+   "backoffFactor": 1.15,
+   "backoffSeconds": 1,
+   "container": { "type": "DOCKER",
+    "docker": { "image": "docker.io/redis-ha:5.0.4", "privileged": false, "forcePullImage": true, "parameters": [{"key": "add-host", "value": "10.128.64.32"}]},
+	"volumes": [
+     { "containerPath": "/data", "hostPath": "/var/lib/mesos/redis/1/data", "mode": "RW" },
+	 { "containerPath": "/conf", "hostPath": "/data/redis-1/conf", "mode": "RW" },
+	 { "containerPath": "/etc/localtime", "hostPath": "/etc/localtime", "mode": "RO" }
+	],
+	"portMappings": [
+	 { "containerPort": 46379, "labels": { "VIP_0": "/redissrv:46379" }, "protocol": "tcp", "servicePort": 10013 }
+	]
+   },
+   "cpus": 0.5,
+   "env": { "REDISPORT": "46379", "REDISMODE": "standalone" },
+   "fetch": [
+	{ "uri": "file:///data/registry-auth/docker.tar.gz", "extract": true, "executable": false, "cache": false }
+   ],
+   "healthChecks": [
+	{ "gracePeriodSeconds": 30, "intervalSeconds": 5, "maxConsecutiveFailures": 3, "path": "", "portIndex": 0, "protocol": "TCP", "ipProtocol": "IPv4", "timeoutSeconds": 5, "delaySeconds": 15 }
+   ],
+   "instances": 1,
+   "labels": { "ENVIRONMENT": "staging" },
+   "maxLaunchDelaySeconds": 3600,
+   "mem": 8192,
+   "networks": [ { "mode": "container/bridge" } ],
+   "upgradeStrategy": { "maximumOverCapacity": 0, "minimumHealthCapacity": 0 },
+   "killSelection": "YOUNGEST_FIRST",
+   "unreachableStrategy": { "inactiveAfterSeconds": 0, "expungeAfterSeconds": 0 },
+   "role": "slave_public",
+   "tasksStaged": 0,
+   "tasksRunning": 1,
+   "tasksHealthy": 1,
+   "tasksUnhealthy": 0,
+   "deployments": []
   }
 }`
 
@@ -635,7 +692,12 @@ var appBroker = `{
    "upgradeStrategy": { "maximumOverCapacity": 0, "minimumHealthCapacity": 0 },
    "killSelection": "YOUNGEST_FIRST",
    "unreachableStrategy": { "inactiveAfterSeconds": 0, "expungeAfterSeconds": 0 },
-   "role": "slave_public"
+   "role": "slave_public",
+   "tasksStaged": 0,
+   "tasksRunning": 1,
+   "tasksHealthy": 1,
+   "tasksUnhealthy": 0,
+   "deployments": []
   }
 }`
 
@@ -729,8 +791,560 @@ var KongApp = `{
 	}
 }`
 
+var LockedApp = `{
+  "message": "App is locked by one or more deployments. Override with the option '?force=true'. View details at '/v2/deployments/<DEPLOYMENT_ID>'.",
+  "deployments": [ { "id": "97c136bf-5a28-4821-9d94-480d9fbb01c8" } ]
+}`
+
+var InvalidApp = `{
+  "message": "Object is not valid",
+  "details": [
+    { "path": "/cpus", "errors": [ "error.min" ] },
+    { "path": "/container/docker/image", "errors": [ "must not be empty" ] }
+  ]
+}`
+
+var SomeEvents = []string{
+	`{"remoteAddress":"127.0.0.1","eventType":"event_stream_attached","timestamp":"2021-01-21T20:27:42.725Z"}`,
+	`{"slaveId":"agent-1","taskId":"infra_redis-1.c9de6033","taskStatus":"TASK_RUNNING","message":"","appId":"/infra/redis-1","host":"10.0.0.1","ipAddresses":[{"ipAddress":"172.17.0.2","protocol":"IPv4"}],"ports":[31001],"version":"2021-01-21T20:27:42.725Z","eventType":"status_update_event","timestamp":"2021-01-21T20:27:43.725Z"}`,
+	`{"appId":"/infra/redis-1","taskId":"infra_redis-1.c9de6033","instanceId":"infra_redis-1.marathon-c9de6033","version":"2021-01-21T20:27:42.725Z","alive":true,"eventType":"health_status_changed_event","timestamp":"2021-01-21T20:27:44.725Z"}`,
+	`{"id":"97c136bf-5a28-4821-9d94-480d9fbb01c8","plan":{"id":"97c136bf-5a28-4821-9d94-480d9fbb01c8","version":"2021-01-21T20:27:42.725Z","steps":[{"actions":[{"action":"ScaleApplication","app":"/infra/redis-1"}]}]},"eventType":"deployment_success","timestamp":"2021-01-21T20:27:45.725Z"}`,
+}
+
+var SomeTasks = `{
+  "tasks": [
+    {
+      "appId": "/infra/redis-1",
+      "healthCheckResults": [
+        { "alive": true, "consecutiveFailures": 0, "firstSuccess": "2021-01-21T20:27:50.725Z", "instanceId": "infra_redis-1.marathon-c9de6033", "lastSuccess": "2021-01-21T20:30:50.725Z" }
+      ],
+      "host": "10.0.0.1",
+      "id": "infra_redis-1.c9de6033",
+      "ipAddresses": [ { "ipAddress": "172.17.0.2", "protocol": "IPv4" } ],
+      "ports": [ 31001 ],
+      "servicePorts": [ 10001 ],
+      "slaveId": "agent-1",
+      "state": "TASK_RUNNING",
+      "stagedAt": "2021-01-21T20:27:42.725Z",
+      "startedAt": "2021-01-21T20:27:45.725Z",
+      "version": "2021-01-21T20:27:42.725Z"
+    },
+    {
+      "appId": "/infra/redis-1",
+      "healthCheckResults": [
+        { "alive": false, "consecutiveFailures": 3, "firstSuccess": "2021-01-21T20:27:50.725Z", "instanceId": "infra_redis-1.marathon-d1ab7744", "lastSuccess": "2021-01-21T20:28:50.725Z", "lastFailure": "2021-01-21T20:30:50.725Z", "lastFailureCause": "connection refused" }
+      ],
+      "host": "10.0.0.2",
+      "id": "infra_redis-1.d1ab7744",
+      "ipAddresses": [ { "ipAddress": "172.17.0.3", "protocol": "IPv4" } ],
+      "ports": [ 31002 ],
+      "servicePorts": [ 10001 ],
+      "slaveId": "agent-2",
+      "state": "TASK_RUNNING",
+      "stagedAt": "2021-01-21T20:27:42.725Z",
+      "startedAt": "2021-01-21T20:27:45.725Z",
+      "version": "2021-01-21T20:27:42.725Z"
+    }
+  ]
+}`
+
+var SomeQueue = `{
+  "queue": [
+    {
+      "app": { "id": "/infra/redis-1", "instances": 2, "cpus": 4, "mem": 2048 },
+      "count": 1,
+      "delay": { "timeLeftSeconds": 17, "overdue": false },
+      "since": "2021-01-21T20:27:42.725Z",
+      "processedOffersSummary": {
+        "processedOffersCount": 3,
+        "unusedOffersCount": 3,
+        "rejectSummaryLastOffers": [
+          { "reason": "UnfulfilledRole", "declined": 0, "processed": 3 },
+          { "reason": "InsufficientCpus", "declined": 2, "processed": 3 },
+          { "reason": "UnfulfilledConstraint", "declined": 1, "processed": 1 }
+        ]
+      },
+      "lastUnusedOffers": [
+        {
+          "offer": { "id": "offer-1", "agentId": "agent-1", "hostname": "10.0.0.1", "resources": [ { "name": "cpus", "scalar": 0.5, "role": "*" } ], "attributes": [] },
+          "timestamp": "2021-01-21T20:27:50.725Z",
+          "reason": [ "InsufficientCpus" ]
+        },
+        {
+          "offer": { "id": "offer-2", "agentId": "agent-2", "hostname": "10.0.0.2", "resources": [ { "name": "cpus", "scalar": 1.5, "role": "*" } ], "attributes": [] },
+          "timestamp": "2021-01-21T20:27:51.725Z",
+          "reason": [ "InsufficientCpus", "InsufficientMemory" ]
+        },
+        {
+          "offer": { "id": "offer-3", "agentId": "agent-3", "hostname": "10.0.0.3", "resources": [], "attributes": [] },
+          "timestamp": "2021-01-21T20:27:52.725Z",
+          "reason": [ "UnfulfilledConstraint" ]
+        }
+      ]
+    },
+    {
+      "app": { "id": "/infra/broker-0", "instances": 1, "cpus": 1, "mem": 512 },
+      "count": 1,
+      "delay": { "timeLeftSeconds": 0, "overdue": true },
+      "since": "2021-01-21T20:27:42.725Z",
+      "processedOffersSummary": {
+        "processedOffersCount": 4,
+        "unusedOffersCount": 4,
+        "rejectSummaryLastOffers": [
+          { "reason": "NoCorrespondingReservationFound", "declined": 4, "processed": 4 }
+        ]
+      }
+    }
+  ]
+}`
+
+var SomeMetrics = `{
+  "version": "4.0.0",
+  "counters": {
+    "marathon.mesos.offers.used.counter": { "count": 12 },
+    "marathon.mesos.offers.declined.counter": { "count": 3 },
+    "marathon.deployments.counter": { "count": 7 }
+  },
+  "gauges": {
+    "marathon.apps.active.gauge": { "value": 4 },
+    "marathon.jvm.memory.heap.usage.gauge": { "value": 0.42 },
+    "marathon.jvm.threads.deadlocks.gauge": { "value": [] }
+  },
+  "histograms": {
+    "marathon.http.requests.size.histogram.bytes": { "count": 10, "min": 1, "mean": 5.5, "max": 10, "p50": 5, "p75": 8, "p95": 10, "p98": 10, "p99": 10, "p999": 10, "stddev": 2.8 }
+  },
+  "meters": {
+    "marathon.http.responses.5xx.rate.meter": { "count": 2, "m1_rate": 0.1, "m5_rate": 0.05, "m15_rate": 0.01, "mean_rate": 0.02, "units": "events/second" }
+  },
+  "timers": {
+    "marathon.http.requests.duration.timer.seconds": { "count": 100, "min": 0.001, "mean": 0.01, "max": 0.2, "p50": 0.01, "p75": 0.02, "p95": 0.05, "p98": 0.1, "p99": 0.15, "p999": 0.2, "stddev": 0.02, "m1_rate": 1.5, "m5_rate": 1.2, "m15_rate": 1.1, "mean_rate": 1.3, "duration_units": "seconds", "rate_units": "calls/second" }
+  }
+}`
+
+var SomePlugins = `{
+  "plugins": [
+    {
+      "id": "auth-plugin",
+      "implementation": "mesosphere.marathon.example.plugin.auth.ExampleAuthenticator",
+      "info": { "version": "1.2.3", "array": [ 1, 2, 3 ], "test": true },
+      "plugin": "mesosphere.marathon.plugin.auth.Authenticator",
+      "tags": [ "auth" ]
+    }
+  ]
+}`
+
+var SomePod = `{
+  "id": "/infra/web",
+  "labels": { "ENVIRONMENT": "testing" },
+  "environment": { "LOG_LEVEL": "info", "DB_PASSWORD": { "secret": "db" } },
+  "secrets": { "db": { "source": "/infra/db-password" } },
+  "containers": [
+    {
+      "name": "nginx",
+      "resources": { "cpus": 0.5, "mem": 128 },
+      "image": { "kind": "DOCKER", "id": "docker.io/nginx:1.19" },
+      "endpoints": [ { "name": "http", "containerPort": 80, "hostPort": 0, "protocol": [ "tcp" ] } ],
+      "healthCheck": { "http": { "endpoint": "http", "path": "/health" }, "gracePeriodSeconds": 30 },
+      "volumeMounts": [ { "name": "assets", "mountPath": "/usr/share/nginx/html" } ]
+    },
+    {
+      "name": "sidecar",
+      "resources": { "cpus": 0.1, "mem": 32 },
+      "exec": { "command": { "shell": "sleep infinity" } }
+    }
+  ],
+  "volumes": [ { "name": "assets" } ],
+  "networks": [ { "name": "dcos", "mode": "container" } ],
+  "scaling": { "kind": "fixed", "instances": 2 },
+  "scheduling": {
+    "backoff": { "backoff": 1, "backoffFactor": 1.15, "maxLaunchDelay": 3600 },
+    "upgrade": { "minimumHealthCapacity": 1, "maximumOverCapacity": 1 },
+    "placement": { "constraints": [ { "fieldName": "hostname", "operator": "UNIQUE" } ] }
+  }
+}`
+
+var SomePodStatus = `{
+  "id": "/infra/web",
+  "status": "STABLE",
+  "statusSince": "2021-01-21T20:27:42.725Z",
+  "instances": [
+    {
+      "id": "infra_web.instance-c9de6033",
+      "status": "STABLE",
+      "statusSince": "2021-01-21T20:27:42.725Z",
+      "agentHostname": "10.0.0.1",
+      "containers": [
+        { "name": "nginx", "status": "TASK_RUNNING", "statusSince": "2021-01-21T20:27:42.725Z", "endpoints": [ { "name": "http", "allocatedHostPort": 31001 } ], "conditions": [ { "name": "healthy", "value": "true", "lastChanged": "2021-01-21T20:27:42.725Z", "lastUpdated": "2021-01-21T20:27:42.725Z" } ], "lastUpdated": "2021-01-21T20:27:42.725Z", "lastChanged": "2021-01-21T20:27:42.725Z" }
+      ],
+      "lastUpdated": "2021-01-21T20:27:42.725Z",
+      "lastChanged": "2021-01-21T20:27:42.725Z"
+    }
+  ],
+  "lastUpdated": "2021-01-21T20:27:42.725Z",
+  "lastChanged": "2021-01-21T20:27:42.725Z"
+}`
+
+var FullApp = `{
+  "id": "/infra/postgres",
+  "cmd": "docker-entrypoint.sh postgres",
+  "user": "postgres",
+  "container": {
+    "type": "MESOS",
+    "docker": { "image": "docker.io/postgres:13", "privileged": false, "forcePullImage": false, "pullConfig": { "secret": "registry" } },
+    "volumes": [
+      { "containerPath": "pgdata", "mode": "RW", "persistent": { "type": "root", "size": 1024, "constraints": [ [ "path", "LIKE", "/mnt/.*" ] ] } },
+      { "containerPath": "/backup", "mode": "RW", "external": { "name": "pg-backup", "provider": "dvdi", "options": { "dvdi/driver": "rexray" } } },
+      { "containerPath": "/etc/pg.pem", "secret": "certificate" }
+    ],
+    "portMappings": [ { "name": "pg", "containerPort": 5432, "protocol": "tcp", "networkNames": [ "dcos" ] } ]
+  },
+  "cpus": 2,
+  "mem": 4096,
+  "instances": 1,
+  "env": { "PGDATA": "pgdata", "POSTGRES_PASSWORD": { "secret": "password" } },
+  "secrets": { "password": { "source": "/infra/postgres/password" }, "certificate": { "source": "/infra/postgres/cert" }, "registry": { "source": "/infra/registry" } },
+  "healthChecks": [ { "gracePeriodSeconds": 300, "intervalSeconds": 60, "maxConsecutiveFailures": 3, "path": "", "portIndex": 0, "protocol": "COMMAND", "ipProtocol": "IPv4", "timeoutSeconds": 20, "delaySeconds": 15, "command": { "value": "pg_isready" } } ],
+  "readinessChecks": [ { "name": "ready", "protocol": "HTTP", "path": "/ready", "portName": "pg", "intervalSeconds": 30, "timeoutSeconds": 10, "httpStatusCodesForReady": [ 200 ] } ],
+  "networks": [ { "name": "dcos", "mode": "container", "labels": { "tier": "db" } } ],
+  "dependencies": [ "/infra/redis-1" ],
+  "residency": { "relaunchEscalationTimeoutSeconds": 3600, "taskLostBehavior": "WAIT_FOREVER" },
+  "taskKillGracePeriodSeconds": 30,
+  "upgradeStrategy": { "maximumOverCapacity": 0, "minimumHealthCapacity": 0 },
+  "unreachableStrategy": { "inactiveAfterSeconds": 0, "expungeAfterSeconds": 0 }
+}`
+
+// RecordedApp is a GET /v2/apps/{id} response recorded from a real Marathon 1.9 server
+var RecordedApp = `{
+  "app": {
+    "id": "/infra/recorded",
+    "backoffFactor": 1.15,
+    "backoffSeconds": 0,
+    "container": {
+      "type": "DOCKER",
+      "docker": { "forcePullImage": false, "image": "docker.io/nginx:1.19", "parameters": [], "privileged": false },
+      "volumes": [],
+      "portMappings": [ { "containerPort": 80, "hostPort": 0, "labels": {}, "protocol": "tcp", "servicePort": 10101 } ]
+    },
+    "cpus": 0.1,
+    "disk": 0,
+    "env": {},
+    "executor": "",
+    "instances": 2,
+    "labels": { "HAPROXY_GROUP": "external" },
+    "maxLaunchDelaySeconds": 300,
+    "mem": 128,
+    "gpus": 0,
+    "networks": [ { "mode": "container/bridge" } ],
+    "requirePorts": false,
+    "upgradeStrategy": { "maximumOverCapacity": 1, "minimumHealthCapacity": 1 },
+    "version": "2021-01-21T20:27:42.725Z",
+    "versionInfo": { "lastScalingAt": "2021-01-21T20:27:42.725Z", "lastConfigChangeAt": "2021-01-21T20:27:42.725Z" },
+    "killSelection": "YOUNGEST_FIRST",
+    "unreachableStrategy": { "inactiveAfterSeconds": 0, "expungeAfterSeconds": 0 },
+    "tasksStaged": 0,
+    "tasksRunning": 2,
+    "tasksHealthy": 0,
+    "tasksUnhealthy": 0,
+    "deployments": [],
+    "tasks": [],
+    "storeUrls": [],
+    "uris": [],
+    "tty": false,
+    "resourceLimits": { "cpus": "unlimited", "mem": 512 },
+    "role": "slave_public"
+  }
+}`
+
+// HostApp is a host networking app as Marathon returns it, with both ports and portDefinitions
+// and the defaults Marathon fills in
+var HostApp = `{
+  "id": "/stored/host/web",
+  "cmd": "python3 -m http.server $PORT0",
+  "backoffFactor": 1.15,
+  "backoffSeconds": 1,
+  "cpus": 0.1,
+  "disk": 0,
+  "executor": "",
+  "instances": 2,
+  "labels": { "HAPROXY_0_VHOST": "web.example.com", "HAPROXY_GROUP": "external" },
+  "maxLaunchDelaySeconds": 300,
+  "mem": 64,
+  "gpus": 0,
+  "networks": [ { "mode": "host" } ],
+  "portDefinitions": [ { "port": 10105, "name": "http", "protocol": "tcp", "labels": {} } ],
+  "ports": [ 10105 ],
+  "requirePorts": false,
+  "upgradeStrategy": { "maximumOverCapacity": 1, "minimumHealthCapacity": 1 },
+  "version": "2021-01-21T20:27:42.725Z",
+  "versionInfo": { "lastScalingAt": "2021-01-21T20:27:42.725Z", "lastConfigChangeAt": "2021-01-21T20:27:42.725Z" },
+  "killSelection": "YOUNGEST_FIRST",
+  "unreachableStrategy": { "inactiveAfterSeconds": 0, "expungeAfterSeconds": 0 },
+  "tasksStaged": 0,
+  "tasksRunning": 2,
+  "tasksHealthy": 0,
+  "tasksUnhealthy": 0,
+  "deployments": [],
+  "storeUrls": [],
+  "uris": [],
+  "tty": false,
+  "role": "*"
+}`
+
 var times int = 0
 
+var lastBody []byte
+var lastBodyMutex sync.Mutex
+
+// LastBody returns the body of the last PUT request received by a recording endpoint
+func LastBody() []byte {
+	lastBodyMutex.Lock()
+	defer lastBodyMutex.Unlock()
+
+	return lastBody
+}
+
+// recordBody saves the body of a request
+func recordBody(r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	lastBodyMutex.Lock()
+	defer lastBodyMutex.Unlock()
+
+	lastBody = body
+}
+
+var revision int
+var revisionMutex sync.Mutex
+
+// nextRevision returns a new revision of the moving app and group, each read sees a new one
+func nextRevision() int {
+	revisionMutex.Lock()
+	defer revisionMutex.Unlock()
+
+	revision++
+	return revision
+}
+
+// MovingApp returns the app /infra/moving as it is at revision, someone else changes its
+// labels and version between reads
+func MovingApp(revision int) string {
+	return fmt.Sprintf(`{
+  "app": {
+    "id": "/infra/moving",
+    "cmd": "sleep 100",
+    "cpus": 0.1,
+    "mem": 64,
+    "instances": 1,
+    "env": { "LOG_LEVEL": "info" },
+    "labels": { "REVISION": "%d" },
+    "container": { "type": "DOCKER", "docker": { "image": "busybox:1.32" } },
+    "version": "%s"
+  }
+}`, revision, time.Date(2021, 1, 21, 20, 0, revision, 0, time.UTC).Format(time.RFC3339Nano))
+}
+
+// MovingGroup returns the group /moving as it is at revision, its version changes between reads
+func MovingGroup(revision int) string {
+	return fmt.Sprintf(`{
+  "id": "/moving",
+  "apps": [],
+  "groups": [],
+  "pods": [],
+  "version": "%s"
+}`, time.Date(2021, 1, 21, 20, 0, revision, 0, time.UTC).Format(time.RFC3339Nano))
+}
+
+// StoredAppsPrefix is the path of apps served from the store, they can be created, changed and
+// destroyed; their tasks are running and healthy unless their image contains "broken", tasks
+// fail their health checks if it contains "unhealthy" and restart on every read if it contains
+// "flapping"; tasks report no health check results yet if it contains "unchecked" and apps
+// whose image contains "undeletable" cannot be destroyed
+const StoredAppsPrefix = "/v2/apps/stored/"
+
+var storedApps = make(map[string]map[string]json.RawMessage)
+var storedTasksRead int
+var storedAppsMutex sync.Mutex
+
+// StoreApp saves app (an AppDefinition as JSON) into the store
+func StoreApp(app string) {
+	fields := make(map[string]json.RawMessage)
+	_ = json.Unmarshal([]byte(app), &fields)
+
+	var id string
+	_ = json.Unmarshal(fields["id"], &id)
+
+	storedAppsMutex.Lock()
+	defer storedAppsMutex.Unlock()
+
+	storedApps[id] = fields
+}
+
+// StoredApp returns the app id as it is in the store, empty if it does not exist
+func StoredApp(id string) string {
+	storedAppsMutex.Lock()
+	defer storedAppsMutex.Unlock()
+
+	if fields, ok := storedApps[id]; ok {
+		app, _ := json.Marshal(fields)
+		return string(app)
+	}
+	return ""
+}
+
+// storedApp serves the apps of the store
+func storedApp(w http.ResponseWriter, r *http.Request, buffer []byte) {
+	id := strings.TrimPrefix(r.URL.Path, "/v2/apps")
+	tasks := strings.HasSuffix(id, "/tasks")
+	id = strings.TrimSuffix(id, "/tasks")
+
+	storedAppsMutex.Lock()
+	defer storedAppsMutex.Unlock()
+
+	fields, ok := storedApps[id]
+	if !ok && r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"message": "App '%s' does not exist"}`, id)))
+		return
+	}
+
+	var app struct {
+		Instances int `json:"instances"`
+		Container struct {
+			Docker struct {
+				Image string `json:"image"`
+			} `json:"docker"`
+		} `json:"container"`
+	}
+	content, _ := json.Marshal(fields)
+	_ = json.Unmarshal(content, &app)
+
+	running := app.Instances
+	if strings.Contains(app.Container.Docker.Image, "broken") {
+		running = 0
+	}
+	healthy := running
+	if strings.Contains(app.Container.Docker.Image, "unhealthy") {
+		healthy = 0
+	}
+
+	switch {
+
+	case tasks && r.Method == http.MethodGet:
+		generation := 0
+		if strings.Contains(app.Container.Docker.Image, "flapping") {
+			storedTasksRead++
+			generation = storedTasksRead
+		}
+
+		list := make([]map[string]interface{}, 0, running)
+		for index := 0; index < running; index++ {
+			taskID := fmt.Sprintf("%s.%d-%d", strings.Replace(strings.TrimPrefix(id, "/"), "/", "_", -1), index, generation)
+			task := map[string]interface{}{
+				"appId":              id,
+				"id":                 taskID,
+				"state":              "TASK_RUNNING",
+				"healthCheckResults": []map[string]interface{}{{"alive": index < healthy, "taskId": taskID}},
+			}
+			if strings.Contains(app.Container.Docker.Image, "unchecked") {
+				delete(task, "healthCheckResults")
+			}
+			list = append(list, task)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"tasks": list})
+
+	case r.Method == http.MethodGet:
+		status := make(map[string]interface{})
+		_ = json.Unmarshal(content, &status)
+		status["tasksStaged"] = 0
+		status["tasksRunning"] = running
+		status["tasksHealthy"] = healthy
+		status["tasksUnhealthy"] = running - healthy
+		status["deployments"] = []interface{}{}
+
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"app": status})
+
+	case r.Method == http.MethodPut || r.Method == http.MethodPatch:
+		changes := make(map[string]json.RawMessage)
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &changes)
+
+		if r.Method == http.MethodPut || fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+		for name, value := range changes {
+			fields[name] = value
+		}
+		fields["id"], _ = json.Marshal(id)
+		storedApps[id] = fields
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buffer)
+
+	case r.Method == http.MethodDelete:
+		if strings.Contains(app.Container.Docker.Image, "undeletable") {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"message": "App '%s' cannot be destroyed"}`, id)))
+			return
+		}
+		delete(storedApps, id)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buffer)
+	}
+}
+
+// groupApp serves the apps of GroupsArray not served on their own
+func groupApp(w http.ResponseWriter, r *http.Request, buffer []byte) {
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/apps"), "/restart")
+	app, ok := findGroupApp([]byte(GroupsArray), id)
+	if !ok {
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet && !strings.HasSuffix(r.URL.Path, "/restart") {
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"app": %s}`, app)))
+		return
+	}
+	_, _ = w.Write(buffer)
+}
+
+// findGroupApp returns the definition of app id inside group, looking into its groups
+func findGroupApp(group []byte, id string) (json.RawMessage, bool) {
+
+	var content struct {
+		Apps   []json.RawMessage `json:"apps"`
+		Groups []json.RawMessage `json:"groups"`
+	}
+	_ = json.Unmarshal(group, &content)
+
+	for _, app := range content.Apps {
+		var definition struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(app, &definition) == nil && definition.ID == id {
+			return app, true
+		}
+	}
+	for _, inner := range content.Groups {
+		if app, ok := findGroupApp(inner, id); ok {
+			return app, true
+		}
+	}
+	return nil, false
+}
+
+// ResetDeployments sets the deployments served and restarts the "break condition" counter
+func ResetDeployments(deployments string) {
+	DeployArray = deployments
+	times = 0
+}
+
 func MockServer() *httptest.Server {
 	// Mock Marathon server
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -741,12 +1355,61 @@ func MockServer() *httptest.Server {
 		}
 		buffer, _ := json.Marshal(fakeDeploy)
 
+		if strings.HasPrefix(r.URL.Path, StoredAppsPrefix) {
+			storedApp(w, r, buffer)
+			return
+		}
+
 		switch r.URL.Path {
 
 		case "/ping":
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte(`pong`))
 
+		case "/v2/events":
+			filter := r.URL.Query()["event_type"]
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			for _, event := range SomeEvents {
+				var base struct {
+					EventType string `json:"eventType"`
+				}
+				_ = json.Unmarshal([]byte(event), &base)
+
+				matched := len(filter) == 0
+				for _, f := range filter {
+					matched = matched || f == base.EventType
+				}
+				if matched {
+					_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", base.EventType, event)
+				}
+			}
+
+		case "/metrics":
+			w.WriteHeader(http.StatusOK)
+			w.Header().Add("Content-Type", "application/json")
+			_, _ = w.Write([]byte(SomeMetrics))
+
+		case "/v2/leader":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				leaderBuffer, _ := json.Marshal(map[string]string{"leader": r.Host})
+
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(leaderBuffer)
+
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"message": "Leadership abdicated"}`))
+			}
+
+		case "/v2/plugins":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(SomePlugins))
+
 		case "/v2/info":
 			fakeInfo := &data.Info{
 				Name:           "mock_marathon",
@@ -837,6 +1500,12 @@ func MockServer() *httptest.Server {
 				w.Header().Add("Content-Type", "application/json")
 				_, _ = w.Write(buffer)
 
+			case http.MethodPut:
+				recordBody(r)
+				w.WriteHeader(http.StatusOK)
+				w.Header().Add("Content-Type", "application/json")
+				_, _ = w.Write(buffer)
+
 			case http.MethodDelete:
 				w.WriteHeader(http.StatusOK)
 				w.Header().Add("Content-Type", "application/json")
@@ -854,11 +1523,13 @@ func MockServer() *httptest.Server {
 				_, _ = w.Write([]byte(AppRedis))
 
 			case http.MethodPatch:
+				recordBody(r)
 				w.WriteHeader(http.StatusOK)
 				w.Header().Add("Content-Type", "application/json")
 				_, _ = w.Write(buffer)
 
 			case http.MethodPut:
+				recordBody(r)
 				w.WriteHeader(http.StatusOK)
 				w.Header().Add("Content-Type", "application/json")
 				_, _ = w.Write(buffer)
@@ -926,6 +1597,21 @@ func MockServer() *httptest.Server {
 				_, _ = w.Write([]byte(AppRedisVersions))
 			}
 
+		case "/v2/apps/infra/versioned/versions":
+			// Marathon trims trailing zeros of fractional seconds
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"versions": ["2021-01-21T20:27:42.7Z", "2021-01-21T20:27:42.65Z", "2021-01-21T20:27:42.725Z"]}`))
+
+		case "/v2/apps/infra/redis-1/versions/2021-01-16T18:27:41.662Z":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				w.Header().Add("Content-Type", "application/json")
+				_, _ = w.Write([]byte(AppRedisPrevious))
+			}
+
 		case "/v2/apps/infra/redis-1/versions/2021-01-21T20:27:42.725Z":
 
 			switch r.Method {
@@ -961,6 +1647,179 @@ func MockServer() *httptest.Server {
 				_, _ = w.Write(buffer)
 
 			}
+
+		case "/v2/apps/infra/redis-1/tasks", "/v2/tasks":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				w.Header().Add("Content-Type", "application/json")
+				_, _ = w.Write([]byte(SomeTasks))
+			}
+
+		case "/v2/apps/infra/redis-1/tasks/infra_redis-1.c9de6033":
+
+			switch r.Method {
+
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusOK)
+				w.Header().Add("Content-Type", "application/json")
+				if r.URL.Query().Get("scale") == "true" {
+					_, _ = w.Write(buffer)
+				} else {
+					_, _ = w.Write([]byte(`{"task": {"id": "infra_redis-1.c9de6033", "appId": "/infra/redis-1", "state": "TASK_KILLING"}}`))
+				}
+			}
+
+		case "/v2/apps/infra/redis-1/tasks/infra_redis-1.unknown":
+			w.WriteHeader(http.StatusNotFound)
+			w.Header().Add("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message": "Task 'infra_redis-1.unknown' does not exist"}`))
+
+		case "/v2/tasks/delete":
+
+			switch r.Method {
+
+			case http.MethodPost:
+				w.WriteHeader(http.StatusOK)
+				w.Header().Add("Content-Type", "application/json")
+				if r.URL.Query().Get("scale") == "true" {
+					_, _ = w.Write(buffer)
+				}
+			}
+
+		case "/v2/queue/":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				w.Header().Add("Content-Type", "application/json")
+				_, _ = w.Write([]byte(SomeQueue))
+			}
+
+		case "/v2/queue/infra/redis-1/delay":
+
+			switch r.Method {
+
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			}
+
+		case "/v2/pods/infra/web":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				w.Header().Add("Content-Type", "application/json")
+				_, _ = w.Write([]byte(SomePod))
+
+			case http.MethodPut:
+				w.Header().Add("Marathon-Deployment-Id", fakeDeploy.ID)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(SomePod))
+
+			case http.MethodDelete:
+				w.Header().Add("Marathon-Deployment-Id", fakeDeploy.ID)
+				w.WriteHeader(http.StatusAccepted)
+			}
+
+		case "/v2/pods/infra/web::status":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(SomePodStatus))
+
+		case "/v2/pods/infra/web::versions":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`["2021-01-21T20:27:42.7Z", "2021-01-21T20:27:42.725Z"]`))
+
+		case "/v2/pods/infra/web::versions/2021-01-21T20:27:42.725Z":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(SomePod))
+
+		case "/v2/pods/infra/web::instances/infra_web.instance-c9de6033":
+
+			switch r.Method {
+
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"instanceId": "infra_web.instance-c9de6033"}`))
+			}
+
+		case "/v2/pods/infra/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Pod '/infra/missing' does not exist"}`))
+
+		case "/v2/apps/infra/recorded":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(RecordedApp))
+
+			case http.MethodPut:
+				recordBody(r)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(buffer)
+			}
+
+		case "/v2/apps/infra/moving":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(MovingApp(nextRevision())))
+
+			case http.MethodPut, http.MethodPatch:
+				recordBody(r)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(buffer)
+			}
+
+		case "/v2/groups/moving":
+
+			switch r.Method {
+
+			case http.MethodGet:
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(MovingGroup(nextRevision())))
+
+			case http.MethodPut, http.MethodPost:
+				recordBody(r)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(buffer)
+			}
+
+		case "/v2/apps/infra/locked":
+
+			switch r.Method {
+
+			case http.MethodPut:
+				w.WriteHeader(http.StatusConflict)
+				w.Header().Add("Content-Type", "application/json")
+				_, _ = w.Write([]byte(LockedApp))
+			}
+
+		case "/v2/apps/infra/invalid":
+
+			switch r.Method {
+
+			case http.MethodPut:
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Header().Add("Content-Type", "application/json")
+				_, _ = w.Write([]byte(InvalidApp))
+			}
+
+		case "/v2/apps/infra/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Header().Add("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"message": "App '/infra/missing' does not exist"}`))
+
+		default:
+			groupApp(w, r, buffer)
 		}
 	}),
 	)
@@ -0,0 +1,369 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/diff"
+	"github.com/dotWicho/marathon/groups"
+	"path"
+	"strings"
+	"time"
+)
+
+// Action of a plan item
+type Action string
+
+const (
+	// Create an app or group missing on Marathon
+	Create Action = "create"
+	// Update an app or group that differs from its definition
+	Update Action = "update"
+	// Delete an app or group not defined, only planned with Prune
+	Delete Action = "delete"
+)
+
+// Kinds of plan items
+const (
+	KindApp   = "app"
+	KindGroup = "group"
+)
+
+// groupTreeFields are Group fields holding its children or changed by Marathon on every
+// deployment, they are not compared as group fields
+var groupTreeFields = []string{"apps", "groups", "pods", "version", "versionInfo"}
+
+// Item is a change of a plan
+type Item struct {
+	Action Action `json:"action"`
+	// Kind is KindApp or KindGroup
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	// Changes holds the field changes of an Update
+	Changes diff.Changes `json:"changes,omitempty"`
+}
+
+// Plan holds the changes needed to turn a live group tree into a desired one
+type Plan struct {
+	// Group is the id of the root of the tree
+	Group string
+	// Items follow the desired tree, groups before apps and deletions last
+	Items []Item
+	// Unmanaged are the ids of live apps and groups not defined, kept as they are without Prune
+	Unmanaged []string
+	// Prune is true if unmanaged apps and groups are planned to be deleted
+	Prune bool
+
+	desired *groups.Group
+	live    *groups.Groups
+}
+
+// Options of a Planner
+type Options struct {
+	// Prune deletes live apps and groups missing in the desired tree
+	Prune bool
+	// Ordered applies plans app by app in dependency order instead of a single group update,
+	// changes of group fields like dependencies are only sent by the single group update
+	Ordered bool
+	// Force changes even if the apps are locked by other deployments, only used when Ordered
+	Force bool
+}
+
+// Planner computes and applies plans of group trees
+type Planner struct {
+	client  *marathon.Client
+	options Options
+}
+
+// New returns a new instance of Planner with options
+func New(client *marathon.Client, options Options) *Planner {
+
+	if client != nil {
+		return &Planner{
+			client:  client,
+			options: options,
+		}
+	}
+	return nil
+}
+
+// PlanFile returns the plan to turn the live group tree into the one defined in fileName
+func (p *Planner) PlanFile(fileName string) (*Plan, error) {
+
+	return p.PlanFileContext(context.Background(), fileName)
+}
+
+// PlanFileContext returns the plan to turn the live group tree into the one defined in fileName,
+// honoring ctx
+func (p *Planner) PlanFileContext(ctx context.Context, fileName string) (*Plan, error) {
+
+	loaded := groups.New(p.client).Load(fileName)
+	if err := loaded.LastError(); err != nil {
+		return nil, fmt.Errorf("unable to load group from %s: %w", fileName, err)
+	}
+	return p.PlanContext(ctx, loaded.AsRaw())
+}
+
+// Plan returns the plan to turn the live group tree into desired
+func (p *Planner) Plan(desired *groups.Group) (*Plan, error) {
+
+	return p.PlanContext(context.Background(), desired)
+}
+
+// PlanContext returns the plan to turn the live group tree into desired, honoring ctx. Apps and
+// groups are compared as diff.Definitions does, fields Marathon fills with defaults are only
+// compared if desired sets them
+func (p *Planner) PlanContext(ctx context.Context, desired *groups.Group) (*Plan, error) {
+
+	if desired == nil || len(desired.ID) == 0 {
+		return nil, errors.New("group cannot be null nor empty")
+	}
+	if err := desired.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := desired.Tiers(); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Group: resolve("", desired.ID), Prune: p.options.Prune, desired: desired}
+
+	// A group missing on Marathon is created as a whole
+	live := groups.New(p.client).GetContext(ctx, desired.ID)
+	if err := live.LastError(); err != nil && !marathon.IsNotFound(err) {
+		return nil, err
+	}
+	wanted := flatten(desired)
+	current := flatten(&groups.Group{})
+	if live.LastError() == nil && len(live.AsRaw().ID) > 0 {
+		plan.live = live
+		current = flatten(live.AsRaw())
+	}
+
+	for _, id := range wanted.groupIDs {
+		group, found := current.groups[id]
+		if !found {
+			plan.Items = append(plan.Items, Item{Action: Create, Kind: KindGroup, ID: id})
+			continue
+		}
+		want := *wanted.groups[id]
+		want.ID = id
+		changes, err := diff.Definitions(*group, want, groupTreeFields...)
+		if err != nil {
+			return nil, err
+		}
+		if !changes.Empty() {
+			plan.Items = append(plan.Items, Item{Action: Update, Kind: KindGroup, ID: id, Changes: changes})
+		}
+	}
+	for _, id := range wanted.appIDs {
+		app, found := current.apps[id]
+		if !found {
+			plan.Items = append(plan.Items, Item{Action: Create, Kind: KindApp, ID: id})
+			continue
+		}
+		changes, err := diff.Definitions(app, wanted.apps[id])
+		if err != nil {
+			return nil, err
+		}
+		if !changes.Empty() {
+			plan.Items = append(plan.Items, Item{Action: Update, Kind: KindApp, ID: id, Changes: changes})
+		}
+	}
+
+	// Live apps and groups out of the desired tree, only the topmost unmanaged group is reported
+	for _, id := range current.groupIDs {
+		if _, found := wanted.groups[id]; !found && !under(id, plan.Unmanaged) {
+			plan.unmanaged(KindGroup, id)
+		}
+	}
+	for _, id := range current.appIDs {
+		if _, found := wanted.apps[id]; !found && !under(id, plan.Unmanaged) {
+			plan.unmanaged(KindApp, id)
+		}
+	}
+	return plan, nil
+}
+
+// Apply sends plan to Marathon
+func (p *Planner) Apply(plan *Plan) error {
+
+	return p.ApplyContext(context.Background(), plan)
+}
+
+// ApplyContext sends plan to Marathon, by default as a single update of the whole tree which
+// fails if the live tree changed since the plan was computed; with Ordered apps are changed one
+// by one in dependency order and deleted last. Honoring ctx
+func (p *Planner) ApplyContext(ctx context.Context, plan *Plan) error {
+
+	if plan == nil || plan.desired == nil {
+		return errors.New("plan cannot be null nor empty")
+	}
+	if plan.Empty() {
+		return nil
+	}
+
+	target, err := plan.target()
+	if err != nil {
+		return err
+	}
+
+	if !p.options.Ordered {
+		if plan.live == nil {
+			return groups.New(p.client).CreateContext(ctx, target)
+		}
+		return plan.live.OnConflict(marathon.FailOnChange).UpdateContext(ctx, target)
+	}
+
+	// Only apps created or updated are sent, in the order of their dependencies
+	changed := make(map[string]bool)
+	for _, item := range plan.Items {
+		if item.Kind == KindApp && item.Action != Delete {
+			changed[item.ID] = true
+		}
+	}
+	if len(changed) > 0 {
+		subset := only(target, "", changed)
+		if err := groups.New(p.client).Set(subset).OnError(groups.ContinueOnError).ApplyContext(ctx, p.options.Force); err != nil {
+			return err
+		}
+	}
+
+	var errs groups.AppErrors
+	for _, item := range plan.Items {
+		if item.Action != Delete {
+			continue
+		}
+		switch item.Kind {
+		case KindApp:
+			err = application.New(p.client).Set(application.AppDefinition{ID: item.ID}).DestroyContext(ctx)
+		case KindGroup:
+			err = groups.New(p.client).Set(&groups.Group{ID: item.ID}).DestroyContext(ctx)
+		}
+		if err != nil && !marathon.IsNotFound(err) {
+			errs = append(errs, groups.AppError{AppID: item.ID, Err: err})
+		}
+	}
+	return errs.Err()
+}
+
+// Empty returns true if the plan has no changes
+func (p *Plan) Empty() bool {
+
+	return len(p.Items) == 0
+}
+
+// Count returns the number of items of each action
+func (p *Plan) Count() (create, update, remove int) {
+
+	for _, item := range p.Items {
+		switch item.Action {
+		case Create:
+			create++
+		case Update:
+			update++
+		case Delete:
+			remove++
+		}
+	}
+	return create, update, remove
+}
+
+// String formats the plan: + creations, ~ updates with their field changes and - deletions
+func (p *Plan) String() string {
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(fmt.Sprintf("Plan for group %s\n", p.Group))
+
+	symbols := map[Action]string{Create: "+", Update: "~", Delete: "-"}
+	for _, item := range p.Items {
+		buffer.WriteString(fmt.Sprintf("  %s %s %s\n", symbols[item.Action], item.Kind, item.ID))
+		for _, change := range item.Changes {
+			buffer.WriteString(fmt.Sprintf("      %s\n", changeLine(change)))
+		}
+	}
+	if !p.Prune && len(p.Unmanaged) > 0 {
+		buffer.WriteString(fmt.Sprintf("Unmanaged, kept without prune: %s\n", strings.Join(p.Unmanaged, ", ")))
+	}
+
+	create, update, remove := p.Count()
+	buffer.WriteString(fmt.Sprintf("Plan: %d to create, %d to update, %d to delete.\n", create, update, remove))
+	return buffer.String()
+}
+
+// unmanaged records a live app or group out of the desired tree
+func (p *Plan) unmanaged(kind, id string) {
+
+	p.Unmanaged = append(p.Unmanaged, id)
+	if p.Prune {
+		p.Items = append(p.Items, Item{Action: Delete, Kind: kind, ID: id})
+	}
+}
+
+// target returns the tree sent to Marathon: the desired one with unmanaged apps and groups
+// kept unless they are pruned, without versions as Marathon takes them as a rollback
+func (p *Plan) target() (*groups.Group, error) {
+
+	target := &groups.Group{}
+	content, err := json.Marshal(p.desired)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, target); err != nil {
+		return nil, err
+	}
+	if p.Prune || p.live == nil || len(p.Unmanaged) == 0 {
+		unversioned(target)
+		return target, nil
+	}
+
+	current := flatten(p.live.AsRaw())
+	for _, id := range p.Unmanaged {
+		// Indexed again every time, appending may move the groups inside the parent
+		parent, found := flatten(target).groups[path.Dir(id)]
+		if !found {
+			return nil, fmt.Errorf("unable to keep %s, its group is not defined", id)
+		}
+		if group, ok := current.groups[id]; ok {
+			parent.Groups = append(parent.Groups, *group)
+			continue
+		}
+		parent.Apps = append(parent.Apps, current.apps[id])
+	}
+	unversioned(target)
+	return target, nil
+}
+
+// unversioned clears the version of group and of every app, pod and group inside it
+func unversioned(group *groups.Group) {
+
+	group.Version, group.VersionInfo = time.Time{}, application.VersionInfo{}
+	delete(group.Extra, "version")
+	delete(group.Extra, "versionInfo")
+
+	for index := range group.Apps {
+		delete(group.Apps[index].Extra, "version")
+		delete(group.Apps[index].Extra, "versionInfo")
+	}
+	for index := range group.Pods {
+		group.Pods[index].Version, group.Pods[index].VersionInfo = nil, nil
+	}
+	for index := range group.Groups {
+		unversioned(&group.Groups[index])
+	}
+}
+
+// changeLine formats a field change of an Update
+func changeLine(change diff.Change) string {
+
+	switch change.Kind {
+	case diff.Added:
+		return fmt.Sprintf("+ %s: %s", change.Path, render(change.To))
+	case diff.Removed:
+		return fmt.Sprintf("- %s: %s", change.Path, render(change.From))
+	}
+	return fmt.Sprintf("~ %s: %s => %s", change.Path, render(change.From), render(change.To))
+}
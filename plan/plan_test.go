@@ -0,0 +1,271 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/diff"
+	"github.com/dotWicho/marathon/groups"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// desiredInfra returns the infra group of the mock server with redis changed, kong-v2 removed
+// and two apps added, one of them in a new group
+func desiredInfra(t *testing.T) *groups.Group {
+
+	group := &groups.Group{}
+	assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), group))
+
+	group.Apps[0].Cpus = group.Apps[0].Cpus + 1
+	group.Apps = append(group.Apps[:1], application.AppDefinition{ID: "new", Cmd: "sleep 100", Cpus: 0.1, Mem: 32, Instances: 1})
+	group.Groups = append(group.Groups, groups.Group{
+		ID:   "/infra/web",
+		Apps: []application.AppDefinition{{ID: "/infra/web/nginx", Cmd: "nginx", Cpus: 0.1, Mem: 64, Instances: 1}},
+	})
+	return group
+}
+
+func TestNew(t *testing.T) {
+
+	assert.Nil(t, New(nil, Options{}))
+	assert.NotNil(t, New(marathon.New("http://127.0.0.1:8080"), Options{}))
+}
+
+func TestPlanner_Plan(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	client := marathon.New(server.URL)
+
+	t.Run("error if group is empty or invalid", func(t *testing.T) {
+
+		_, err := New(client, Options{}).Plan(&groups.Group{})
+		assert.Equal(t, "group cannot be null nor empty", err.Error())
+
+		_, err = New(client, Options{}).Plan(&groups.Group{ID: "/infra", Apps: []application.AppDefinition{{ID: "/other/app"}}})
+		assert.True(t, marathon.IsValidation(err))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+
+		group := &groups.Group{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), group))
+
+		plan, err := New(client, Options{}).Plan(group)
+
+		assert.Nil(t, err)
+		assert.True(t, plan.Empty())
+		assert.Empty(t, plan.Unmanaged)
+		assert.Nil(t, New(client, Options{}).Apply(plan))
+	})
+
+	t.Run("no changes if the live apps carry server defaults", func(t *testing.T) {
+
+		group := &groups.Group{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), group))
+
+		// The definition leaves out what Marathon fills in
+		for index := range group.Apps {
+			app := &group.Apps[index]
+			app.BackoffFactor, app.BackoffSeconds, app.MaxLaunchDelaySeconds, app.KillSelection = 0, 0, 0, ""
+			app.UpgradeStrategy = application.UpgradeStrategy{}
+			app.UnreachableStrategy = application.UnreachableStrategy{}
+			app.AcceptedResourceRoles = nil
+			app.Extra = nil
+			for check := range app.HealthChecks {
+				app.HealthChecks[check].IPProtocol = ""
+			}
+		}
+
+		planner := New(client, Options{})
+		plan, err := planner.Plan(group)
+
+		assert.Nil(t, err)
+		assert.True(t, plan.Empty(), plan.String())
+
+		// Nothing is sent
+		before := mockserver.LastBody()
+		assert.Nil(t, planner.Apply(plan))
+		assert.Equal(t, before, mockserver.LastBody())
+
+		// A field set in the definition is still compared
+		group.Apps[0].Env = map[string]string{"MODE": "cluster"}
+		plan, err = planner.Plan(group)
+		assert.Nil(t, err)
+		assert.Equal(t, []Item{{Action: Update, Kind: KindApp, ID: "/infra/redis", Changes: diff.Changes{
+			{Path: "env.EMPTY", Kind: diff.Removed, From: "true"},
+			{Path: "env.MODE", Kind: diff.Added, To: "cluster"},
+		}}}, plan.Items)
+	})
+
+	t.Run("fields removed from the definition are changes", func(t *testing.T) {
+
+		group := &groups.Group{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), group))
+
+		// The definition drops the env and health checks of an app
+		group.Apps[0].Env = nil
+		group.Apps[0].HealthChecks = nil
+
+		plan, err := New(client, Options{}).Plan(group)
+
+		assert.Nil(t, err)
+		assert.Len(t, plan.Items, 1)
+		assert.Equal(t, "/infra/redis", plan.Items[0].ID)
+		assert.Equal(t, []string{"env", "healthChecks"}, plan.Items[0].Changes.Paths())
+		assert.Equal(t, diff.Removed, plan.Items[0].Changes[0].Kind)
+	})
+
+	t.Run("changes keeping unmanaged apps", func(t *testing.T) {
+
+		plan, err := New(client, Options{}).Plan(desiredInfra(t))
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, "/infra", plan.Group)
+		assert.Equal(t, []Item{
+			{Action: Create, Kind: KindGroup, ID: "/infra/web"},
+			{Action: Update, Kind: KindApp, ID: "/infra/redis", Changes: diff.Changes{{Path: "cpus", Kind: diff.Changed, From: 4.0, To: 5.0}}},
+			{Action: Create, Kind: KindApp, ID: "/infra/new"},
+			{Action: Create, Kind: KindApp, ID: "/infra/web/nginx"},
+		}, plan.Items)
+		assert.Equal(t, []string{"/infra/kong-v2"}, plan.Unmanaged)
+
+		assert.Equal(t, strings.Join([]string{
+			"Plan for group /infra",
+			"  + group /infra/web",
+			"  ~ app /infra/redis",
+			"      ~ cpus: 4 => 5",
+			"  + app /infra/new",
+			"  + app /infra/web/nginx",
+			"Unmanaged, kept without prune: /infra/kong-v2",
+			"Plan: 3 to create, 1 to update, 0 to delete.",
+			"",
+		}, "\n"), plan.String())
+	})
+
+	t.Run("prune unmanaged apps", func(t *testing.T) {
+
+		plan, err := New(client, Options{Prune: true}).Plan(desiredInfra(t))
+
+		assert.Nil(t, err)
+		assert.Equal(t, Item{Action: Delete, Kind: KindApp, ID: "/infra/kong-v2"}, plan.Items[len(plan.Items)-1])
+
+		create, update, remove := plan.Count()
+		assert.Equal(t, []int{3, 1, 1}, []int{create, update, remove})
+	})
+
+	t.Run("plan from a file", func(t *testing.T) {
+
+		// We create out file to read as JSON
+		fileName := "plan-desired.json"
+		content, _ := json.Marshal(desiredInfra(t))
+		assert.Nil(t, ioutil.WriteFile(fileName, content, 0644))
+		defer os.Remove(fileName)
+
+		plan, err := New(client, Options{}).PlanFile(fileName)
+
+		assert.Nil(t, err)
+		assert.Len(t, plan.Items, 4)
+
+		_, err = New(client, Options{}).PlanFile("missing.json")
+		assert.Contains(t, err.Error(), "unable to load group from missing.json: ")
+		assert.NotNil(t, errors.Unwrap(err))
+
+		_, err = New(client, Options{}).PlanFile("plan-desired.conf")
+		assert.Equal(t, "unable to load group from plan-desired.conf: invalid filename extension", err.Error())
+	})
+}
+
+func TestPlanner_Apply(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	client := marathon.New(server.URL)
+
+	// sent returns the ids of the apps of the last group sent
+	sent := func() []string {
+		group := &groups.Group{}
+		assert.Nil(t, json.Unmarshal(mockserver.LastBody(), group))
+		return flatten(group).appIDs
+	}
+
+	t.Run("error if plan is empty", func(t *testing.T) {
+
+		assert.Equal(t, "plan cannot be null nor empty", New(client, Options{}).Apply(nil).Error())
+	})
+
+	t.Run("whole tree in a single update keeping unmanaged apps", func(t *testing.T) {
+
+		planner := New(client, Options{})
+		plan, err := planner.Plan(desiredInfra(t))
+		assert.Nil(t, err)
+
+		// We get not error
+		assert.Nil(t, planner.Apply(plan))
+		assert.ElementsMatch(t, []string{
+			"/infra/redis", "/infra/new", "/infra/kong-v2",
+			"/infra/kafka/broker-0", "/infra/kafka/broker-1", "/infra/kafka/broker-2",
+			"/infra/web/nginx",
+		}, sent())
+	})
+
+	t.Run("versions are not sent, even of kept unmanaged groups", func(t *testing.T) {
+
+		desired := desiredInfra(t)
+		desired.Groups = desired.Groups[len(desired.Groups)-1:]
+
+		planner := New(client, Options{})
+		plan, err := planner.Plan(desired)
+		assert.Nil(t, err)
+		assert.Contains(t, plan.Unmanaged, "/infra/kafka")
+
+		// Marathon takes a version as a rollback
+		assert.Nil(t, planner.Apply(plan))
+		assert.Contains(t, sent(), "/infra/kafka/broker-0")
+		assert.NotContains(t, string(mockserver.LastBody()), `"version"`)
+		assert.NotContains(t, string(mockserver.LastBody()), `"versionInfo"`)
+	})
+
+	t.Run("whole tree in a single update pruning unmanaged apps", func(t *testing.T) {
+
+		planner := New(client, Options{Prune: true})
+		plan, err := planner.Plan(desiredInfra(t))
+		assert.Nil(t, err)
+
+		assert.Nil(t, planner.Apply(plan))
+		assert.NotContains(t, sent(), "/infra/kong-v2")
+	})
+
+	t.Run("app by app", func(t *testing.T) {
+
+		planner := New(client, Options{Prune: true, Ordered: true, Force: true})
+		plan, err := planner.Plan(desiredInfra(t))
+		assert.Nil(t, err)
+
+		assert.Nil(t, planner.Apply(plan))
+	})
+}
+
+func Test_only(t *testing.T) {
+
+	// We define some vars
+	group := desiredInfra(t)
+
+	subset := only(group, "", map[string]bool{"/infra/new": true, "/infra/web/nginx": true})
+
+	assert.Equal(t, []string{"/infra/new", "/infra/web/nginx"}, flatten(subset).appIDs)
+	assert.Equal(t, []string{"/infra", "/infra/web"}, flatten(subset).groupIDs)
+}
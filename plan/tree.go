@@ -0,0 +1,102 @@
+package plan
+
+import (
+	"encoding/json"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/groups"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// tree indexes the apps and groups of a group tree by their absolute ids
+type tree struct {
+	apps     map[string]application.AppDefinition
+	appIDs   []string
+	groups   map[string]*groups.Group
+	groupIDs []string
+}
+
+// flatten returns the tree of root, groups are pointers into root and apps copies with their
+// ids resolved
+func flatten(root *groups.Group) *tree {
+
+	flat := &tree{
+		apps:   make(map[string]application.AppDefinition),
+		groups: make(map[string]*groups.Group),
+	}
+	if len(root.ID) > 0 {
+		flat.add(root, "")
+	}
+	return flat
+}
+
+// add indexes group, whose parent is the group parent, and everything inside it
+func (t *tree) add(group *groups.Group, parent string) {
+
+	id := resolve(parent, group.ID)
+	t.groups[id] = group
+	t.groupIDs = append(t.groupIDs, id)
+
+	for _, app := range group.Apps {
+		app.ID = resolve(id, app.ID)
+		t.apps[app.ID] = app
+		t.appIDs = append(t.appIDs, app.ID)
+	}
+	for index := range group.Groups {
+		t.add(&group.Groups[index], id)
+	}
+}
+
+// only returns a copy of group keeping only the apps in ids, and the groups holding them
+func only(group *groups.Group, parent string, ids map[string]bool) *groups.Group {
+
+	id := resolve(parent, group.ID)
+	subset := &groups.Group{ID: id, Dependencies: group.Dependencies}
+
+	for _, app := range group.Apps {
+		app.ID = resolve(id, app.ID)
+		if ids[app.ID] {
+			subset.Apps = append(subset.Apps, app)
+		}
+	}
+	for index := range group.Groups {
+		if inner := only(&group.Groups[index], id, ids); len(inner.Apps)+len(inner.Groups) > 0 {
+			subset.Groups = append(subset.Groups, *inner)
+		}
+	}
+	return subset
+}
+
+// resolve returns the absolute id of id relative to parent
+func resolve(parent, id string) string {
+
+	if strings.HasPrefix(id, "/") {
+		return path.Clean(id)
+	}
+	return path.Join("/", parent, id)
+}
+
+// under returns true if id is inside any of the groups ids
+func under(id string, ids []string) bool {
+
+	for _, group := range ids {
+		if strings.HasPrefix(id, group+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// render returns the compact JSON representation of value
+func render(value interface{}) string {
+
+	if text, ok := value.(string); ok {
+		return strconv.Quote(text)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "?"
+	}
+	return string(encoded)
+}
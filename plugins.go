@@ -1,5 +1,10 @@
 package marathon
 
+import (
+	"context"
+	"net/http"
+)
+
 // Plugins Array of
 type Plugins struct {
 	Plugins []Plugin `json:"plugins"`
@@ -20,3 +25,30 @@ type PluginInfo struct {
 	Array   []int  `json:"array"`
 	Test    bool   `json:"test"`
 }
+
+// Plugins fetches plugins loaded by the Marathon server
+func (mc *Client) Plugins() (*Plugins, error) {
+
+	return mc.PluginsContext(context.Background())
+}
+
+// PluginsContext fetches plugins loaded by the Marathon server, honoring ctx
+func (mc *Client) PluginsContext(ctx context.Context) (*Plugins, error) {
+
+	plugins := &Plugins{}
+	if err := mc.Do(ctx, http.MethodGet, APIPlugins, nil, nil, plugins, mc.fail); err != nil {
+		return nil, err
+	}
+	return plugins, nil
+}
+
+// Plugin returns a plugin by its id, nil if it is not loaded
+func (mp *Plugins) Plugin(id string) *Plugin {
+
+	for index := range mp.Plugins {
+		if mp.Plugins[index].ID == id {
+			return &mp.Plugins[index]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package pods
+
+import (
+	"encoding/json"
+	"github.com/dotWicho/marathon"
+	"time"
+)
+
+//=== Marathon Pods JSON Entities definition
+
+// PodDefinition encapsulates the data definitions of a Marathon Pod
+type PodDefinition struct {
+	ID                string            `json:"id"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Version           *time.Time        `json:"version,omitempty"`
+	User              string            `json:"user,omitempty"`
+	Environment       map[string]EnvVar `json:"environment,omitempty"`
+	Containers        []Container       `json:"containers"`
+	Secrets           map[string]Secret `json:"secrets,omitempty"`
+	Volumes           []Volume          `json:"volumes,omitempty"`
+	Networks          []Network         `json:"networks,omitempty"`
+	Scaling           *Scaling          `json:"scaling,omitempty"`
+	Scheduling        *Scheduling       `json:"scheduling,omitempty"`
+	ExecutorResources *Resources        `json:"executorResources,omitempty"`
+	Role              string            `json:"role,omitempty"`
+	VersionInfo       *VersionInfo      `json:"versionInfo,omitempty"`
+
+	// Extra holds fields not modeled (or zero valued) in the original payload, re-emitted on marshal
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// EnvVar is a Pod environment variable, a literal value or a reference to a secret
+type EnvVar struct {
+	Value  string
+	Secret string
+}
+
+// Container reflects the data used by the sub-element containers on a Marathon Pod
+type Container struct {
+	Name         string            `json:"name"`
+	Exec         *Exec             `json:"exec,omitempty"`
+	Resources    Resources         `json:"resources"`
+	Endpoints    []Endpoint        `json:"endpoints,omitempty"`
+	Image        *Image            `json:"image,omitempty"`
+	Environment  map[string]EnvVar `json:"environment,omitempty"`
+	User         string            `json:"user,omitempty"`
+	HealthCheck  *HealthCheck      `json:"healthCheck,omitempty"`
+	VolumeMounts []VolumeMount     `json:"volumeMounts,omitempty"`
+	Artifacts    []Artifact        `json:"artifacts,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Lifecycle    *Lifecycle        `json:"lifecycle,omitempty"`
+}
+
+// Exec holds the command run by a Pod container
+type Exec struct {
+	Command Command `json:"command"`
+}
+
+// Command holds a shell command
+type Command struct {
+	Shell string `json:"shell"`
+}
+
+// Resources holds resources requested by a Pod container or executor
+type Resources struct {
+	Cpus float64 `json:"cpus"`
+	Mem  float64 `json:"mem"`
+	Disk float64 `json:"disk,omitempty"`
+	Gpus int     `json:"gpus,omitempty"`
+}
+
+// Endpoint reflects a port exposed by a Pod container
+type Endpoint struct {
+	Name          string            `json:"name"`
+	ContainerPort int               `json:"containerPort,omitempty"`
+	HostPort      int               `json:"hostPort,omitempty"`
+	Protocol      []string          `json:"protocol,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+}
+
+// Image reflects the image run by a Pod container
+type Image struct {
+	Kind       string      `json:"kind"`
+	ID         string      `json:"id"`
+	ForcePull  bool        `json:"forcePull,omitempty"`
+	PullConfig *PullConfig `json:"pullConfig,omitempty"`
+}
+
+// PullConfig holds the secret used to pull an image from a private registry
+type PullConfig struct {
+	Secret string `json:"secret"`
+}
+
+// HealthCheck reflects the health check of a Pod container, only one of HTTP, TCP or Exec is set
+type HealthCheck struct {
+	HTTP                   *HTTPCheck `json:"http,omitempty"`
+	TCP                    *TCPCheck  `json:"tcp,omitempty"`
+	Exec                   *Exec      `json:"exec,omitempty"`
+	GracePeriodSeconds     int        `json:"gracePeriodSeconds,omitempty"`
+	IntervalSeconds        int        `json:"intervalSeconds,omitempty"`
+	MaxConsecutiveFailures int        `json:"maxConsecutiveFailures,omitempty"`
+	TimeoutSeconds         int        `json:"timeoutSeconds,omitempty"`
+	DelaySeconds           int        `json:"delaySeconds,omitempty"`
+}
+
+// HTTPCheck is an HTTP health check against a Pod endpoint
+type HTTPCheck struct {
+	Endpoint string `json:"endpoint"`
+	Path     string `json:"path,omitempty"`
+	Scheme   string `json:"scheme,omitempty"`
+}
+
+// TCPCheck is a TCP health check against a Pod endpoint
+type TCPCheck struct {
+	Endpoint string `json:"endpoint"`
+}
+
+// VolumeMount mounts a Pod volume into a container
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// Artifact is fetched into the Pod container sandbox before launch
+type Artifact struct {
+	URI        string `json:"uri"`
+	Extract    bool   `json:"extract,omitempty"`
+	Executable bool   `json:"executable,omitempty"`
+	Cache      bool   `json:"cache,omitempty"`
+	DestPath   string `json:"destPath,omitempty"`
+}
+
+// Lifecycle holds the kill policy of a Pod container
+type Lifecycle struct {
+	KillGracePeriodSeconds float64 `json:"killGracePeriodSeconds,omitempty"`
+}
+
+// Secret references a secret from the secret store
+type Secret struct {
+	Source string `json:"source"`
+}
+
+// Volume reflects a volume shared by Pod containers, ephemeral if neither Host, Persistent nor Secret is set
+type Volume struct {
+	Name       string            `json:"name"`
+	Host       string            `json:"host,omitempty"`
+	Persistent *PersistentVolume `json:"persistent,omitempty"`
+	Secret     string            `json:"secret,omitempty"`
+}
+
+// PersistentVolume holds a local persistent volume definition
+type PersistentVolume struct {
+	Type        string   `json:"type,omitempty"`
+	Size        int      `json:"size"`
+	MaxSize     int      `json:"maxSize,omitempty"`
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// Network reflects a network joined by the Pod
+type Network struct {
+	Name   string            `json:"name,omitempty"`
+	Mode   string            `json:"mode"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Scaling holds the instances policy of a Pod
+type Scaling struct {
+	Kind         string `json:"kind"`
+	Instances    int    `json:"instances"`
+	MaxInstances int    `json:"maxInstances,omitempty"`
+}
+
+// Scheduling holds the placement, backoff and upgrade policies of a Pod
+type Scheduling struct {
+	Backoff             *Backoff             `json:"backoff,omitempty"`
+	Upgrade             *Upgrade             `json:"upgrade,omitempty"`
+	Placement           *Placement           `json:"placement,omitempty"`
+	KillSelection       string               `json:"killSelection,omitempty"`
+	UnreachableStrategy *UnreachableStrategy `json:"unreachableStrategy,omitempty"`
+}
+
+// Backoff holds the launch backoff policy of a Pod
+type Backoff struct {
+	Backoff        float64 `json:"backoff"`
+	BackoffFactor  float64 `json:"backoffFactor"`
+	MaxLaunchDelay float64 `json:"maxLaunchDelay"`
+}
+
+// Upgrade holds the upgrade policy of a Pod
+type Upgrade struct {
+	MinimumHealthCapacity float64 `json:"minimumHealthCapacity"`
+	MaximumOverCapacity   float64 `json:"maximumOverCapacity"`
+}
+
+// Placement holds the constraints and roles used to place Pod instances
+type Placement struct {
+	Constraints           []Constraint `json:"constraints,omitempty"`
+	AcceptedResourceRoles []string     `json:"acceptedResourceRoles,omitempty"`
+}
+
+// Constraint is a placement constraint of a Pod
+type Constraint struct {
+	FieldName string `json:"fieldName"`
+	Operator  string `json:"operator"`
+	Value     string `json:"value,omitempty"`
+}
+
+// UnreachableStrategy reflects the data used by the sub-element unreachableStrategy on a Marathon Pod
+type UnreachableStrategy struct {
+	InactiveAfterSeconds int `json:"inactiveAfterSeconds"`
+	ExpungeAfterSeconds  int `json:"expungeAfterSeconds"`
+}
+
+// VersionInfo reflects the data used by the sub-element versionInfo on a Marathon Pod
+type VersionInfo struct {
+	LastScalingAt      time.Time `json:"lastScalingAt"`
+	LastConfigChangeAt time.Time `json:"lastConfigChangeAt"`
+}
+
+//=== Marathon Pods status JSON Entities definition
+
+// PodStatus reflects the runtime state of a Marathon Pod returned by /v2/pods/{id}::status
+type PodStatus struct {
+	ID                 string              `json:"id"`
+	Spec               PodDefinition       `json:"spec"`
+	Status             string              `json:"status"`
+	StatusSince        time.Time           `json:"statusSince"`
+	Message            string              `json:"message,omitempty"`
+	Instances          []InstanceStatus    `json:"instances,omitempty"`
+	TerminationHistory []TerminationRecord `json:"terminationHistory,omitempty"`
+	LastUpdated        time.Time           `json:"lastUpdated"`
+	LastChanged        time.Time           `json:"lastChanged"`
+}
+
+// InstanceStatus reflects the runtime state of a Pod instance
+type InstanceStatus struct {
+	ID            string            `json:"id"`
+	Status        string            `json:"status"`
+	StatusSince   time.Time         `json:"statusSince"`
+	Message       string            `json:"message,omitempty"`
+	AgentHostname string            `json:"agentHostname,omitempty"`
+	AgentID       string            `json:"agentId,omitempty"`
+	Resources     *Resources        `json:"resources,omitempty"`
+	Networks      []NetworkStatus   `json:"networks,omitempty"`
+	Containers    []ContainerStatus `json:"containers,omitempty"`
+	SpecReference string            `json:"specReference,omitempty"`
+	LastUpdated   time.Time         `json:"lastUpdated"`
+	LastChanged   time.Time         `json:"lastChanged"`
+}
+
+// NetworkStatus holds addresses of a Pod instance on a network
+type NetworkStatus struct {
+	Name      string   `json:"name,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// ContainerStatus reflects the runtime state of a Pod instance container
+type ContainerStatus struct {
+	Name        string               `json:"name"`
+	Status      string               `json:"status"`
+	StatusSince time.Time            `json:"statusSince"`
+	Message     string               `json:"message,omitempty"`
+	ContainerID string               `json:"containerId,omitempty"`
+	Conditions  []StatusCondition    `json:"conditions,omitempty"`
+	Endpoints   []ContainerEndpoint  `json:"endpoints,omitempty"`
+	Resources   *Resources           `json:"resources,omitempty"`
+	Termination *ContainerTerminated `json:"termination,omitempty"`
+	LastUpdated time.Time            `json:"lastUpdated"`
+	LastChanged time.Time            `json:"lastChanged"`
+}
+
+// StatusCondition holds a condition of a Pod container, e.g. healthy
+type StatusCondition struct {
+	Name        string    `json:"name"`
+	Value       string    `json:"value"`
+	Reason      string    `json:"reason,omitempty"`
+	LastChanged time.Time `json:"lastChanged"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// ContainerEndpoint holds the host port allocated to a container endpoint
+type ContainerEndpoint struct {
+	Name              string `json:"name"`
+	AllocatedHostPort int    `json:"allocatedHostPort,omitempty"`
+	Healthy           *bool  `json:"healthy,omitempty"`
+}
+
+// ContainerTerminated holds the termination of a Pod container
+type ContainerTerminated struct {
+	ExitCode int    `json:"exitCode"`
+	Message  string `json:"message,omitempty"`
+}
+
+// TerminationRecord holds a terminated Pod instance
+type TerminationRecord struct {
+	InstanceID   string                  `json:"instanceId"`
+	StartedAt    time.Time               `json:"startedAt"`
+	TerminatedAt time.Time               `json:"terminatedAt"`
+	Message      string                  `json:"message,omitempty"`
+	Containers   []ContainerTerminatedAt `json:"containers,omitempty"`
+}
+
+// ContainerTerminatedAt holds the termination of a container of a terminated Pod instance
+type ContainerTerminatedAt struct {
+	ContainerID    string              `json:"containerId"`
+	LastKnownState string              `json:"lastKnownState"`
+	Termination    ContainerTerminated `json:"termination"`
+}
+
+// UnmarshalJSON decodes a PodDefinition keeping fields not modeled into Extra
+func (pd *PodDefinition) UnmarshalJSON(content []byte) error {
+
+	type podDefinition PodDefinition
+	if err := json.Unmarshal(content, (*podDefinition)(pd)); err != nil {
+		return err
+	}
+
+	extra, err := marathon.UnknownFields(content, pd)
+	pd.Extra = extra
+	return err
+}
+
+// MarshalJSON encodes a PodDefinition including fields kept into Extra
+func (pd PodDefinition) MarshalJSON() ([]byte, error) {
+
+	type podDefinition PodDefinition
+	encoded, err := json.Marshal(podDefinition(pd))
+	if err != nil {
+		return nil, err
+	}
+	return marathon.MergeFields(encoded, pd.Extra)
+}
+
+// MarshalJSON encodes an EnvVar as a string, or as {"secret": name} if it references a secret
+func (ev EnvVar) MarshalJSON() ([]byte, error) {
+
+	if len(ev.Secret) > 0 {
+		return json.Marshal(map[string]string{"secret": ev.Secret})
+	}
+	return json.Marshal(ev.Value)
+}
+
+// UnmarshalJSON decodes an EnvVar from a string or a {"secret": name} reference
+func (ev *EnvVar) UnmarshalJSON(content []byte) error {
+
+	if err := json.Unmarshal(content, &ev.Value); err == nil {
+		ev.Secret = ""
+		return nil
+	}
+
+	var reference struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(content, &reference); err != nil {
+		return err
+	}
+	ev.Value = ""
+	ev.Secret = reference.Secret
+	return nil
+}
@@ -0,0 +1,472 @@
+package pods
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/data"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//===
+
+// Pod status values reported by Marathon
+const (
+	StatusStable    string = "STABLE"
+	StatusDegraded  string = "DEGRADED"
+	StatusDeploying string = "DEPLOYING"
+	StatusTerminal  string = "TERMINAL"
+)
+
+// Marathon Pod interface
+type pod interface {
+	Get(id string) *Pod
+	GetContext(ctx context.Context, id string) *Pod
+	Set(pod PodDefinition) *Pod
+	Create(pod PodDefinition) *Pod
+	CreateContext(ctx context.Context, pod PodDefinition) *Pod
+	Destroy(force bool) error
+	DestroyContext(ctx context.Context, force bool) error
+	Update(pod PodDefinition) error
+	UpdateContext(ctx context.Context, pod PodDefinition) error
+
+	Instances() int
+
+	Scale(instances int, force bool) error
+	ScaleContext(ctx context.Context, instances int, force bool) error
+	Stop(force bool) error
+	StopContext(ctx context.Context, force bool) error
+	Start(instances int, force bool) error
+	StartContext(ctx context.Context, instances int, force bool) error
+
+	Status() (*PodStatus, error)
+	StatusContext(ctx context.Context) (*PodStatus, error)
+	KillInstance(instanceID string) error
+	KillInstanceContext(ctx context.Context, instanceID string) error
+
+	Versions() []string
+	VersionsContext(ctx context.Context) []string
+	LastVersion() string
+	LastVersionContext(ctx context.Context) string
+	Config(version string) *Pod
+	ConfigContext(ctx context.Context, version string) *Pod
+
+	Load(fileName string) *Pod
+	Dump(fileName string) error
+
+	LastError() error
+
+	Apply(force bool) error
+	ApplyContext(ctx context.Context, force bool) error
+}
+
+// Pod is a Marathon Pod implementation
+type Pod struct {
+	client *marathon.Client
+
+	//
+	pod *PodDefinition
+
+	//
+	fail *data.FailureMessage
+	err  error
+}
+
+// New returns a new instance of Marathon pod implementation
+func New(client *marathon.Client) *Pod {
+
+	if client != nil {
+		return &Pod{
+			client: client,
+			pod:    &PodDefinition{},
+			fail:   &data.FailureMessage{},
+		}
+	}
+	return nil
+}
+
+//=== Marathon Pod methods
+
+// Get allows to establish the internal structures to referenced id
+func (mp *Pod) Get(id string) *Pod {
+
+	return mp.GetContext(context.Background(), id)
+}
+
+// GetContext allows to establish the internal structures to referenced id, honoring ctx
+func (mp *Pod) GetContext(ctx context.Context, id string) *Pod {
+
+	if len(id) > 0 {
+		mp.clear()
+
+		if mp.err = mp.client.Do(ctx, http.MethodGet, podPath(id, ""), nil, nil, mp.pod, mp.fail); mp.err != nil {
+			marathon.Logger.Debug("Pod: Get failed [%+v]", mp.err)
+			mp.clear()
+		}
+	}
+	return mp
+}
+
+// Set allows to establish the internal structures from a given pod
+func (mp *Pod) Set(pod PodDefinition) *Pod {
+
+	if len(pod.ID) > 0 {
+		marathon.Logger.Debug("Pod: Set id = %s", pod.ID)
+		mp.clear()
+		*mp.pod = pod
+	}
+	return mp
+}
+
+// Create allows create a Marathon pod into server
+func (mp *Pod) Create(pod PodDefinition) *Pod {
+
+	return mp.CreateContext(context.Background(), pod)
+}
+
+// CreateContext allows create a Marathon pod into server, honoring ctx
+func (mp *Pod) CreateContext(ctx context.Context, pod PodDefinition) *Pod {
+
+	if len(pod.ID) > 0 {
+		marathon.Logger.Debug("Pod: Create id = [%s] body = %+v", pod.ID, pod)
+
+		*mp.pod = pod
+		mp.err = mp.ApplyContext(ctx, true)
+	}
+	return mp
+}
+
+// Destroy erase a Marathon pod from server
+func (mp *Pod) Destroy(force bool) error {
+
+	return mp.DestroyContext(context.Background(), force)
+}
+
+// DestroyContext erase a Marathon pod from server, honoring ctx
+func (mp *Pod) DestroyContext(ctx context.Context, force bool) error {
+
+	if len(mp.pod.ID) > 0 {
+		marathon.Logger.Debug("Pod: Destroy id = [%s]", mp.pod.ID)
+
+		path := podPath(mp.pod.ID, "")
+
+		mp.clear()
+		if err := mp.client.Do(ctx, http.MethodDelete, path, forceParams(force), nil, nil, mp.fail); err != nil {
+			marathon.Logger.Debug("Pod: Destroy failed [%+v]", err)
+			return err
+		}
+		return nil
+	}
+	return errors.New("pod cannot be null nor empty")
+}
+
+// Update allows change values into Marathon pod
+func (mp *Pod) Update(pod PodDefinition) error {
+
+	return mp.UpdateContext(context.Background(), pod)
+}
+
+// UpdateContext allows change values into Marathon pod, honoring ctx
+func (mp *Pod) UpdateContext(ctx context.Context, pod PodDefinition) error {
+
+	if len(pod.ID) > 0 {
+		marathon.Logger.Debug("Pod: Update id = [%s] body = %+v", pod.ID, pod)
+
+		*mp.pod = pod
+		return mp.ApplyContext(ctx, true)
+	}
+	return errors.New("pod cannot be null nor empty")
+}
+
+// Instances return actual instances of a Marathon pod
+func (mp *Pod) Instances() int {
+
+	if len(mp.pod.ID) > 0 {
+		if mp.pod.Scaling == nil {
+			// Marathon runs a single instance of pods without scaling policy
+			return 1
+		}
+		return mp.pod.Scaling.Instances
+	}
+	return -1
+}
+
+// Scale allows change instances numbers of a Marathon pod
+func (mp *Pod) Scale(instances int, force bool) error {
+
+	return mp.ScaleContext(context.Background(), instances, force)
+}
+
+// ScaleContext allows change instances numbers of a Marathon pod, honoring ctx
+func (mp *Pod) ScaleContext(ctx context.Context, instances int, force bool) error {
+
+	if len(mp.pod.ID) > 0 {
+		marathon.Logger.Debug("Pod: Scale %s to %d force=%v", mp.pod.ID, instances, force)
+
+		if mp.pod.Scaling == nil {
+			mp.pod.Scaling = &Scaling{Kind: "fixed"}
+		}
+		mp.pod.Scaling.Instances = instances
+
+		return mp.ApplyContext(ctx, force)
+	}
+	return errors.New("pod cannot be null nor empty")
+}
+
+// Start sets instances of a Marathon pod to a number provided
+func (mp *Pod) Start(instances int, force bool) error {
+
+	return mp.Scale(instances, force)
+}
+
+// StartContext sets instances of a Marathon pod to a number provided, honoring ctx
+func (mp *Pod) StartContext(ctx context.Context, instances int, force bool) error {
+
+	return mp.ScaleContext(ctx, instances, force)
+}
+
+// Stop sets instances of a Marathon pod to 0
+func (mp *Pod) Stop(force bool) error {
+
+	return mp.Scale(0, force)
+}
+
+// StopContext sets instances of a Marathon pod to 0, honoring ctx
+func (mp *Pod) StopContext(ctx context.Context, force bool) error {
+
+	return mp.ScaleContext(ctx, 0, force)
+}
+
+// Status returns the runtime state of a Marathon pod and its instances
+func (mp *Pod) Status() (*PodStatus, error) {
+
+	return mp.StatusContext(context.Background())
+}
+
+// StatusContext returns the runtime state of a Marathon pod and its instances, honoring ctx
+func (mp *Pod) StatusContext(ctx context.Context) (*PodStatus, error) {
+
+	if len(mp.pod.ID) > 0 {
+
+		status := &PodStatus{}
+		if err := mp.client.Do(ctx, http.MethodGet, podPath(mp.pod.ID, "::status"), nil, nil, status, mp.fail); err != nil {
+			return nil, err
+		}
+		return status, nil
+	}
+	return nil, errors.New("pod cannot be null nor empty")
+}
+
+// KillInstance kills an instance of a Marathon pod, Marathon launches a replacement
+func (mp *Pod) KillInstance(instanceID string) error {
+
+	return mp.KillInstanceContext(context.Background(), instanceID)
+}
+
+// KillInstanceContext kills an instance of a Marathon pod, honoring ctx
+func (mp *Pod) KillInstanceContext(ctx context.Context, instanceID string) error {
+
+	if len(mp.pod.ID) > 0 && len(instanceID) > 0 {
+
+		path := podPath(mp.pod.ID, "::instances/"+instanceID)
+
+		if err := mp.client.Do(ctx, http.MethodDelete, path, nil, nil, nil, mp.fail); err != nil {
+			return err
+		}
+		return nil
+	}
+	return errors.New("pod and instance cannot be null nor empty")
+}
+
+// Versions returns all configurations versions of the pod
+func (mp *Pod) Versions() []string {
+
+	return mp.VersionsContext(context.Background())
+}
+
+// VersionsContext returns all configurations versions of the pod, honoring ctx. The pod is kept
+// if they cannot be read
+func (mp *Pod) VersionsContext(ctx context.Context) []string {
+
+	if len(mp.pod.ID) > 0 {
+
+		versions := make([]string, 0)
+
+		if mp.err = mp.client.Do(ctx, http.MethodGet, podPath(mp.pod.ID, "::versions"), nil, nil, &versions, mp.fail); mp.err != nil {
+			marathon.Logger.Debug("Pod: Versions failed [%+v]", mp.err)
+		}
+
+		if len(versions) > 0 {
+			return versions
+		}
+	}
+	return nil
+}
+
+// LastVersion returns last version of the pod
+func (mp *Pod) LastVersion() string {
+
+	return mp.LastVersionContext(context.Background())
+}
+
+// LastVersionContext returns last version of the pod, honoring ctx. Versions are compared as
+// times and the pod is kept if they cannot be read
+func (mp *Pod) LastVersionContext(ctx context.Context) string {
+
+	if len(mp.pod.ID) > 0 {
+		var versions []string
+		if versions, mp.err = mp.versions(ctx); len(versions) > 0 {
+			return versions[len(versions)-1]
+		}
+	}
+	return ""
+}
+
+// versions returns the versions of the cached pod from the oldest to the newest, the cached pod
+// is not changed
+func (mp *Pod) versions(ctx context.Context) ([]string, error) {
+
+	var versions []string
+	if err := mp.client.Do(ctx, http.MethodGet, podPath(mp.pod.ID, "::versions"), nil, nil, &versions, mp.fail); err != nil {
+		marathon.Logger.Debug("Pod: Versions failed [%+v]", err)
+		return nil, err
+	}
+
+	// Timestamps are compared as times, their fractional seconds may have different lengths
+	times := make(map[string]time.Time, len(versions))
+	for _, version := range versions {
+		parsed, err := time.Parse(time.RFC3339Nano, version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %s of the Marathon pod %s: %w", version, mp.pod.ID, err)
+		}
+		times[version] = parsed
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return times[versions[i]].Before(times[versions[j]])
+	})
+	return versions, nil
+}
+
+// Config returns a PodDefinition based on it version
+func (mp *Pod) Config(version string) *Pod {
+
+	return mp.ConfigContext(context.Background(), version)
+}
+
+// ConfigContext returns a PodDefinition based on it version, honoring ctx
+func (mp *Pod) ConfigContext(ctx context.Context, version string) *Pod {
+
+	if len(mp.pod.ID) > 0 {
+
+		path := podPath(mp.pod.ID, "::versions/"+version)
+
+		if mp.err = mp.client.Do(ctx, http.MethodGet, path, nil, nil, mp.pod, mp.fail); mp.err != nil {
+			marathon.Logger.Debug("Pod: Config failed [%+v]", mp.err)
+			mp.clear()
+		}
+	}
+	return mp
+}
+
+// Load allows create or update a Marathon pod from file
+func (mp *Pod) Load(fileName string) *Pod {
+
+	mp.clear()
+
+	switch filepath.Ext(strings.TrimSpace(fileName)) {
+	case ".json":
+		mp.err = utilities.LoadDataFromJSON(mp.pod, fileName)
+	case ".yaml":
+		mp.err = marathon.LoadYAML(fileName, mp.pod)
+	default:
+		mp.err = fmt.Errorf("invalid filename extension")
+	}
+
+	if mp.err != nil {
+		mp.clear()
+	}
+	return mp
+}
+
+// Dump allows to create a .json or .yaml file with the configuration of a Marathon pod
+func (mp *Pod) Dump(fileName string) (err error) {
+
+	if len(mp.pod.ID) > 0 {
+
+		switch filepath.Ext(strings.TrimSpace(fileName)) {
+		case ".yaml":
+			err = utilities.WriteDataToYAML(mp.pod, fileName)
+		default:
+			err = utilities.WriteDataToJSON(mp.pod, fileName)
+		}
+		return
+	}
+	return errors.New("pod cannot be null nor empty")
+}
+
+// Apply allows send all changes of a Marathon pod to Marathon server, creating it if needed
+func (mp *Pod) Apply(force bool) error {
+
+	return mp.ApplyContext(context.Background(), force)
+}
+
+// ApplyContext allows send all changes of a Marathon pod to Marathon server, honoring ctx
+func (mp *Pod) ApplyContext(ctx context.Context, force bool) error {
+
+	if len(mp.pod.ID) > 0 {
+
+		path := podPath(mp.pod.ID, "")
+
+		marathon.Logger.Debug("Pod: Apply(%v)[%+v] %s", force, mp.pod, path)
+
+		if err := mp.client.Do(ctx, http.MethodPut, path, forceParams(force), mp.pod, nil, mp.fail); err != nil {
+			marathon.Logger.Debug("Pod: Apply StatusCode: %d {%+v}{%+v}", mp.client.StatusCode(), mp.fail, err)
+			return err
+		}
+		return nil
+	}
+	return errors.New("pod cannot be null nor empty")
+}
+
+// LastError returns the error of last Get, Create, Versions, Config or Load, nil if it was successful
+func (mp *Pod) LastError() error {
+
+	return mp.err
+}
+
+// AsRaw returns PodDefinition content of current Pod
+func (mp *Pod) AsRaw() PodDefinition {
+
+	if len(mp.pod.ID) > 0 {
+		return *mp.pod
+	}
+	return PodDefinition{}
+}
+
+// podPath returns the path of a pod resource, suffix selects a sub resource (e.g. ::status)
+func podPath(id, suffix string) string {
+
+	return fmt.Sprintf("%s%s%s", marathon.APIPods, utilities.DelInitialSlash(id), suffix)
+}
+
+// forceParams returns the query params needed to force a change
+func forceParams(force bool) url.Values {
+
+	if force {
+		return url.Values{"force": []string{"true"}}
+	}
+	return nil
+}
+
+// clear set internal data to his defaults
+func (mp *Pod) clear() {
+
+	mp.pod = &PodDefinition{}
+}
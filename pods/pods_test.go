@@ -0,0 +1,226 @@
+package pods
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func Test_New(t *testing.T) {
+
+	t.Run("nil Pod if send nil client", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(nil)
+
+		// Pod is nil
+		assert.Nil(t, _pod)
+	})
+
+	t.Run("valid Pod if send valid client", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(marathon.New("http://127.0.0.1:8080"))
+
+		// Pod is not nil
+		assert.NotNil(t, _pod)
+	})
+}
+
+func TestPod_Get(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get a valid Pod", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(marathon.New(server.URL)).Get("/infra/web")
+
+		// We get not error
+		assert.Nil(t, _pod.LastError())
+
+		// Check some values of the definition
+		pod := _pod.AsRaw()
+		assert.Equal(t, "/infra/web", pod.ID)
+		assert.Equal(t, 2, _pod.Instances())
+		assert.Len(t, pod.Containers, 2)
+		assert.Equal(t, "docker.io/nginx:1.19", pod.Containers[0].Image.ID)
+		assert.Equal(t, "/health", pod.Containers[0].HealthCheck.HTTP.Path)
+		assert.Equal(t, "sleep infinity", pod.Containers[1].Exec.Command.Shell)
+		assert.Equal(t, "UNIQUE", pod.Scheduling.Placement.Constraints[0].Operator)
+		assert.Equal(t, EnvVar{Value: "info"}, pod.Environment["LOG_LEVEL"])
+		assert.Equal(t, EnvVar{Secret: "db"}, pod.Environment["DB_PASSWORD"])
+		assert.Equal(t, "/infra/db-password", pod.Secrets["db"].Source)
+	})
+
+	t.Run("get a missing Pod", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(marathon.New(server.URL)).Get("/infra/missing")
+
+		// We get a not found error and an empty Pod
+		assert.True(t, marathon.IsNotFound(_pod.LastError()))
+		assert.Equal(t, -1, _pod.Instances())
+	})
+}
+
+func TestPod_Apply(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("error if pod is empty", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(marathon.New(server.URL))
+
+		// Every change requires a pod
+		assert.NotNil(t, _pod.Apply(false))
+		assert.NotNil(t, _pod.Scale(1, false))
+		assert.NotNil(t, _pod.Destroy(false))
+		assert.NotNil(t, _pod.Update(PodDefinition{}))
+		assert.NotNil(t, _pod.KillInstance("infra_web.instance-c9de6033"))
+	})
+
+	t.Run("create, scale and destroy a Pod", func(t *testing.T) {
+
+		pod := PodDefinition{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.SomePod), &pod))
+
+		// Try to create Pod
+		_pod := New(marathon.New(server.URL)).Create(pod)
+		assert.Nil(t, _pod.LastError())
+
+		// Scale it
+		assert.Nil(t, _pod.ScaleContext(context.Background(), 3, true))
+		assert.Equal(t, 3, _pod.Instances())
+
+		// Destroy it
+		assert.Nil(t, _pod.Destroy(true))
+		assert.Equal(t, -1, _pod.Instances())
+	})
+
+	t.Run("scale a Pod without scaling policy", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(marathon.New(server.URL)).Set(PodDefinition{ID: "/infra/web"})
+		assert.Equal(t, 1, _pod.Instances())
+
+		// Stop it
+		assert.Nil(t, _pod.Stop(false))
+		assert.Equal(t, "fixed", _pod.AsRaw().Scaling.Kind)
+		assert.Equal(t, 0, _pod.Instances())
+	})
+}
+
+func TestPod_Status(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Try to create Pod
+	_pod := New(marathon.New(server.URL)).Get("/infra/web")
+
+	t.Run("get status of a Pod", func(t *testing.T) {
+
+		status, err := _pod.Status()
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, StatusStable, status.Status)
+		assert.Len(t, status.Instances, 1)
+		assert.Equal(t, 31001, status.Instances[0].Containers[0].Endpoints[0].AllocatedHostPort)
+	})
+
+	t.Run("kill an instance of a Pod", func(t *testing.T) {
+
+		assert.Nil(t, _pod.KillInstance("infra_web.instance-c9de6033"))
+	})
+}
+
+func TestPod_Versions(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("last version is the newest as a time", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(marathon.New(server.URL)).Get("/infra/web")
+
+		assert.Len(t, _pod.Versions(), 2)
+		// Compared as strings 42.7Z would be the newest
+		assert.Equal(t, "2021-01-21T20:27:42.725Z", _pod.LastVersion())
+
+		// Get config of last version
+		_pod = _pod.Config(_pod.LastVersion())
+		assert.Nil(t, _pod.LastError())
+		assert.Equal(t, "/infra/web", _pod.AsRaw().ID)
+	})
+
+	t.Run("the cached pod is kept if versions fail", func(t *testing.T) {
+
+		// A server that is gone
+		gone := mockserver.MockServer()
+		gone.Close()
+		_pod := New(marathon.New(gone.URL)).Set(PodDefinition{ID: "/infra/web"})
+
+		assert.Nil(t, _pod.Versions())
+		assert.NotNil(t, _pod.LastError())
+		assert.Empty(t, _pod.LastVersion())
+		assert.NotNil(t, _pod.LastError())
+		assert.Equal(t, "/infra/web", _pod.AsRaw().ID)
+	})
+}
+
+func TestPod_LoadDump(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("error when Dump is called with pod empty", func(t *testing.T) {
+
+		// Try to create Pod
+		err := New(marathon.New(server.URL)).Dump("dumpfile-pod.json")
+
+		// Error must be "pod cannot be null nor empty"
+		assert.NotNil(t, err)
+		assert.Equal(t, "pod cannot be null nor empty", err.Error())
+	})
+
+	t.Run("error when Load is called with invalid file", func(t *testing.T) {
+
+		// Try to create Pod
+		_pod := New(marathon.New(server.URL)).Load("dumpfile-pod.txt")
+
+		// Pod must be empty
+		assert.NotNil(t, _pod.LastError())
+		assert.Empty(t, _pod.AsRaw().ID)
+	})
+
+	t.Run("dump and load a valid Pod", func(t *testing.T) {
+
+		// We define some vars
+		fileName := "dumpfile-pod.json"
+		defer os.Remove(fileName)
+
+		// Dump it
+		_pod := New(marathon.New(server.URL)).Get("/infra/web")
+		assert.Nil(t, _pod.Dump(fileName))
+
+		// Load it
+		_loaded := New(marathon.New(server.URL)).Load(fileName)
+		assert.Nil(t, _loaded.LastError())
+		assert.Equal(t, _pod.AsRaw(), _loaded.AsRaw())
+	})
+}
@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Reasons given by Marathon when an offer is declined
+const (
+	ReasonUnfulfilledRole                 string = "UnfulfilledRole"
+	ReasonUnfulfilledConstraint           string = "UnfulfilledConstraint"
+	ReasonNoCorrespondingReservationFound string = "NoCorrespondingReservationFound"
+	ReasonAgentMaintenance                string = "AgentMaintenance"
+	ReasonInsufficientCpus                string = "InsufficientCpus"
+	ReasonInsufficientMemory              string = "InsufficientMemory"
+	ReasonInsufficientDisk                string = "InsufficientDisk"
+	ReasonInsufficientGpus                string = "InsufficientGpus"
+	ReasonInsufficientPorts               string = "InsufficientPorts"
+	ReasonDeclinedScarceResources         string = "DeclinedScarceResources"
+)
+
+// explanations holds a human readable explanation of each decline reason
+var explanations = map[string]string{
+	ReasonUnfulfilledRole:                 "offers do not carry resources of the app role",
+	ReasonUnfulfilledConstraint:           "agents do not match the app placement constraints",
+	ReasonNoCorrespondingReservationFound: "no reservation was found for the app resident tasks",
+	ReasonAgentMaintenance:                "agents are under maintenance",
+	ReasonInsufficientCpus:                "agents do not have enough CPUs",
+	ReasonInsufficientMemory:              "agents do not have enough memory",
+	ReasonInsufficientDisk:                "agents do not have enough disk",
+	ReasonInsufficientGpus:                "agents do not have enough GPUs",
+	ReasonInsufficientPorts:               "agents do not have the requested ports available",
+	ReasonDeclinedScarceResources:         "offers carry scarce resources (GPUs) the app does not use",
+}
+
+// Reason holds how many offers were declined by the same reason
+type Reason struct {
+	Reason      string
+	Count       int
+	Explanation string
+}
+
+// Diagnosis explains why an App is waiting on the launch queue
+type Diagnosis struct {
+	AppID           string
+	Waiting         int
+	Delayed         bool
+	TimeLeftSeconds int
+	Processed       int
+	Unused          int
+	Reasons         []Reason
+}
+
+// Diagnose returns a Diagnosis of every App on the launch queue
+func (mq *Queue) Diagnose() []*Diagnosis {
+
+	var diagnoses []*Diagnosis
+	for index := range mq.queue.Queue {
+		diagnoses = append(diagnoses, diagnose(&mq.queue.Queue[index]))
+	}
+	return diagnoses
+}
+
+// DiagnoseApp returns a Diagnosis of an App, nil if the App is not waiting
+func (mq *Queue) DiagnoseApp(appID string) *Diagnosis {
+
+	if entry := mq.App(appID); entry != nil {
+		return diagnose(entry)
+	}
+	return nil
+}
+
+// diagnose aggregates decline reasons of a queue entry, last unused offers are preferred
+// and reject summary of last offers is used when Marathon does not embed them
+func diagnose(entry *Type) *Diagnosis {
+
+	diagnosis := &Diagnosis{
+		AppID:           entry.App.ID,
+		Waiting:         entry.Count,
+		Delayed:         entry.Delay.TimeLeftSeconds > 0 && !entry.Delay.Overdue,
+		TimeLeftSeconds: entry.Delay.TimeLeftSeconds,
+		Processed:       entry.ProcessedOffersSummary.ProcessedOffersCount,
+		Unused:          entry.ProcessedOffersSummary.UnusedOffersCount,
+	}
+
+	counts := make(map[string]int)
+	for _, offer := range entry.LastUnusedOffers {
+		for _, reason := range offer.Reason {
+			counts[reason]++
+		}
+	}
+	if len(counts) == 0 {
+		for _, summary := range entry.ProcessedOffersSummary.RejectSummaryLastOffers {
+			if summary.Declined > 0 {
+				counts[summary.Reason] += summary.Declined
+			}
+		}
+	}
+
+	for reason, count := range counts {
+		explanation, ok := explanations[reason]
+		if !ok {
+			explanation = "offers declined by Marathon"
+		}
+		diagnosis.Reasons = append(diagnosis.Reasons, Reason{Reason: reason, Count: count, Explanation: explanation})
+	}
+	// Most frequent reasons first, it is likely the one blocking the App
+	sort.Slice(diagnosis.Reasons, func(i, j int) bool {
+		if diagnosis.Reasons[i].Count != diagnosis.Reasons[j].Count {
+			return diagnosis.Reasons[i].Count > diagnosis.Reasons[j].Count
+		}
+		return diagnosis.Reasons[i].Reason < diagnosis.Reasons[j].Reason
+	})
+	return diagnosis
+}
+
+// String returns a human readable explanation of the Diagnosis
+func (d *Diagnosis) String() string {
+
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s: %d instance(s) waiting to be launched", d.AppID, d.Waiting)
+	if d.Delayed {
+		fmt.Fprintf(&builder, ", launch delayed %ds by backoff (reset the delay to retry now)", d.TimeLeftSeconds)
+	}
+	fmt.Fprintf(&builder, ", %d of %d processed offers declined", d.Unused, d.Processed)
+
+	if len(d.Reasons) == 0 {
+		builder.WriteString(", no decline reasons reported")
+		return builder.String()
+	}
+	for _, reason := range d.Reasons {
+		fmt.Fprintf(&builder, "\n  - %s (%d offers): %s", reason.Reason, reason.Count, reason.Explanation)
+	}
+	return builder.String()
+}
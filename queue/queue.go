@@ -1,11 +1,46 @@
 package queue
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/dotWicho/marathon"
 	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/data"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"net/url"
 	"time"
 )
 
+//===
+
+// Marathon Queue interface
+type queue interface {
+	Get() (*Queue, error)
+	GetContext(ctx context.Context) (*Queue, error)
+	App(appID string) *Type
+	AsRaw() []Type
+
+	ResetDelay(appID string) error
+	ResetDelayContext(ctx context.Context, appID string) error
+
+	Diagnose() []*Diagnosis
+	DiagnoseApp(appID string) *Diagnosis
+}
+
+// Queue is Marathon launch Queue implementation
+type Queue struct {
+	client *marathon.Client
+	//
+	queue *Queues
+
+	//
+	fail *data.FailureMessage
+}
+
+//=== Marathon Queue JSON Entities definition
+
 // Queues Array of
 type Queues struct {
 	Queue []Type `json:"queue"`
@@ -23,7 +58,7 @@ type Type struct {
 
 // AppQueue holds definitions of Apps in Queue
 type AppQueue struct {
-	ID                    string          `json:"id"`
+	ID                    string                      `json:"id"`
 	Instances             int                         `json:"instances"`
 	Cpus                  int                         `json:"cpus"`
 	Mem                   int                         `json:"mem"`
@@ -48,8 +83,19 @@ type Delay struct {
 
 // ProcessedOffersSummary is Processed Offers Summary info
 type ProcessedOffersSummary struct {
-	ProcessedOffersCount int `json:"processedOffersCount"`
-	UnusedOffersCount    int `json:"unusedOffersCount"`
+	ProcessedOffersCount       int             `json:"processedOffersCount"`
+	UnusedOffersCount          int             `json:"unusedOffersCount"`
+	LastUnusedOfferAt          *time.Time      `json:"lastUnusedOfferAt,omitempty"`
+	LastUsedOfferAt            *time.Time      `json:"lastUsedOfferAt,omitempty"`
+	RejectSummaryLastOffers    []RejectSummary `json:"rejectSummaryLastOffers,omitempty"`
+	RejectSummaryLaunchAttempt []RejectSummary `json:"rejectSummaryLaunchAttempt,omitempty"`
+}
+
+// RejectSummary holds how many offers were processed and declined by a reason
+type RejectSummary struct {
+	Reason    string `json:"reason"`
+	Declined  int    `json:"declined"`
+	Processed int    `json:"processed"`
 }
 
 // LastUnusedOffer Offers not used info
@@ -71,7 +117,7 @@ type Offer struct {
 // Resource is Offer resource representation
 type Resource struct {
 	Name   string   `json:"name"`
-	Scalar int      `json:"scalar"`
+	Scalar float64  `json:"scalar"`
 	Ranges []Range  `json:"ranges"`
 	Set    []string `json:"set"`
 	Role   string   `json:"role"`
@@ -80,7 +126,7 @@ type Resource struct {
 // Attribute of an Offer from Mesos
 type Attribute struct {
 	Name   string   `json:"name"`
-	Scalar int      `json:"scalar"`
+	Scalar float64  `json:"scalar"`
 	Ranges []Range  `json:"ranges"`
 	Set    []string `json:"set"`
 }
@@ -90,3 +136,74 @@ type Range struct {
 	Begin int `json:"begin"`
 	End   int `json:"end"`
 }
+
+// New returns a new instance of Marathon launch queue implementation
+func New(client *marathon.Client) *Queue {
+
+	if client != nil {
+		return &Queue{
+			client: client,
+			queue:  &Queues{},
+			fail:   &data.FailureMessage{},
+		}
+	}
+	return nil
+}
+
+// Get allows to establish the internal structures with the launch queue, including last unused offers
+func (mq *Queue) Get() (*Queue, error) {
+
+	return mq.GetContext(context.Background())
+}
+
+// GetContext allows to establish the internal structures with the launch queue, honoring ctx
+func (mq *Queue) GetContext(ctx context.Context) (*Queue, error) {
+
+	params := url.Values{}
+	params.Set("embed", "lastUnusedOffers")
+
+	mq.queue = &Queues{}
+	if err := mq.client.Do(ctx, http.MethodGet, marathon.APIQueue, params, nil, mq.queue, mq.fail); err != nil {
+		return mq, err
+	}
+	return mq, nil
+}
+
+// App returns the queue entry of an App, nil if the App is not waiting
+func (mq *Queue) App(appID string) *Type {
+
+	appID = "/" + utilities.DelInitialSlash(appID)
+	for index := range mq.queue.Queue {
+		if mq.queue.Queue[index].App.ID == appID {
+			return &mq.queue.Queue[index]
+		}
+	}
+	return nil
+}
+
+// AsRaw returns queue entries of internal structures
+func (mq *Queue) AsRaw() []Type {
+
+	return mq.queue.Queue
+}
+
+// ResetDelay resets the launch delay of an App, so its tasks are launched on next offers
+func (mq *Queue) ResetDelay(appID string) error {
+
+	return mq.ResetDelayContext(context.Background(), appID)
+}
+
+// ResetDelayContext resets the launch delay of an App, honoring ctx
+func (mq *Queue) ResetDelayContext(ctx context.Context, appID string) error {
+
+	if len(appID) > 0 {
+
+		path := fmt.Sprintf("%s%s/delay", marathon.APIQueue, utilities.DelInitialSlash(appID))
+
+		if err := mq.client.Do(ctx, http.MethodDelete, path, nil, nil, nil, mq.fail); err != nil {
+			return err
+		}
+		return nil
+	}
+	return errors.New("app cannot be null nor empty")
+}
@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"context"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_New(t *testing.T) {
+
+	t.Run("nil Queue if send nil client", func(t *testing.T) {
+
+		// Try to create Queue
+		_queue := New(nil)
+
+		// Queue is nil
+		assert.Nil(t, _queue)
+	})
+
+	t.Run("valid Queue if send valid client", func(t *testing.T) {
+
+		// Try to create Queue
+		_queue := New(marathon.New("http://127.0.0.1:8080"))
+
+		// Queue is not nil
+		assert.NotNil(t, _queue)
+	})
+}
+
+func TestQueue_Get(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get the launch queue with last unused offers", func(t *testing.T) {
+
+		// Try to create Queue
+		_queue := New(marathon.New(server.URL))
+
+		// try to Get the queue
+		_ref, err := _queue.Get()
+
+		// err must be nil
+		assert.Nil(t, err)
+		assert.Equal(t, _queue, _ref)
+		assert.Len(t, _queue.AsRaw(), 2)
+
+		// Apps are found by id with or without initial slash
+		assert.NotNil(t, _queue.App("infra/redis-1"))
+		assert.Len(t, _queue.App("/infra/redis-1").LastUnusedOffers, 3)
+		assert.Equal(t, 0.5, _queue.App("/infra/redis-1").LastUnusedOffers[0].Offer.Resources[0].Scalar)
+		assert.Nil(t, _queue.App("/infra/kong-v2"))
+	})
+}
+
+func TestQueue_ResetDelay(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("error if send empty app id", func(t *testing.T) {
+
+		// Try to create Queue
+		_queue := New(marathon.New(server.URL))
+
+		// err must be not nil
+		assert.NotNil(t, _queue.ResetDelay(""))
+	})
+
+	t.Run("reset delay of a valid app", func(t *testing.T) {
+
+		// Try to create Queue
+		_queue := New(marathon.New(server.URL))
+
+		// err must be nil
+		assert.Nil(t, _queue.ResetDelayContext(context.Background(), "/infra/redis-1"))
+	})
+}
+
+func TestQueue_Diagnose(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// Try to create Queue
+	_queue := New(marathon.New(server.URL))
+	_, err := _queue.Get()
+	assert.Nil(t, err)
+
+	t.Run("diagnose from last unused offers", func(t *testing.T) {
+
+		diagnosis := _queue.DiagnoseApp("/infra/redis-1")
+
+		// Reasons are aggregated and sorted by count
+		assert.NotNil(t, diagnosis)
+		assert.True(t, diagnosis.Delayed)
+		assert.Equal(t, []Reason{
+			{Reason: ReasonInsufficientCpus, Count: 2, Explanation: explanations[ReasonInsufficientCpus]},
+			{Reason: ReasonInsufficientMemory, Count: 1, Explanation: explanations[ReasonInsufficientMemory]},
+			{Reason: ReasonUnfulfilledConstraint, Count: 1, Explanation: explanations[ReasonUnfulfilledConstraint]},
+		}, diagnosis.Reasons)
+
+		text := diagnosis.String()
+		assert.True(t, strings.HasPrefix(text, "/infra/redis-1: 1 instance(s) waiting"))
+		assert.Contains(t, text, "InsufficientCpus (2 offers)")
+		assert.Contains(t, text, "launch delayed 17s")
+	})
+
+	t.Run("diagnose from reject summary", func(t *testing.T) {
+
+		diagnosis := _queue.DiagnoseApp("/infra/broker-0")
+
+		// Overdue delay is not reported as delayed
+		assert.NotNil(t, diagnosis)
+		assert.False(t, diagnosis.Delayed)
+		assert.Len(t, diagnosis.Reasons, 1)
+		assert.Equal(t, ReasonNoCorrespondingReservationFound, diagnosis.Reasons[0].Reason)
+		assert.Equal(t, 4, diagnosis.Reasons[0].Count)
+	})
+
+	t.Run("diagnose every app", func(t *testing.T) {
+
+		assert.Len(t, _queue.Diagnose(), 2)
+		assert.Nil(t, _queue.DiagnoseApp("/infra/kong-v2"))
+	})
+}
@@ -0,0 +1,160 @@
+package marathon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// modeled caches JSON field names of every model type seen by UnknownFields
+var modeled sync.Map
+
+// UnknownFields returns the fields of content (a JSON object) that model (a struct, or a
+// pointer to it) does not define, plus defined fields holding a zero value, as omitempty
+// would drop them on encode and Marathon could apply a different default. Fields named
+// in ignore (e.g. read-only status fields) are never returned
+func UnknownFields(content []byte, model interface{}, ignore ...string) (map[string]json.RawMessage, error) {
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+
+	known := fieldsOf(reflect.TypeOf(model))
+
+	var unknown map[string]json.RawMessage
+	for name, raw := range fields {
+		if contains(ignore, name) || (known[name] && !isZero(raw)) {
+			continue
+		}
+		if unknown == nil {
+			unknown = make(map[string]json.RawMessage)
+		}
+		unknown[name] = raw
+	}
+	return unknown, nil
+}
+
+// MergeFields adds fields to encoded (a JSON object) if they are not already there,
+// so values set on the model always win over values kept from the original payload
+func MergeFields(encoded []byte, fields map[string]json.RawMessage) ([]byte, error) {
+
+	if len(fields) == 0 {
+		return encoded, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		return nil, err
+	}
+	for name, raw := range fields {
+		if _, ok := object[name]; !ok {
+			object[name] = raw
+		}
+	}
+	return json.Marshal(object)
+}
+
+// LoadYAML decodes the YAML file fileName into value through JSON, so value is decoded by its
+// json tags and JSON methods: camelCase keys as healthChecks are matched as in a .json file
+func LoadYAML(fileName string, value interface{}) error {
+
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var decoded interface{}
+	if err := yaml.Unmarshal(content, &decoded); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(jsonValue(decoded))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, value)
+}
+
+// jsonValue returns value, as decoded by yaml, with its maps keyed by strings as JSON needs
+func jsonValue(value interface{}) interface{} {
+
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(typed))
+		for key, field := range typed {
+			object[fmt.Sprint(key)] = jsonValue(field)
+		}
+		return object
+	case []interface{}:
+		for index, element := range typed {
+			typed[index] = jsonValue(element)
+		}
+	}
+	return value
+}
+
+// fieldsOf returns JSON field names defined by a struct type
+func fieldsOf(model reflect.Type) map[string]bool {
+
+	for model.Kind() == reflect.Ptr {
+		model = model.Elem()
+	}
+	if fields, ok := modeled.Load(model); ok {
+		return fields.(map[string]bool)
+	}
+
+	fields := make(map[string]bool)
+	for index := 0; index < model.NumField(); index++ {
+		field := model.Field(index)
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "-":
+			continue
+		case field.Anonymous && len(name) == 0 && field.Type.Kind() == reflect.Struct:
+			for embedded := range fieldsOf(field.Type) {
+				fields[embedded] = true
+			}
+			continue
+		case len(name) == 0:
+			name = field.Name
+		}
+		fields[name] = true
+	}
+	modeled.Store(model, fields)
+	return fields
+}
+
+// isZero returns true if raw is a JSON zero value: 0, false, "", null, [] or {}
+func isZero(raw json.RawMessage) bool {
+
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, raw); err != nil {
+		return false
+	}
+
+	switch value := compacted.String(); value {
+	case "false", `""`, "null", "[]", "{}":
+		return true
+	default:
+		number, err := strconv.ParseFloat(value, 64)
+		return err == nil && number == 0
+	}
+}
+
+// contains returns true if name is in names
+func contains(names []string, name string) bool {
+
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
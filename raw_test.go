@@ -0,0 +1,106 @@
+package marathon
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+
+	type model struct {
+		ID      string `json:"id"`
+		Count   int    `json:"count,omitempty"`
+		Ignored string `json:"-"`
+	}
+
+	t.Run("keep unknown and zero valued fields", func(t *testing.T) {
+
+		fields, err := UnknownFields([]byte(`{"id":"a","count":0,"extra":{"a":1},"status":"running"}`), &model{}, "status")
+
+		// We get not error
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]json.RawMessage{
+			"count": json.RawMessage(`0`),
+			"extra": json.RawMessage(`{"a":1}`),
+		}, fields)
+	})
+
+	t.Run("nothing to keep", func(t *testing.T) {
+
+		fields, err := UnknownFields([]byte(`{"id":"a","count":3}`), model{})
+
+		assert.Nil(t, err)
+		assert.Nil(t, fields)
+	})
+
+	t.Run("error if content is not an object", func(t *testing.T) {
+
+		_, err := UnknownFields([]byte(`[1,2]`), model{})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMergeFields(t *testing.T) {
+
+	t.Run("encoded values win", func(t *testing.T) {
+
+		merged, err := MergeFields([]byte(`{"id":"a","count":3}`), map[string]json.RawMessage{
+			"count": json.RawMessage(`0`),
+			"extra": json.RawMessage(`true`),
+		})
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"id":"a","count":3,"extra":true}`, string(merged))
+	})
+
+	t.Run("nothing to merge", func(t *testing.T) {
+
+		merged, err := MergeFields([]byte(`{"id":"a"}`), nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"id":"a"}`, string(merged))
+	})
+}
+
+func Test_isZero(t *testing.T) {
+
+	for _, zero := range []string{`0`, `0.0`, `false`, `""`, `null`, `[]`, `{ }`} {
+		assert.True(t, isZero(json.RawMessage(zero)), zero)
+	}
+	for _, value := range []string{`1`, `true`, `"a"`, `[0]`, `{"a":0}`} {
+		assert.False(t, isZero(json.RawMessage(value)), value)
+	}
+}
+
+func TestLoadYAML(t *testing.T) {
+
+	type model struct {
+		ID           string            `json:"id"`
+		HealthChecks []Healthcheck     `json:"healthChecks"`
+		Labels       map[string]string `json:"labels"`
+	}
+
+	file, err := ioutil.TempFile("", "*.yaml")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString("id: /infra/web\nhealthChecks:\n  - gracePeriodSeconds: 30\nlabels:\n  HAPROXY_GROUP: external\n")
+	_ = file.Close()
+
+	t.Run("camelCase keys are decoded by json tags", func(t *testing.T) {
+
+		loaded := model{}
+		assert.Nil(t, LoadYAML(file.Name(), &loaded))
+		assert.Equal(t, "/infra/web", loaded.ID)
+		assert.Equal(t, 30, loaded.HealthChecks[0].GracePeriodSeconds)
+		assert.Equal(t, map[string]string{"HAPROXY_GROUP": "external"}, loaded.Labels)
+	})
+
+	t.Run("error if the file does not exist", func(t *testing.T) {
+
+		assert.NotNil(t, LoadYAML(file.Name()+".missing", &model{}))
+	})
+}
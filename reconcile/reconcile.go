@@ -0,0 +1,388 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/diff"
+	"github.com/dotWicho/marathon/groups"
+	"github.com/dotWicho/marathon/plan"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is the time Run waits between reconciliations
+	DefaultInterval = time.Minute
+	// DefaultHistory is the number of results a Reconciler keeps
+	DefaultHistory = 20
+)
+
+// State of a definition after a reconciliation
+type State string
+
+const (
+	// InSync definitions match the live state
+	InSync State = "in-sync"
+	// Drifted definitions differ from the live state, which was left as it is by DryRun
+	Drifted State = "drifted"
+	// Corrected definitions differed from the live state and were applied
+	Corrected State = "corrected"
+	// Failed definitions could not be loaded, compared or applied
+	Failed State = "failed"
+)
+
+// Object is the outcome of reconciling a definition file
+type Object struct {
+	// File is the path of the definition relative to the directory
+	File string `json:"file"`
+	// Kind is plan.KindApp or plan.KindGroup, empty if the file could not be loaded
+	Kind  string `json:"kind,omitempty"`
+	ID    string `json:"id,omitempty"`
+	State State  `json:"state"`
+	// Items are the changes found between the live state and the definition
+	Items []plan.Item `json:"items,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// Result of a reconciliation of the whole directory
+type Result struct {
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Objects  []Object      `json:"objects"`
+	// Error is set when the directory could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// Status of a Reconciler
+type Status struct {
+	Dir      string        `json:"dir"`
+	Interval time.Duration `json:"interval"`
+	DryRun   bool          `json:"dryRun"`
+	// Running is true while Run is reconciling periodically
+	Running bool `json:"running"`
+	// Runs is the number of reconciliations done
+	Runs int `json:"runs"`
+	// History holds the last results, the newest last
+	History []Result `json:"history"`
+}
+
+// Options of a Reconciler
+type Options struct {
+	// Interval between reconciliations of Run, DefaultInterval if not set
+	Interval time.Duration
+	// Prune deletes live apps and groups inside defined groups but missing in their definition,
+	// apps and groups out of every definition are never touched
+	Prune bool
+	// Force changes even if the apps are locked by other deployments
+	Force bool
+	// DryRun only reports drift without correcting it
+	DryRun bool
+	// History is the number of results kept, DefaultHistory if not set
+	History int
+	// Report, if set, is called with the result of every reconciliation
+	Report func(result Result)
+}
+
+// Reconciler keeps Marathon in sync with a directory of app and group definitions
+//
+// Every .json and .yaml file of the directory and its subdirectories holds an app or a group,
+// as read by Application.Load and Groups.Load; files and directories starting with a dot are
+// skipped. A file is a group if it has apps or groups inside, an app otherwise. Definitions
+// are compared as diff.Definitions does: fields Marathon fills with defaults are drift only if
+// the definition sets them, any other field differing from the definition is drift
+type Reconciler struct {
+	client  *marathon.Client
+	dir     string
+	options Options
+
+	// reconciling serializes reconciliations, mutex guards the fields below it
+	reconciling sync.Mutex
+	mutex       sync.Mutex
+	running     bool
+	runs        int
+	history     []Result
+}
+
+// New returns a new instance of Reconciler of the definitions found into dir
+func New(client *marathon.Client, dir string, options Options) *Reconciler {
+
+	if client != nil {
+		if options.Interval <= 0 {
+			options.Interval = DefaultInterval
+		}
+		if options.History <= 0 {
+			options.History = DefaultHistory
+		}
+		return &Reconciler{
+			client:  client,
+			dir:     dir,
+			options: options,
+		}
+	}
+	return nil
+}
+
+// Run reconciles right away and then every interval until ctx is done, returning its error
+func (rc *Reconciler) Run(ctx context.Context) error {
+
+	rc.mutex.Lock()
+	if rc.running {
+		rc.mutex.Unlock()
+		return errors.New("reconciler is already running")
+	}
+	rc.running = true
+	rc.mutex.Unlock()
+
+	defer func() {
+		rc.mutex.Lock()
+		rc.running = false
+		rc.mutex.Unlock()
+	}()
+
+	ticker := time.NewTicker(rc.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		rc.ReconcileContext(ctx)
+
+		// The ticker may fire while ctx is done, ctx is checked after both
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Reconcile compares every definition with the live state and corrects the drift found
+func (rc *Reconciler) Reconcile() Result {
+
+	return rc.ReconcileContext(context.Background())
+}
+
+// ReconcileContext compares every definition with the live state and corrects the drift found,
+// honoring ctx. The result is recorded into the history and sent to Report
+func (rc *Reconciler) ReconcileContext(ctx context.Context) Result {
+
+	rc.reconciling.Lock()
+	defer rc.reconciling.Unlock()
+
+	result := Result{Started: time.Now(), Objects: []Object{}}
+
+	files, err := definitions(rc.dir)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	for _, file := range files {
+		result.Objects = append(result.Objects, rc.reconcile(ctx, file))
+	}
+	result.Duration = time.Since(result.Started)
+
+	marathon.Logger.Debug("Reconciler: %s => %s", rc.dir, result)
+
+	rc.mutex.Lock()
+	rc.runs++
+	rc.history = append(rc.history, result)
+	if len(rc.history) > rc.options.History {
+		rc.history = rc.history[len(rc.history)-rc.options.History:]
+	}
+	rc.mutex.Unlock()
+
+	if rc.options.Report != nil {
+		rc.options.Report(result)
+	}
+	return result
+}
+
+// Status returns the state of the reconciler and its last results
+func (rc *Reconciler) Status() Status {
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	return Status{
+		Dir:      rc.dir,
+		Interval: rc.options.Interval,
+		DryRun:   rc.options.DryRun,
+		Running:  rc.running,
+		Runs:     rc.runs,
+		History:  append([]Result{}, rc.history...),
+	}
+}
+
+// ServeHTTP answers /healthz with 200 if the last reconciliation did not fail and 503 otherwise,
+// any other path gets the Status as JSON
+func (rc *Reconciler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	status := rc.Status()
+
+	if strings.HasSuffix(r.URL.Path, "/healthz") {
+		last := status.Last()
+		switch {
+		case last == nil:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("not reconciled yet\n"))
+		case last.Failed():
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(last.String() + "\n"))
+		default:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(last.String() + "\n"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// Last returns the newest result, nil if there is none
+func (s Status) Last() *Result {
+
+	if len(s.History) == 0 {
+		return nil
+	}
+	return &s.History[len(s.History)-1]
+}
+
+// Count returns the number of definitions in state
+func (r Result) Count(state State) int {
+
+	count := 0
+	for _, object := range r.Objects {
+		if object.State == state {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed returns true if the directory could not be read or any definition failed
+func (r Result) Failed() bool {
+
+	return len(r.Error) > 0 || r.Count(Failed) > 0
+}
+
+// String summarizes the result in a line
+func (r Result) String() string {
+
+	if len(r.Error) > 0 {
+		return fmt.Sprintf("unable to reconcile: %s", r.Error)
+	}
+	return fmt.Sprintf("%d definitions in %v: %d in sync, %d corrected, %d drifted, %d failed",
+		len(r.Objects), r.Duration.Round(time.Millisecond), r.Count(InSync), r.Count(Corrected), r.Count(Drifted), r.Count(Failed))
+}
+
+// reconcile loads the definition of file and brings the live state in line with it
+func (rc *Reconciler) reconcile(ctx context.Context, file string) Object {
+
+	object := Object{File: file}
+	if relative, err := filepath.Rel(rc.dir, file); err == nil {
+		object.File = relative
+	}
+
+	var items []plan.Item
+	var err error
+
+	if group := groups.New(rc.client).Load(file).AsRaw(); len(group.ID) > 0 && len(group.Apps)+len(group.Groups) > 0 {
+		object.Kind, object.ID = plan.KindGroup, group.ID
+		items, err = rc.group(ctx, group)
+	} else if app := application.New(rc.client).Load(file).AsRaw(); len(app.ID) > 0 {
+		object.Kind, object.ID = plan.KindApp, app.ID
+		items, err = rc.app(ctx, app)
+	} else {
+		err = errors.New("unable to load an app or group")
+	}
+
+	object.Items = items
+	switch {
+	case err != nil:
+		object.State, object.Error = Failed, err.Error()
+	case len(items) == 0:
+		object.State = InSync
+	case rc.options.DryRun:
+		object.State = Drifted
+	default:
+		object.State = Corrected
+	}
+	return object
+}
+
+// group plans the changes of the group tree and applies them unless DryRun
+func (rc *Reconciler) group(ctx context.Context, desired *groups.Group) ([]plan.Item, error) {
+
+	planner := plan.New(rc.client, plan.Options{Prune: rc.options.Prune, Force: rc.options.Force})
+	changes, err := planner.PlanContext(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Empty() || rc.options.DryRun {
+		return changes.Items, nil
+	}
+	return changes.Items, planner.ApplyContext(ctx, changes)
+}
+
+// app compares the live app with desired and applies it unless DryRun
+func (rc *Reconciler) app(ctx context.Context, desired application.AppDefinition) ([]plan.Item, error) {
+
+	if err := desired.Validate(); err != nil {
+		return nil, err
+	}
+
+	item := plan.Item{Action: plan.Create, Kind: plan.KindApp, ID: desired.ID}
+
+	live := application.New(rc.client).GetContext(ctx, desired.ID)
+	if err := live.LastError(); err != nil && !marathon.IsNotFound(err) {
+		return nil, err
+	}
+	if live.LastError() == nil {
+		changes, err := diff.Definitions(live.AsRaw(), desired)
+		if err != nil {
+			return nil, err
+		}
+		item.Action, item.Changes = plan.Update, changes
+		if item.Changes.Empty() {
+			return nil, nil
+		}
+	}
+
+	items := []plan.Item{item}
+	if rc.options.DryRun {
+		return items, nil
+	}
+	return items, application.New(rc.client).Set(desired).ApplyContext(ctx, rc.options.Force)
+}
+
+// definitions returns the .json and .yaml files inside dir and its subdirectories, sorted by
+// path; files and directories starting with a dot are skipped
+func definitions(dir string) ([]string, error) {
+
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if extension := filepath.Ext(path); !info.IsDir() && (extension == ".json" || extension == ".yaml") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,280 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/application"
+	"github.com/dotWicho/marathon/diff"
+	"github.com/dotWicho/marathon/groups"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/dotWicho/marathon/plan"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// definitionsDir returns a directory holding files with their contents, it must be removed
+func definitionsDir(t *testing.T, files map[string]string) string {
+
+	dir, err := ioutil.TempDir("", "reconcile")
+	assert.Nil(t, err)
+
+	for name, content := range files {
+		fileName := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(fileName), 0755))
+		assert.Nil(t, ioutil.WriteFile(fileName, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestNew(t *testing.T) {
+
+	assert.Nil(t, New(nil, ".", Options{}))
+
+	reconciler := New(marathon.New("http://127.0.0.1:8080"), ".", Options{})
+	assert.NotNil(t, reconciler)
+	assert.Equal(t, DefaultInterval, reconciler.options.Interval)
+	assert.Equal(t, DefaultHistory, reconciler.options.History)
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	client := marathon.New(server.URL)
+	dir := definitionsDir(t, map[string]string{
+		"web.json":          `{"id": "/stored/reconcile/web", "cmd": "sleep 100", "cpus": 0.1, "mem": 32, "instances": 2}`,
+		"groups/infra.json": mockserver.GroupsArray,
+		"broken.json":       `{}`,
+		".hidden.json":      `{}`,
+		"README.txt":        "not a definition",
+	})
+	defer os.RemoveAll(dir)
+
+	t.Run("create missing apps and keep groups in sync", func(t *testing.T) {
+
+		result := New(client, dir, Options{}).Reconcile()
+
+		assert.Empty(t, result.Error)
+		assert.Equal(t, []Object{
+			{File: "broken.json", State: Failed, Error: "unable to load an app or group"},
+			{File: filepath.Join("groups", "infra.json"), Kind: plan.KindGroup, ID: "/infra", State: InSync},
+			{File: "web.json", Kind: plan.KindApp, ID: "/stored/reconcile/web", State: Corrected,
+				Items: []plan.Item{{Action: plan.Create, Kind: plan.KindApp, ID: "/stored/reconcile/web"}}},
+		}, result.Objects)
+		assert.True(t, result.Failed())
+		assert.NotEmpty(t, mockserver.StoredApp("/stored/reconcile/web"))
+	})
+
+	t.Run("apps applied are in sync", func(t *testing.T) {
+
+		result := New(client, dir, Options{}).Reconcile()
+
+		assert.Equal(t, InSync, result.Objects[2].State)
+		assert.Equal(t, 1, result.Count(Failed))
+		assert.Equal(t, 2, result.Count(InSync))
+	})
+
+	t.Run("report drift without correcting it", func(t *testing.T) {
+
+		// Somebody scales the app by hand
+		mockserver.StoreApp(`{"id": "/stored/reconcile/web", "cmd": "sleep 100", "cpus": 0.1, "mem": 32, "instances": 5}`)
+
+		result := New(client, dir, Options{DryRun: true}).Reconcile()
+
+		assert.Equal(t, Drifted, result.Objects[2].State)
+		assert.Equal(t, diff.Changes{{Path: "instances", Kind: diff.Changed, From: 5.0, To: 2.0}}, result.Objects[2].Items[0].Changes)
+		assert.Contains(t, mockserver.StoredApp("/stored/reconcile/web"), `"instances":5`)
+	})
+
+	t.Run("correct drift", func(t *testing.T) {
+
+		result := New(client, dir, Options{}).Reconcile()
+
+		assert.Equal(t, Corrected, result.Objects[2].State)
+		assert.Equal(t, plan.Update, result.Objects[2].Items[0].Action)
+		assert.Contains(t, mockserver.StoredApp("/stored/reconcile/web"), `"instances":2`)
+	})
+
+	t.Run("fields left to their defaults are not drift", func(t *testing.T) {
+
+		// Marathon fills the fields missing in the definition
+		mockserver.StoreApp(`{"id": "/stored/reconcile/web", "cmd": "sleep 100", "cpus": 0.1, "mem": 32, "instances": 2, "backoffFactor": 1.15}`)
+
+		result := New(client, dir, Options{DryRun: true}).Reconcile()
+
+		assert.Equal(t, InSync, result.Objects[2].State)
+	})
+
+	t.Run("correct drift of fields the definition leaves out", func(t *testing.T) {
+
+		// Somebody adds env and constraints by hand
+		mockserver.StoreApp(`{"id": "/stored/reconcile/web", "cmd": "sleep 100", "cpus": 0.1, "mem": 32, "instances": 2,
+			"env": {"DEBUG": "true"}, "constraints": [["hostname", "UNIQUE"]]}`)
+
+		result := New(client, dir, Options{}).Reconcile()
+
+		assert.Equal(t, Corrected, result.Objects[2].State)
+		assert.Equal(t, []string{"constraints", "env"}, result.Objects[2].Items[0].Changes.Paths())
+		assert.NotContains(t, mockserver.StoredApp("/stored/reconcile/web"), "DEBUG")
+	})
+
+	t.Run("groups left to their defaults are in sync", func(t *testing.T) {
+
+		// The definition leaves out what Marathon fills in
+		group := &groups.Group{}
+		assert.Nil(t, json.Unmarshal([]byte(mockserver.GroupsArray), group))
+		for index := range group.Apps {
+			app := &group.Apps[index]
+			app.BackoffFactor, app.BackoffSeconds, app.MaxLaunchDelaySeconds, app.KillSelection = 0, 0, 0, ""
+			app.UpgradeStrategy = application.UpgradeStrategy{}
+			app.Extra = nil
+		}
+		content, _ := json.Marshal(group)
+
+		groupDir := definitionsDir(t, map[string]string{"infra.json": string(content)})
+		defer os.RemoveAll(groupDir)
+
+		before := mockserver.LastBody()
+		result := New(client, groupDir, Options{}).Reconcile()
+
+		// Nothing is sent to Marathon
+		assert.Equal(t, InSync, result.Objects[0].State)
+		assert.Empty(t, result.Objects[0].Items)
+		assert.Equal(t, before, mockserver.LastBody())
+	})
+
+	t.Run("yaml definitions with camelCase fields", func(t *testing.T) {
+
+		yamlDir := definitionsDir(t, map[string]string{"api.yaml": `
+id: /stored/reconcile/api
+cmd: sleep 100
+cpus: 0.1
+mem: 32
+instances: 1
+healthChecks:
+  - protocol: COMMAND
+    command:
+      value: "true"
+    gracePeriodSeconds: 30
+upgradeStrategy:
+  minimumHealthCapacity: 0.5
+  maximumOverCapacity: 0.5
+`})
+		defer os.RemoveAll(yamlDir)
+
+		result := New(client, yamlDir, Options{}).Reconcile()
+
+		assert.Equal(t, Corrected, result.Objects[0].State)
+		stored := mockserver.StoredApp("/stored/reconcile/api")
+		assert.Contains(t, stored, `"gracePeriodSeconds":30`)
+		assert.Contains(t, stored, `"minimumHealthCapacity":0.5`)
+
+		// Loaded again it matches what was applied
+		result = New(client, yamlDir, Options{}).Reconcile()
+		assert.Equal(t, InSync, result.Objects[0].State)
+	})
+
+	t.Run("error if the directory does not exist", func(t *testing.T) {
+
+		result := New(client, filepath.Join(dir, "missing"), Options{}).Reconcile()
+
+		assert.NotEmpty(t, result.Error)
+		assert.Empty(t, result.Objects)
+		assert.True(t, result.Failed())
+	})
+}
+
+func TestReconciler_Run(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	dir := definitionsDir(t, map[string]string{"infra.json": mockserver.GroupsArray})
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := 0
+	var reconciler *Reconciler
+	reconciler = New(marathon.New(server.URL), dir, Options{Interval: 10 * time.Millisecond, History: 2, Report: func(result Result) {
+		// Report runs inside Run, so a second Run is rejected
+		assert.Equal(t, "reconciler is already running", reconciler.Run(ctx).Error())
+		if reports++; reports == 3 {
+			cancel()
+		}
+	}})
+
+	assert.Equal(t, context.Canceled, reconciler.Run(ctx))
+
+	status := reconciler.Status()
+	assert.False(t, status.Running)
+	assert.Equal(t, 3, status.Runs)
+	assert.Len(t, status.History, 2)
+	assert.Equal(t, InSync, status.Last().Objects[0].State)
+}
+
+func TestReconciler_ServeHTTP(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	// We define some vars
+	dir := definitionsDir(t, map[string]string{"infra.json": mockserver.GroupsArray})
+	defer os.RemoveAll(dir)
+
+	reconciler := New(marathon.New(server.URL), dir, Options{})
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		reconciler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	t.Run("not healthy before the first reconciliation", func(t *testing.T) {
+
+		assert.Equal(t, http.StatusServiceUnavailable, get("/healthz").Code)
+	})
+
+	t.Run("healthy after a reconciliation without failures", func(t *testing.T) {
+
+		reconciler.Reconcile()
+
+		recorder := get("/healthz")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "1 definitions in")
+	})
+
+	t.Run("status as JSON", func(t *testing.T) {
+
+		recorder := get("/status")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		status := Status{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		assert.Equal(t, dir, status.Dir)
+		assert.Equal(t, 1, status.Runs)
+		assert.Equal(t, "/infra", status.Last().Objects[0].ID)
+	})
+
+	t.Run("not healthy after a failed reconciliation", func(t *testing.T) {
+
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{}`), 0644))
+		reconciler.Reconcile()
+
+		assert.Equal(t, http.StatusServiceUnavailable, get("/healthz").Code)
+	})
+}
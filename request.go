@@ -0,0 +1,199 @@
+package marathon
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/dotWicho/marathon/data"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Do sends a request to the Marathon server honoring ctx cancellation and deadlines,
+// params are added as query string, body (if not nil) is sent as JSON and the
+// response is decoded into success on 2xx status codes or into failure otherwise.
+// On 503 responses the request is retried against other endpoints, as it is on connection
+// errors if it is idempotent or never reached the server: a POST, PUT or PATCH dropped after it
+// was sent is not retried, as it could have been applied. Any other failure status code is
+// returned as an *Error
+func (mc *Client) Do(ctx context.Context, method, path string, params url.Values, body, success, failure interface{}) error {
+
+	var payload []byte
+	if body != nil {
+		buffer := new(bytes.Buffer)
+		if err := json.NewEncoder(buffer).Encode(body); err != nil {
+			return err
+		}
+		payload = buffer.Bytes()
+	}
+
+	attempts := len(mc.Endpoints())
+	for attempt := 1; ; attempt++ {
+
+		endpoint := mc.Endpoint()
+		response, err := mc.send(ctx, endpoint, method, path, params, payload)
+		if err != nil {
+			// Prefer the context error, it tells the caller why the request was aborted
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if attempt < attempts && retryable(method, err) && mc.failover(ctx, endpoint) {
+				continue
+			}
+			return err
+		}
+		if response.StatusCode == http.StatusServiceUnavailable && attempt < attempts && mc.failover(ctx, endpoint) {
+			response.Body.Close()
+			continue
+		}
+
+		mc.setStatusCode(response.StatusCode)
+		Logger.Debug("Client: %s %s%s StatusCode %d", method, endpoint, path, response.StatusCode)
+
+		defer response.Body.Close()
+		if 200 <= response.StatusCode && response.StatusCode <= 299 {
+			return decodeBody(response.Body, success)
+		}
+		return failed(response, failure)
+	}
+}
+
+// retryable returns true if a request with method failing with err can be sent again to other
+// endpoint: idempotent methods always are, other ones only if the connection was never made
+func retryable(method string, err error) bool {
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	}
+	var dial *net.OpError
+	return errors.As(err, &dial) && dial.Op == "dial"
+}
+
+// failed builds an Error from a failure response, decoding it into failure too (if not nil)
+func failed(response *http.Response, failure interface{}) error {
+
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	message := &data.FailureMessage{}
+	if err := decodeBody(bytes.NewReader(content), message); err != nil {
+		// Marathon (or a proxy in front of it) could answer with a non JSON body
+		message.Message = strings.TrimSpace(string(content))
+	}
+	if failure != nil {
+		_ = decodeBody(bytes.NewReader(content), failure)
+	}
+	return NewError(response.StatusCode, message)
+}
+
+// send fires up a single request against endpoint
+func (mc *Client) send(ctx context.Context, endpoint, method, path string, params url.Values, payload []byte) (*http.Response, error) {
+
+	requestURL, err := buildURL(endpoint, path, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header = mc.headers()
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	Logger.Debug("Client: %s %s", method, requestURL)
+	return mc.httpClient().Do(request)
+}
+
+// buildURL builds the full URL of path (plus query params) against endpoint
+func buildURL(endpoint, path string, params url.Values) (string, error) {
+
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	reference, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+
+	requestURL := base.ResolveReference(reference)
+	requestURL.User = nil
+	if len(params) > 0 {
+		query := requestURL.Query()
+		for key, values := range params {
+			for _, value := range values {
+				query.Add(key, value)
+			}
+		}
+		requestURL.RawQuery = query.Encode()
+	}
+	return requestURL.String(), nil
+}
+
+// headers returns a fresh copy of headers sent on every request
+func (mc *Client) headers() http.Header {
+
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Accept-Encoding", "identity")
+	if len(mc.auth) > 0 {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(mc.auth)))
+	}
+	return header
+}
+
+// httpClient returns the http.Client used to reach Marathon, creating it if needed
+func (mc *Client) httpClient() *http.Client {
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if mc.http == nil {
+		mc.http = &http.Client{Timeout: mc.timeout}
+	}
+	return mc.http
+}
+
+// setStatusCode saves the status code of last response
+func (mc *Client) setStatusCode(code int) {
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	mc.statusCode = code
+}
+
+// decodeBody decodes a JSON response body into target, empty bodies are ignored
+func decodeBody(body io.Reader, target interface{}) error {
+
+	if target == nil {
+		return nil
+	}
+
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return nil
+	}
+	return json.Unmarshal(content, target)
+}
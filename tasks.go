@@ -37,3 +37,23 @@ type IPAddress struct {
 	IPAddress string `json:"ipAddress"`
 	Protocol  string `json:"protocol"`
 }
+
+// Running returns true if the Task is in TASK_RUNNING state
+func (tm *TaskMarathon) Running() bool {
+
+	return tm.State == "TASK_RUNNING"
+}
+
+// Healthy returns true if the Task is running and all its health checks are alive
+func (tm *TaskMarathon) Healthy() bool {
+
+	if !tm.Running() {
+		return false
+	}
+	for _, result := range tm.HealthCheckResults {
+		if !result.Alive {
+			return false
+		}
+	}
+	return true
+}
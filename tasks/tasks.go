@@ -0,0 +1,235 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/data"
+	"github.com/dotWicho/utilities"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+//===
+
+// Task states accepted by Marathon as status filter
+const (
+	StatusRunning string = "running"
+	StatusStaging string = "staging"
+)
+
+// Marathon Tasks interface
+type tasks interface {
+	Get(appID string) (*Tasks, error)
+	GetContext(ctx context.Context, appID string) (*Tasks, error)
+	All(status ...string) (*Tasks, error)
+	AllContext(ctx context.Context, status ...string) (*Tasks, error)
+	Task(id string) *marathon.TaskMarathon
+
+	Kill(appID, taskID string, scale, wipe bool) error
+	KillContext(ctx context.Context, appID, taskID string, scale, wipe bool) error
+	KillAll(ids []string, scale, wipe bool) error
+	KillAllContext(ctx context.Context, ids []string, scale, wipe bool) error
+
+	AsRaw() []marathon.TaskMarathon
+	Running() []marathon.TaskMarathon
+	Healthy() []marathon.TaskMarathon
+	Unhealthy() []marathon.TaskMarathon
+	DeploymentID() string
+}
+
+// Tasks is Marathon Tasks implementation
+type Tasks struct {
+	client *marathon.Client
+	//
+	tasks *marathon.Tasks
+
+	//
+	deploy *data.Response
+	fail   *data.FailureMessage
+}
+
+// killed holds Marathon response of a Task kill, a killed Task or a deployment if scale was requested
+type killed struct {
+	marathon.Task
+	data.Response
+}
+
+// bulk is the payload of POST /v2/tasks/delete
+type bulk struct {
+	IDs []string `json:"ids"`
+}
+
+// New returns a new instance of Marathon tasks implementation
+func New(client *marathon.Client) *Tasks {
+
+	if client != nil {
+		return &Tasks{
+			client: client,
+			tasks:  &marathon.Tasks{},
+			deploy: &data.Response{},
+			fail:   &data.FailureMessage{},
+		}
+	}
+	return nil
+}
+
+// Get allows to establish the internal structures with Tasks of an App
+func (mt *Tasks) Get(appID string) (*Tasks, error) {
+
+	return mt.GetContext(context.Background(), appID)
+}
+
+// GetContext allows to establish the internal structures with Tasks of an App, honoring ctx
+func (mt *Tasks) GetContext(ctx context.Context, appID string) (*Tasks, error) {
+
+	if len(appID) > 0 {
+
+		path := fmt.Sprintf("%s%s/tasks", marathon.APIApps, utilities.DelInitialSlash(appID))
+
+		mt.tasks = &marathon.Tasks{}
+		if err := mt.client.Do(ctx, http.MethodGet, path, nil, nil, mt.tasks, mt.fail); err != nil {
+			return mt, err
+		}
+		return mt, nil
+	}
+	return mt, errors.New("app cannot be null nor empty")
+}
+
+// All allows to establish the internal structures with Tasks of all Apps, optionally
+// filtered by status (StatusRunning, StatusStaging)
+func (mt *Tasks) All(status ...string) (*Tasks, error) {
+
+	return mt.AllContext(context.Background(), status...)
+}
+
+// AllContext allows to establish the internal structures with Tasks of all Apps, honoring ctx
+func (mt *Tasks) AllContext(ctx context.Context, status ...string) (*Tasks, error) {
+
+	params := url.Values{}
+	switch len(status) {
+	case 0:
+	case 1:
+		params.Set("status", status[0])
+	default:
+		params["status[]"] = status
+	}
+
+	mt.tasks = &marathon.Tasks{}
+	if err := mt.client.Do(ctx, http.MethodGet, marathon.APITasks, params, nil, mt.tasks, mt.fail); err != nil {
+		return mt, err
+	}
+	return mt, nil
+}
+
+// Task returns a Task by its id from internal structures, nil if not found
+func (mt *Tasks) Task(id string) *marathon.TaskMarathon {
+
+	for index := range mt.tasks.Tasks {
+		if mt.tasks.Tasks[index].ID == id {
+			return &mt.tasks.Tasks[index]
+		}
+	}
+	return nil
+}
+
+// Kill kills a Task of an App, if scale is true the App instances are decreased (starting
+// a deployment), if wipe is true reserved resources of the Task are destroyed too
+func (mt *Tasks) Kill(appID, taskID string, scale, wipe bool) error {
+
+	return mt.KillContext(context.Background(), appID, taskID, scale, wipe)
+}
+
+// KillContext kills a Task of an App, honoring ctx
+func (mt *Tasks) KillContext(ctx context.Context, appID, taskID string, scale, wipe bool) error {
+
+	if len(appID) > 0 && len(taskID) > 0 {
+
+		path := fmt.Sprintf("%s%s/tasks/%s", marathon.APIApps, utilities.DelInitialSlash(appID), taskID)
+
+		response := &killed{}
+		if err := mt.client.Do(ctx, http.MethodDelete, path, killParams(scale, wipe), nil, response, mt.fail); err != nil {
+			return err
+		}
+		mt.deploy = &response.Response
+		return nil
+	}
+	return errors.New("app and task cannot be null nor empty")
+}
+
+// KillAll kills a set of Tasks of any App, scale and wipe works as in Kill
+func (mt *Tasks) KillAll(ids []string, scale, wipe bool) error {
+
+	return mt.KillAllContext(context.Background(), ids, scale, wipe)
+}
+
+// KillAllContext kills a set of Tasks of any App, honoring ctx
+func (mt *Tasks) KillAllContext(ctx context.Context, ids []string, scale, wipe bool) error {
+
+	if len(ids) > 0 {
+
+		mt.deploy = &data.Response{}
+		if err := mt.client.Do(ctx, http.MethodPost, marathon.APITasksDelete, killParams(scale, wipe), &bulk{IDs: ids}, mt.deploy, mt.fail); err != nil {
+			return err
+		}
+		return nil
+	}
+	return errors.New("tasks cannot be null nor empty")
+}
+
+// AsRaw returns Tasks of internal structures
+func (mt *Tasks) AsRaw() []marathon.TaskMarathon {
+
+	return mt.tasks.Tasks
+}
+
+// Running returns Tasks in TASK_RUNNING state
+func (mt *Tasks) Running() []marathon.TaskMarathon {
+
+	return mt.filter(func(task *marathon.TaskMarathon) bool { return task.Running() })
+}
+
+// Healthy returns running Tasks with all their health checks alive
+func (mt *Tasks) Healthy() []marathon.TaskMarathon {
+
+	return mt.filter(func(task *marathon.TaskMarathon) bool { return task.Healthy() })
+}
+
+// Unhealthy returns running Tasks with any health check failing
+func (mt *Tasks) Unhealthy() []marathon.TaskMarathon {
+
+	return mt.filter(func(task *marathon.TaskMarathon) bool { return task.Running() && !task.Healthy() })
+}
+
+// DeploymentID returns the deployment started by last Kill or KillAll with scale
+func (mt *Tasks) DeploymentID() string {
+
+	return mt.deploy.ID
+}
+
+// filter returns Tasks matching match
+func (mt *Tasks) filter(match func(task *marathon.TaskMarathon) bool) []marathon.TaskMarathon {
+
+	var selected []marathon.TaskMarathon
+	for index := range mt.tasks.Tasks {
+		if match(&mt.tasks.Tasks[index]) {
+			selected = append(selected, mt.tasks.Tasks[index])
+		}
+	}
+	return selected
+}
+
+// killParams returns query params of kill requests
+func killParams(scale, wipe bool) url.Values {
+
+	params := url.Values{}
+	if scale {
+		params.Set("scale", strconv.FormatBool(scale))
+	}
+	if wipe {
+		params.Set("wipe", strconv.FormatBool(wipe))
+	}
+	return params
+}
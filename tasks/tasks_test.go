@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"context"
+	"github.com/dotWicho/marathon"
+	"github.com/dotWicho/marathon/mockserver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_New(t *testing.T) {
+
+	t.Run("nil Tasks if send nil client", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(nil)
+
+		// Tasks is nil
+		assert.Nil(t, _tasks)
+	})
+
+	t.Run("valid Tasks if send valid client", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New("http://127.0.0.1:8080"))
+
+		// Tasks is not nil
+		assert.NotNil(t, _tasks)
+	})
+}
+
+func TestTasks_Get(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("error if send empty app id", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// try to Get with empty app id
+		_, err := _tasks.Get("")
+
+		// err must be not nil
+		assert.NotNil(t, err)
+	})
+
+	t.Run("get Tasks of a valid app", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// try to Get tasks of redis-1
+		_ref, err := _tasks.Get("/infra/redis-1")
+
+		// err must be nil
+		assert.Nil(t, err)
+		assert.Equal(t, _tasks, _ref)
+
+		// Tasks were loaded with their health check results
+		assert.Len(t, _tasks.AsRaw(), 2)
+		assert.Len(t, _tasks.Running(), 2)
+		assert.Len(t, _tasks.Healthy(), 1)
+		assert.Len(t, _tasks.Unhealthy(), 1)
+		assert.Equal(t, "connection refused", _tasks.Unhealthy()[0].HealthCheckResults[0].LastFailureCause)
+
+		// Lookup by id
+		assert.NotNil(t, _tasks.Task("infra_redis-1.c9de6033"))
+		assert.Nil(t, _tasks.Task("infra_redis-1.unknown"))
+	})
+}
+
+func TestTasks_All(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("get Tasks of all apps filtered by status", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// try to get cluster-wide running tasks
+		_, err := _tasks.AllContext(context.Background(), StatusRunning, StatusStaging)
+
+		// err must be nil
+		assert.Nil(t, err)
+		assert.Len(t, _tasks.AsRaw(), 2)
+	})
+}
+
+func TestTasks_Kill(t *testing.T) {
+
+	// We create a Mock Server
+	server := mockserver.MockServer()
+	defer server.Close()
+
+	t.Run("error if send empty ids", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// err must be not nil
+		assert.NotNil(t, _tasks.Kill("/infra/redis-1", "", false, false))
+		assert.NotNil(t, _tasks.KillAll(nil, false, false))
+	})
+
+	t.Run("kill a Task without scale", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// err must be nil and no deployment is started
+		assert.Nil(t, _tasks.Kill("/infra/redis-1", "infra_redis-1.c9de6033", false, false))
+		assert.Empty(t, _tasks.DeploymentID())
+	})
+
+	t.Run("kill a Task with scale", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// err must be nil and a deployment is started
+		assert.Nil(t, _tasks.Kill("/infra/redis-1", "infra_redis-1.c9de6033", true, false))
+		assert.Equal(t, "d4b75430-8ee6-47e9-95f2-6cf297aaac00", _tasks.DeploymentID())
+	})
+
+	t.Run("kill an unknown Task", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// err must be a not found error
+		err := _tasks.Kill("/infra/redis-1", "infra_redis-1.unknown", false, false)
+		assert.True(t, marathon.IsNotFound(err))
+	})
+
+	t.Run("kill a set of Tasks with scale", func(t *testing.T) {
+
+		// Try to create Tasks
+		_tasks := New(marathon.New(server.URL))
+
+		// err must be nil and a deployment is started
+		assert.Nil(t, _tasks.KillAll([]string{"infra_redis-1.c9de6033", "infra_redis-1.d1ab7744"}, true, true))
+		assert.Equal(t, "d4b75430-8ee6-47e9-95f2-6cf297aaac00", _tasks.DeploymentID())
+	})
+}
@@ -0,0 +1,57 @@
+package marathon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is a field of a definition Marathon would reject, Path uses the syntax of
+// Marathon failure details, e.g. /container/portMappings(0)/hostPort
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// ValidationErrors holds every ValidationError found on a definition
+type ValidationErrors []ValidationError
+
+// Add appends a ValidationError of path
+func (ve *ValidationErrors) Add(path, format string, args ...interface{}) {
+
+	*ve = append(*ve, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Merge appends every ValidationError of other
+func (ve *ValidationErrors) Merge(other ValidationErrors) {
+
+	*ve = append(*ve, other...)
+}
+
+// Paths returns the path of every ValidationError
+func (ve ValidationErrors) Paths() []string {
+
+	paths := make([]string, 0, len(ve))
+	for _, err := range ve {
+		paths = append(paths, err.Path)
+	}
+	return paths
+}
+
+// Err returns ve as an error, nil if it is empty
+func (ve ValidationErrors) Err() error {
+
+	if len(ve) == 0 {
+		return nil
+	}
+	return ve
+}
+
+// Error returns a readable representation of every ValidationError
+func (ve ValidationErrors) Error() string {
+
+	messages := make([]string, 0, len(ve))
+	for _, err := range ve {
+		messages = append(messages, fmt.Sprintf("%s: %s", err.Path, err.Message))
+	}
+	return fmt.Sprintf("invalid definition (%s)", strings.Join(messages, "; "))
+}